@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	seedCount    int
+	seedExporter string
+	seedSpread   time.Duration
+	seedDev      bool
+
+	checkQuarantine bool
+)
+
+// bufferCmd represents the buffer command
+var bufferCmd = &cobra.Command{
+	Use:   "buffer",
+	Short: "Inspect the buffer of scrapes waiting to be sent",
+}
+
+var bufferCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check buffered scrapes for corruption",
+	Long: `Parses every buffered .prom file with its exporter's parser and reports each file
+as OK or CORRUPT (empty, truncated, partially written, or unparseable).
+
+Corrupt files are otherwise skipped or sent with missing metrics by the agent.
+With --quarantine they are moved to quarantine/ in the buffer directory, which the
+agent never sends and removes after the retention period.
+
+Exits non-zero if corrupt files remain in the buffer.`,
+	SilenceUsage: true,
+	RunE:         runBufferCheck,
+}
+
+var bufferSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Write synthetic scrapes into the buffer to simulate a backlog",
+	Long: `Writes N synthetic .prom files into the buffer with timestamps spread back from now,
+for exercising the drain loop and tuning batch/backoff settings.
+
+This writes to the configured buffer path, so it requires --dev to run.`,
+	RunE: runBufferSeed,
+}
+
+func init() {
+	rootCmd.AddCommand(bufferCmd)
+	bufferCmd.AddCommand(bufferSeedCmd)
+	bufferCmd.AddCommand(bufferCheckCmd)
+
+	bufferSeedCmd.Flags().IntVar(&seedCount, "count", 100, "Number of buffer files to write")
+	bufferSeedCmd.Flags().StringVar(&seedExporter, "exporter", "node_exporter", "Exporter to simulate: node_exporter, process_exporter")
+	bufferSeedCmd.Flags().DurationVar(&seedSpread, "spread", 15*time.Second, "Time between synthetic scrapes")
+	bufferSeedCmd.Flags().BoolVar(&seedDev, "dev", false, "Confirm running a development-only command")
+
+	bufferCheckCmd.Flags().BoolVar(&checkQuarantine, "quarantine", false, "Move corrupt files to quarantine/ in the buffer directory")
+}
+
+func runBufferCheck(cmd *cobra.Command, args []string) error {
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	buffer, err := report.NewBuffer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open buffer: %w", err)
+	}
+
+	return checkBuffer(os.Stdout, buffer, checkQuarantine)
+}
+
+// checkBuffer prints the integrity of each buffered file and optionally quarantines corrupt ones
+// Returns an error if corrupt files remain in the buffer
+func checkBuffer(w io.Writer, buffer *report.Buffer, quarantine bool) error {
+	checks, err := buffer.CheckFiles()
+	if err != nil {
+		return fmt.Errorf("failed to list buffer files: %w", err)
+	}
+
+	ok, corrupt, quarantined := 0, 0, 0
+	for _, check := range checks {
+		if check.Err == nil {
+			ok++
+			fmt.Fprintf(w, "OK       %s\n", check.Path)
+			continue
+		}
+
+		corrupt++
+		fmt.Fprintf(w, "CORRUPT  %s: %v\n", check.Path, check.Err)
+		if quarantine {
+			dest, err := buffer.QuarantineFile(check.Path)
+			if err != nil {
+				fmt.Fprintf(w, "         %v\n", err)
+				continue
+			}
+			quarantined++
+			fmt.Fprintf(w, "         moved to %s\n", dest)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d file(s) checked: %d OK, %d corrupt", len(checks), ok, corrupt)
+	if quarantine {
+		fmt.Fprintf(w, ", %d quarantined", quarantined)
+	}
+	fmt.Fprintln(w)
+
+	if remaining := corrupt - quarantined; remaining > 0 {
+		if !quarantine {
+			return fmt.Errorf("%d corrupt buffer file(s) found (use --quarantine to move them aside)", remaining)
+		}
+		return fmt.Errorf("%d corrupt buffer file(s) could not be quarantined", remaining)
+	}
+	return nil
+}
+
+func runBufferSeed(cmd *cobra.Command, args []string) error {
+	if !seedDev {
+		return fmt.Errorf("buffer seed is a development tool and writes to the live buffer; pass --dev to run it")
+	}
+
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	buffer, err := report.NewBuffer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open buffer: %w", err)
+	}
+
+	if err := seedBuffer(buffer, cfg.Agent.ServerID, seedExporter, seedCount, seedSpread, time.Now()); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d synthetic %s scrapes to %s\n", seedCount, seedExporter, cfg.Buffer.Path)
+	return nil
+}
+
+// seedBuffer writes count synthetic scrapes, the newest at now and each older one spread earlier
+func seedBuffer(buffer *report.Buffer, serverID, exporterName string, count int, spread time.Duration, now time.Time) error {
+	if count <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+	// Filenames have second resolution, so closer scrapes would overwrite each other
+	if spread < time.Second {
+		return fmt.Errorf("--spread must be at least 1s")
+	}
+
+	rng := rand.New(rand.NewSource(now.UnixNano()))
+	for i := 0; i < count; i++ {
+		data, err := syntheticScrape(exporterName, rng)
+		if err != nil {
+			return err
+		}
+
+		scrapedAt := now.Add(-time.Duration(count-1-i) * spread)
+		if err := buffer.SavePrometheusAt(data, serverID, exporterName, scrapedAt); err != nil {
+			return fmt.Errorf("failed to write synthetic scrape %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// syntheticScrape generates Prometheus text format data resembling a real exporter scrape
+func syntheticScrape(exporterName string, rng *rand.Rand) ([]byte, error) {
+	var sb strings.Builder
+
+	switch exporterName {
+	case "node_exporter":
+		fmt.Fprintf(&sb, "node_cpu_seconds_total{cpu=\"0\",mode=\"idle\"} %.2f\n", 10000+rng.Float64()*1000)
+		fmt.Fprintf(&sb, "node_cpu_seconds_total{cpu=\"0\",mode=\"user\"} %.2f\n", 500+rng.Float64()*100)
+		fmt.Fprintf(&sb, "node_cpu_seconds_total{cpu=\"0\",mode=\"system\"} %.2f\n", 200+rng.Float64()*50)
+		fmt.Fprintf(&sb, "node_memory_MemTotal_bytes %d\n", 8<<30)
+		fmt.Fprintf(&sb, "node_memory_MemAvailable_bytes %d\n", 2<<30+rng.Int63n(4<<30))
+		fmt.Fprintf(&sb, "node_load1 %.2f\n", rng.Float64()*4)
+		fmt.Fprintf(&sb, "node_load5 %.2f\n", rng.Float64()*4)
+		fmt.Fprintf(&sb, "node_load15 %.2f\n", rng.Float64()*4)
+		fmt.Fprintf(&sb, "node_network_receive_bytes_total{device=\"eth0\"} %d\n", rng.Int63n(1<<40))
+		fmt.Fprintf(&sb, "node_network_transmit_bytes_total{device=\"eth0\"} %d\n", rng.Int63n(1<<40))
+	case "process_exporter":
+		for _, name := range []string{"nginx", "postgres", "sshd"} {
+			fmt.Fprintf(&sb, "namedprocess_namegroup_num_procs{groupname=%q} %d\n", name, 1+rng.Intn(8))
+			fmt.Fprintf(&sb, "namedprocess_namegroup_cpu_seconds_total{groupname=%q,mode=\"user\"} %.2f\n", name, rng.Float64()*1000)
+			fmt.Fprintf(&sb, "namedprocess_namegroup_memory_bytes{groupname=%q,memtype=\"resident\"} %d\n", name, rng.Int63n(1<<30))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported exporter for seeding: %s (use node_exporter or process_exporter)", exporterName)
+	}
+
+	return []byte(sb.String()), nil
+}
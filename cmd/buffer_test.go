@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/prometheus"
+	"github.com/node-pulse/agent/internal/report"
+)
+
+func TestSeedBuffer(t *testing.T) {
+	tests := []struct {
+		exporter string
+		parse    func([]byte) error
+	}{
+		{
+			exporter: "node_exporter",
+			parse: func(data []byte) error {
+				_, err := prometheus.ParseNodeExporterMetrics(data)
+				return err
+			},
+		},
+		{
+			exporter: "process_exporter",
+			parse: func(data []byte) error {
+				_, err := prometheus.ParseProcessExporterMetrics(data)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			cfg := &config.Config{
+				Buffer: config.BufferConfig{Path: t.TempDir(), RetentionHours: 48},
+			}
+			buffer, err := report.NewBuffer(cfg)
+			if err != nil {
+				t.Fatalf("NewBuffer failed: %v", err)
+			}
+
+			now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.Local)
+			if err := seedBuffer(buffer, "test-server", tt.exporter, 25, 15*time.Second, now); err != nil {
+				t.Fatalf("seedBuffer failed: %v", err)
+			}
+
+			files, err := buffer.GetBufferFiles()
+			if err != nil {
+				t.Fatalf("GetBufferFiles failed: %v", err)
+			}
+			if len(files) != 25 {
+				t.Fatalf("Expected 25 buffer files, got %d", len(files))
+			}
+
+			if got := filepath.Base(files[0]); got != "20250115-115400-test-server.prom" {
+				t.Errorf("Expected oldest file 20250115-115400-test-server.prom, got %s", got)
+			}
+			if got := filepath.Base(files[len(files)-1]); got != "20250115-120000-test-server.prom" {
+				t.Errorf("Expected newest file 20250115-120000-test-server.prom, got %s", got)
+			}
+
+			for _, file := range files {
+				entry, err := buffer.LoadPrometheusFile(file)
+				if err != nil {
+					t.Fatalf("LoadPrometheusFile(%s) failed: %v", file, err)
+				}
+				if entry.ExporterName != tt.exporter || entry.ServerID != "test-server" {
+					t.Errorf("Unexpected entry metadata: %s/%s", entry.ExporterName, entry.ServerID)
+				}
+				if err := tt.parse(entry.Data); err != nil {
+					t.Errorf("Synthetic scrape in %s does not parse: %v", file, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSeedBuffer_InvalidArgs(t *testing.T) {
+	cfg := &config.Config{Buffer: config.BufferConfig{Path: t.TempDir()}}
+	buffer, err := report.NewBuffer(cfg)
+	if err != nil {
+		t.Fatalf("NewBuffer failed: %v", err)
+	}
+
+	if err := seedBuffer(buffer, "s", "node_exporter", 0, time.Second, time.Now()); err == nil {
+		t.Error("Expected error for zero count")
+	}
+	if err := seedBuffer(buffer, "s", "node_exporter", 5, time.Millisecond, time.Now()); err == nil {
+		t.Error("Expected error for sub-second spread")
+	}
+	if err := seedBuffer(buffer, "s", "mysql_exporter", 5, time.Second, time.Now()); err == nil {
+		t.Error("Expected error for unsupported exporter")
+	}
+}
+
+func TestCheckBuffer(t *testing.T) {
+	cfg := &config.Config{
+		Buffer: config.BufferConfig{Path: t.TempDir(), RetentionHours: 48},
+	}
+	buffer, err := report.NewBuffer(cfg)
+	if err != nil {
+		t.Fatalf("NewBuffer failed: %v", err)
+	}
+	if err := seedBuffer(buffer, "srv", "node_exporter", 2, time.Second, time.Now()); err != nil {
+		t.Fatalf("seedBuffer failed: %v", err)
+	}
+	truncated := filepath.Join(cfg.Buffer.Path, "node_exporter", "20200101-000000-srv.prom")
+	if err := os.WriteFile(truncated, []byte("node_load1 0."), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := checkBuffer(&out, buffer, false); err == nil {
+		t.Error("Expected an error while a corrupt file remains")
+	}
+	if !strings.Contains(out.String(), "3 file(s) checked: 2 OK, 1 corrupt") {
+		t.Errorf("Unexpected summary:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := checkBuffer(&out, buffer, true); err != nil {
+		t.Errorf("Expected no error after quarantining, got %v\n%s", err, out.String())
+	}
+	if _, err := os.Stat(truncated); !os.IsNotExist(err) {
+		t.Errorf("Expected the corrupt file to be moved out of the buffer")
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/installer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	flagAsFlags        bool
+	flagIncludeSecrets bool
+)
+
+// secretAnnotation marks setup flags whose values are redacted on export
+const secretAnnotation = "secret"
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the agent configuration",
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the current configuration",
+	Long: `Export the current configuration so it can be reproduced on another host.
+
+With --as-flags, prints a 'nodepulse setup --yes ...' command line that recreates
+the settings managed by setup. Secrets are redacted unless --include-secrets is given.`,
+	RunE: runConfigExport,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration file",
+	Long: `Loads and validates the configuration, reporting every problem at once.
+If the configuration is valid, prints a summary of the settings that matter most.`,
+	SilenceUsage: true,
+	RunE:         runConfigValidate,
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the resolved configuration",
+	Long: `Prints the configuration the agent will actually use as YAML: the config file
+merged with defaults, with the server ID resolved. Secrets are redacted unless
+--include-secrets is given.`,
+	SilenceUsage: true,
+	RunE:         runConfigPrint,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPrintCmd)
+
+	configExportCmd.Flags().BoolVar(&flagAsFlags, "as-flags", false, "Print as a 'nodepulse setup' command line")
+	configExportCmd.Flags().BoolVar(&flagIncludeSecrets, "include-secrets", false, "Include secret values instead of redacting them")
+	configPrintCmd.Flags().BoolVar(&flagIncludeSecrets, "include-secrets", false, "Include secret values instead of redacting them")
+}
+
+func runConfigExport(cmd *cobra.Command, args []string) error {
+	if !flagAsFlags {
+		return fmt.Errorf("no export format specified (use --as-flags)")
+	}
+
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	opts := installer.ConfigOptionsFromConfig(cfg)
+	fmt.Println("nodepulse setup --yes " + strings.Join(setupFlagArgs(opts, flagIncludeSecrets), " "))
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		problems := flattenErrors(err)
+		fmt.Printf("Found %d problem(s):\n", len(problems))
+		for _, problem := range problems {
+			fmt.Printf("  - %v\n", problem)
+		}
+		return fmt.Errorf("invalid config")
+	}
+
+	fmt.Printf("Config OK: %s\n", cfg.ConfigFile)
+	printConfigSummary(os.Stdout, cfg)
+	return nil
+}
+
+// printConfigSummary prints the settings that matter most of a valid config
+func printConfigSummary(w io.Writer, cfg *config.Config) {
+	fmt.Fprintf(w, "  Endpoint:   %s (%s, %s)\n", cfg.Server.Endpoint, cfg.Server.Encoding, cfg.Server.Compression)
+	fmt.Fprintf(w, "  Server ID:  %s\n", cfg.Agent.ServerID)
+
+	var exporters []string
+	for _, e := range cfg.Exporters {
+		switch {
+		case !e.Enabled:
+			exporters = append(exporters, e.Name+" (disabled)")
+		case e.Type == config.ExporterTypeGeneric:
+			exporters = append(exporters, fmt.Sprintf("%s (generic, every %s)", e.Name, e.ParsedInterval))
+		default:
+			exporters = append(exporters, fmt.Sprintf("%s (every %s)", e.Name, e.ParsedInterval))
+		}
+	}
+	fmt.Fprintf(w, "  Exporters:  %s\n", strings.Join(exporters, ", "))
+	fmt.Fprintf(w, "  Buffer:     %s (%dh retention)\n", cfg.Buffer.Path, cfg.Buffer.RetentionHours)
+	fmt.Fprintf(w, "  Logging:    %s to %s\n", cfg.Logging.Level, cfg.Logging.Output)
+}
+
+func runConfigPrint(cmd *cobra.Command, args []string) error {
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fmt.Printf("# Resolved configuration (%s merged with defaults)\n", cfg.ConfigFile)
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2) // Like nodepulse.yml
+	if err := enc.Encode(config.Settings(cfg, flagIncludeSecrets)); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return enc.Close()
+}
+
+// flattenErrors expands errors joined with errors.Join (including nested joins) into a flat list
+func flattenErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []error{err}
+	}
+
+	var flat []error
+	for _, e := range joined.Unwrap() {
+		flat = append(flat, flattenErrors(e)...)
+	}
+	return flat
+}
+
+// setupFlagArgs builds the setup flags that reproduce opts
+// Flags equal to their setup default are omitted, except endpoint-url and server-id
+// which identify the host and are always emitted
+func setupFlagArgs(opts installer.ConfigOptions, includeSecrets bool) []string {
+	// The registered flags provide the defaults and secret annotations, the values come from opts
+	fs := pflag.NewFlagSet("setup", pflag.ContinueOnError)
+	registerSetupFlags(fs, &installer.ConfigOptions{})
+
+	var args []string
+	for _, flag := range setupFlagValues(opts) {
+		f := fs.Lookup(flag.name)
+		value := flag.value
+		if value == f.DefValue && f.Name != "endpoint-url" && f.Name != "server-id" {
+			continue
+		}
+		if _, secret := f.Annotations[secretAnnotation]; secret && !includeSecrets {
+			value = "REDACTED"
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, shellQuote(value)))
+	}
+	return args
+}
+
+// setupFlagValues returns the setup flag values for opts, in registerSetupFlags order
+func setupFlagValues(opts installer.ConfigOptions) []struct{ name, value string } {
+	return []struct{ name, value string }{
+		{"endpoint-url", opts.Endpoint},
+		{"timeout", opts.Timeout},
+		{"auth-type", opts.AuthType},
+		{"auth-token", opts.AuthToken},
+		{"auth-token-env", opts.AuthTokenEnv},
+		{"auth-token-file", opts.AuthTokenFile},
+		{"auth-header-name", opts.AuthHeaderName},
+		{"server-id", opts.ServerID},
+		{"interval", opts.Interval},
+		{"buffer-path", opts.BufferPath},
+		{"buffer-retention-hours", strconv.Itoa(opts.BufferRetentionHours)},
+		{"buffer-batch-size", strconv.Itoa(opts.BufferBatchSize)},
+		{"log-level", opts.LogLevel},
+		{"log-output", opts.LogOutput},
+		{"log-file", opts.LogFilePath},
+		{"log-max-size-mb", strconv.Itoa(opts.LogMaxSizeMB)},
+		{"log-max-backups", strconv.Itoa(opts.LogMaxBackups)},
+		{"log-max-age-days", strconv.Itoa(opts.LogMaxAgeDays)},
+		{"log-compress", strconv.FormatBool(opts.LogCompress)},
+	}
+}
+
+// shellQuote quotes a value for safe use in a POSIX shell command line
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@=,+", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/installer"
+	"github.com/spf13/pflag"
+)
+
+func TestSetupFlagArgs_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts installer.ConfigOptions
+	}{
+		{
+			name: "setup defaults",
+			opts: installer.ConfigOptions{
+				Endpoint:             "https://dashboard.example.com/metrics/prometheus",
+				Timeout:              "5s",
+				AuthType:             "none",
+				ServerID:             "web-01",
+				Interval:             "15s",
+				BufferPath:           installer.DefaultBufferPath,
+				BufferRetentionHours: 48,
+				BufferBatchSize:      5,
+				LogLevel:             "info",
+				LogOutput:            "stdout",
+				LogFilePath:          "/var/log/nodepulse/agent.log",
+				LogMaxSizeMB:         10,
+				LogMaxBackups:        3,
+				LogMaxAgeDays:        7,
+				LogCompress:          true,
+			},
+		},
+		{
+			name: "customized",
+			opts: installer.ConfigOptions{
+				Endpoint:             "http://10.0.0.5:8080/ingest",
+				Timeout:              "10s",
+				AuthType:             "header",
+				AuthToken:            "abc123",
+				AuthHeaderName:       "X-API-Key",
+				ServerID:             "db-primary-2",
+				Interval:             "1m0s",
+				BufferPath:           "/data/nodepulse/buffer",
+				BufferRetentionHours: 24,
+				BufferBatchSize:      20,
+				LogLevel:             "debug",
+				LogOutput:            "both",
+				LogFilePath:          "/data/log/agent.log",
+				LogMaxSizeMB:         50,
+				LogMaxBackups:        0,
+				LogMaxAgeDays:        30,
+				LogCompress:          false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := setupFlagArgs(tt.opts, true)
+
+			var parsed installer.ConfigOptions
+			fs := pflag.NewFlagSet("setup", pflag.ContinueOnError)
+			registerSetupFlags(fs, &parsed)
+			if err := fs.Parse(args); err != nil {
+				t.Fatalf("Failed to parse exported flags %v: %v", args, err)
+			}
+
+			if parsed != tt.opts {
+				t.Errorf("Round trip mismatch:\nargs: %v\ngot:  %+v\nwant: %+v", args, parsed, tt.opts)
+			}
+		})
+	}
+}
+
+func TestSetupFlagArgs_OmitsDefaults(t *testing.T) {
+	var opts installer.ConfigOptions
+	fs := pflag.NewFlagSet("setup", pflag.ContinueOnError)
+	registerSetupFlags(fs, &opts)
+	opts.Endpoint = "https://dashboard.example.com"
+	opts.ServerID = "web-01"
+	opts.LogLevel = "debug"
+
+	got := strings.Join(setupFlagArgs(opts, false), " ")
+	want := "--endpoint-url=https://dashboard.example.com --server-id=web-01 --log-level=debug"
+	if got != want {
+		t.Errorf("setupFlagArgs() = %s, want %s", got, want)
+	}
+}
+
+func TestSetupFlagValues_CoversAllFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("setup", pflag.ContinueOnError)
+	registerSetupFlags(fs, &installer.ConfigOptions{})
+
+	covered := make(map[string]bool)
+	for _, flag := range setupFlagValues(installer.ConfigOptions{}) {
+		if fs.Lookup(flag.name) == nil {
+			t.Errorf("setupFlagValues has %s, which is not a setup flag", flag.name)
+		}
+		covered[flag.name] = true
+	}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !covered[f.Name] {
+			t.Errorf("Setup flag %s is missing from setupFlagValues", f.Name)
+		}
+	})
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://example.com/metrics", "https://example.com/metrics"},
+		{"/var/log/agent.log", "/var/log/agent.log"},
+		{"", "''"},
+		{"has space", "'has space'"},
+		{"it's", `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.input); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSetupFlagArgs_RedactsSecrets(t *testing.T) {
+	var opts installer.ConfigOptions
+	fs := pflag.NewFlagSet("setup", pflag.ContinueOnError)
+	registerSetupFlags(fs, &opts)
+
+	opts.AuthType = "bearer"
+	opts.AuthToken = "s3cr3t"
+
+	args := strings.Join(setupFlagArgs(opts, false), " ")
+	if strings.Contains(args, "s3cr3t") {
+		t.Errorf("Auth token was not redacted: %s", args)
+	}
+	if !strings.Contains(args, "--auth-token=REDACTED") {
+		t.Errorf("Expected redacted auth token placeholder, got: %s", args)
+	}
+
+	args = strings.Join(setupFlagArgs(opts, true), " ")
+	if !strings.Contains(args, "--auth-token=s3cr3t") {
+		t.Errorf("Expected auth token with --include-secrets, got: %s", args)
+	}
+}
+
+func TestFlattenErrors(t *testing.T) {
+	a, b, c := errors.New("a"), errors.New("b"), errors.New("c")
+	err := fmt.Errorf("invalid config: %w", errors.Join(a, errors.Join(b, c)))
+
+	got := flattenErrors(err)
+	if len(got) != 3 || got[0] != a || got[1] != b || got[2] != c {
+		t.Errorf("flattenErrors() = %v, want [a b c]", got)
+	}
+
+	single := errors.New("failed to read config")
+	if got := flattenErrors(single); len(got) != 1 || got[0] != single {
+		t.Errorf("flattenErrors(single) = %v, want [single]", got)
+	}
+}
+
+func TestPrintConfigSummary(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{Endpoint: "https://ingest.example.com/metrics", Encoding: "json", Compression: "gzip"},
+		Agent:  config.AgentConfig{ServerID: "web-01"},
+		Exporters: []config.ExporterConfig{
+			{Name: "node_exporter", Enabled: true, ParsedInterval: 15 * time.Second},
+			{Name: "process_exporter", Enabled: false},
+			{Name: "custom_app", Type: config.ExporterTypeGeneric, Enabled: true, ParsedInterval: time.Minute},
+		},
+		Buffer: config.BufferConfig{Path: "/var/lib/nodepulse/buffer", RetentionHours: 48},
+	}
+
+	var out bytes.Buffer
+	printConfigSummary(&out, cfg)
+
+	for _, want := range []string{
+		"Endpoint:   https://ingest.example.com/metrics (json, gzip)",
+		"Server ID:  web-01",
+		"Exporters:  node_exporter (every 15s), process_exporter (disabled), custom_app (generic, every 1m0s)",
+		"Buffer:     /var/lib/nodepulse/buffer (48h retention)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
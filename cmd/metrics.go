@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/prometheus"
+	"github.com/node-pulse/agent/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsFormat   string
+	metricsExporter string
+)
+
+// metricsCmd represents the metrics command
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Print the metrics the agent currently sees",
+	Long: `Scrapes each enabled exporter once, parses the metrics as the agent would before
+sending them, and prints the result. Nothing is buffered or sent.
+
+Use --exporter to print a single exporter, e.g. to compare what the agent parses
+with the exporter's raw output.`,
+	SilenceUsage: true,
+	RunE:         runMetrics,
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+
+	metricsCmd.Flags().StringVar(&metricsFormat, "format", "json", "Output format: json, table")
+	metricsCmd.Flags().StringVar(&metricsExporter, "exporter", "", "Only scrape this exporter (e.g. node_exporter)")
+}
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	if metricsFormat != "json" && metricsFormat != "table" {
+		return fmt.Errorf("--format must be 'json' or 'table', got: %s", metricsFormat)
+	}
+
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	payload, err := collectMetrics(cmd.Context(), cfg, metricsExporter)
+	if err != nil {
+		return err
+	}
+
+	if metricsFormat == "table" {
+		return printMetricsTable(os.Stdout, payload)
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// collectMetrics scrapes and parses enabled exporters (or only the named one) into a payload
+func collectMetrics(ctx context.Context, cfg *config.Config, only string) (report.Payload, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	payload := report.Payload{DeployID: cfg.Agent.DeployID}
+	found := false
+
+	for _, exporterCfg := range cfg.Exporters {
+		if !exporterCfg.Enabled || (only != "" && exporterCfg.Name != only) {
+			continue
+		}
+		found = true
+
+		exp, err := newExporter(exporterCfg)
+		if err != nil {
+			return payload, err
+		}
+
+		scrapeCtx, cancel := context.WithTimeout(ctx, exporterCfg.Timeout)
+		data, err := exp.Scrape(scrapeCtx)
+		cancel()
+		if err != nil {
+			return payload, fmt.Errorf("failed to scrape %s: %w", exporterCfg.Name, classifiedError(err))
+		}
+
+		if _, err := parseTestScrape(exporterCfg, report.NodeParseOptions(cfg), data, &payload); err != nil {
+			return payload, fmt.Errorf("failed to parse %s metrics: %w", exporterCfg.Name, err)
+		}
+	}
+
+	if !found {
+		if only != "" {
+			return payload, fmt.Errorf("exporter %s is not configured or not enabled", only)
+		}
+		return payload, fmt.Errorf("no enabled exporters configured")
+	}
+
+	// Same process group limit as the drain goroutine applies before sending
+	payload.ProcessExporter = prometheus.LimitProcessSnapshots(payload.ProcessExporter, cfg.ProcessExporter.MaxGroups)
+
+	return payload, nil
+}
+
+// printMetricsTable prints node_exporter snapshots as name/value rows, process groups as a table,
+// and the size of generic exporters' raw scrapes
+// Nested values (per-core, per-interface, ...) are summarized by count; use --format json for them
+func printMetricsTable(w io.Writer, payload report.Payload) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, snapshot := range payload.NodeExporter {
+		fields, err := snapshotFields(snapshot)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintln(tw, "NODE_EXPORTER\tVALUE")
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%s\t%s\n", key, formatFieldValue(fields[key]))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if len(payload.ProcessExporter) > 0 {
+		fmt.Fprintln(tw, "PROCESS_EXPORTER\tPROCS\tCPU SECONDS\tMEMORY BYTES")
+		for _, snapshot := range payload.ProcessExporter {
+			fmt.Fprintf(tw, "%s\t%d\t%.2f\t%d\n", snapshot.Name, snapshot.NumProcs, snapshot.CPUSecondsTotal, snapshot.MemoryBytes)
+		}
+	}
+
+	if len(payload.Generic) > 0 {
+		names := make([]string, 0, len(payload.Generic))
+		for name := range payload.Generic {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "GENERIC\tBYTES (UNPARSED)")
+		for _, name := range names {
+			for _, snapshot := range payload.Generic[name] {
+				fmt.Fprintf(tw, "%s\t%d\n", name, len(snapshot.Metrics))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+// snapshotFields returns a snapshot's fields keyed by their JSON names
+func snapshotFields(snapshot prometheus.NodeExporterMetricSnapshot) (map[string]interface{}, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return fields, nil
+}
+
+// formatFieldValue formats a decoded JSON value for the table
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		// Counters and byte sizes are integral; avoid exponent notation for them
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%g", v)
+	case []interface{}:
+		return fmt.Sprintf("[%d entries]", len(v))
+	case map[string]interface{}:
+		return fmt.Sprintf("{%d entries}", len(v))
+	case nil:
+		return "-"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+func TestCollectMetrics(t *testing.T) {
+	nodeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "node_load1 0.5")
+		fmt.Fprintln(w, "node_memory_MemTotal_bytes 8589934592")
+	}))
+	defer nodeServer.Close()
+
+	processServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `namedprocess_namegroup_num_procs{groupname="nginx"} 4`)
+		fmt.Fprintln(w, `namedprocess_namegroup_memory_bytes{groupname="nginx",memtype="resident"} 1048576`)
+	}))
+	defer processServer.Close()
+
+	cfg := &config.Config{
+		Agent: config.AgentConfig{DeployID: "release-7"},
+		Exporters: []config.ExporterConfig{
+			{Name: "node_exporter", Enabled: true, Endpoint: nodeServer.URL, Timeout: time.Second},
+			{Name: "process_exporter", Enabled: true, Endpoint: processServer.URL, Timeout: time.Second},
+		},
+	}
+
+	t.Run("all exporters", func(t *testing.T) {
+		payload, err := collectMetrics(context.Background(), cfg, "")
+		if err != nil {
+			t.Fatalf("collectMetrics failed: %v", err)
+		}
+		if len(payload.NodeExporter) != 1 || payload.NodeExporter[0].Load1Min != 0.5 {
+			t.Errorf("Expected one node_exporter snapshot with load1 0.5, got %+v", payload.NodeExporter)
+		}
+		if len(payload.ProcessExporter) != 1 || payload.ProcessExporter[0].Name != "nginx" {
+			t.Errorf("Expected the nginx process group, got %+v", payload.ProcessExporter)
+		}
+		if payload.DeployID != "release-7" {
+			t.Errorf("Expected deploy ID release-7, got %q", payload.DeployID)
+		}
+	})
+
+	t.Run("single exporter", func(t *testing.T) {
+		payload, err := collectMetrics(context.Background(), cfg, "node_exporter")
+		if err != nil {
+			t.Fatalf("collectMetrics failed: %v", err)
+		}
+		if len(payload.NodeExporter) != 1 || len(payload.ProcessExporter) != 0 {
+			t.Errorf("Expected only node_exporter metrics, got %+v", payload)
+		}
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		if _, err := collectMetrics(context.Background(), cfg, "redis_exporter"); err == nil {
+			t.Error("Expected an error for an exporter that isn't configured")
+		}
+	})
+
+	t.Run("table output", func(t *testing.T) {
+		payload, err := collectMetrics(context.Background(), cfg, "")
+		if err != nil {
+			t.Fatalf("collectMetrics failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := printMetricsTable(&buf, payload); err != nil {
+			t.Fatalf("printMetricsTable failed: %v", err)
+		}
+		output := buf.String()
+		for _, want := range []string{"load_1min", "0.5", "memory_total_bytes", "8589934592", "nginx"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %q in table output:\n%s", want, output)
+			}
+		}
+	})
+}
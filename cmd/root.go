@@ -3,23 +3,31 @@ package cmd
 import (
 	"os"
 
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/report"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile string
-	// Version is set at build time via -ldflags
-	Version = "dev"
+	cfgFile      string
+	pidFileFlag  string
+	instanceFlag string
+	// Version, Commit, and BuildDate are set at build time via -ldflags (see currentBuildInfo)
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
 )
 
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
-	Use:     "nodepulse",
-	Short:   "NodePulse Agent - Prometheus forwarder for server metrics",
-	Version: Version,
+	Use:   "nodepulse",
+	Short: "NodePulse Agent - Prometheus forwarder for server metrics",
 	Long: `NodePulse Agent scrapes Prometheus metrics from node_exporter and forwards them to a central dashboard.
 
 When called without a subcommand, it runs in foreground mode (equivalent to 'nodepulse start').`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return config.SetInstance(instanceFlag)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If no subcommand provided, default to 'start' command
 		// This allows systemd to call: /opt/nodepulse/nodepulse --config /path
@@ -38,4 +46,12 @@ func Execute() {
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: /etc/nodepulse/nodepulse.yml)")
+	rootCmd.PersistentFlags().StringVar(&instanceFlag, "instance", "", "run as a named instance, with its own config, buffer, server_id, and PID file under <dir>/<instance>/ (default: none)")
+	rootCmd.PersistentFlags().StringVar(&pidFileFlag, "pid-file", "", "PID file path, overrides agent.pid_file (default: /var/run/nodepulse.pid as root, ~/.nodepulse/nodepulse.pid otherwise)")
+
+	// --version prints the same summary as 'nodepulse version'
+	rootCmd.Version = currentBuildInfo().String()
+
+	// Reported in heartbeats and the User-Agent
+	report.SetAgentVersion(currentBuildInfo().Version)
 }
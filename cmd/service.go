@@ -3,43 +3,32 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/node-pulse/agent/internal/config"
 	"github.com/node-pulse/agent/internal/pidfile"
+	"github.com/node-pulse/agent/internal/service"
 	"github.com/spf13/cobra"
 )
 
-const (
-	serviceName     = "nodepulse"
-	serviceFile     = "/etc/systemd/system/nodepulse.service"
-	binaryPath      = "/opt/nodepulse/nodepulse"
-	serviceTemplate = `[Unit]
-Description=NodePulse Server Monitor Agent
-After=network.target
-
-[Service]
-Type=simple
-ExecStart=%s start
-Restart=always
-RestartSec=10s
-
-[Install]
-WantedBy=multi-user.target
-`
-)
+// binaryPath is where the service binary is installed
+const binaryPath = "/opt/nodepulse/nodepulse"
+
+var serviceWatchdog time.Duration
 
 // serviceCmd represents the service command
 var serviceCmd = &cobra.Command{
 	Use:   "service",
-	Short: "Manage the NodePulse systemd service",
-	Long:  `Install, start, stop, restart, status, or uninstall the NodePulse systemd service.`,
+	Short: "Manage the NodePulse system service",
+	Long: `Install, start, stop, restart, status, or uninstall the NodePulse system service.
+
+The init system is detected at runtime: systemd, OpenRC (e.g. Alpine), or launchd (macOS).`,
 }
 
 var serviceInstallCmd = &cobra.Command{
 	Use:   "install",
-	Short: "Install the systemd service",
+	Short: "Install the system service",
 	RunE:  installService,
 }
 
@@ -69,7 +58,7 @@ var serviceStatusCmd = &cobra.Command{
 
 var serviceUninstallCmd = &cobra.Command{
 	Use:   "uninstall",
-	Short: "Uninstall the systemd service",
+	Short: "Uninstall the system service",
 	RunE:  uninstallService,
 }
 
@@ -81,6 +70,8 @@ func init() {
 	serviceCmd.AddCommand(serviceRestartCmd)
 	serviceCmd.AddCommand(serviceStatusCmd)
 	serviceCmd.AddCommand(serviceUninstallCmd)
+
+	serviceInstallCmd.Flags().DurationVar(&serviceWatchdog, "watchdog", 0, "Enable the systemd watchdog with this timeout (e.g. 60s); 0 disables it (systemd only)")
 }
 
 func installService(cmd *cobra.Command, args []string) error {
@@ -94,6 +85,15 @@ func installService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("this command must be run as root (use sudo)")
 	}
 
+	if serviceWatchdog != 0 && serviceWatchdog < time.Second {
+		return fmt.Errorf("--watchdog must be at least 1s")
+	}
+
+	manager, err := service.Detect(config.Instance())
+	if err != nil {
+		return err
+	}
+
 	// Get current executable path
 	exePath, err := os.Executable()
 	if err != nil {
@@ -111,26 +111,15 @@ func installService(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Installed binary to %s\n", binaryPath)
 	}
 
-	// Create service file
-	serviceContent := fmt.Sprintf(serviceTemplate, binaryPath)
-	if err := os.WriteFile(serviceFile, []byte(serviceContent), 0644); err != nil {
-		return fmt.Errorf("failed to write service file: %w", err)
-	}
-	fmt.Printf("Created service file: %s\n", serviceFile)
-
-	// Reload systemd
-	if err := runSystemctl("daemon-reload"); err != nil {
-		return fmt.Errorf("failed to reload systemd: %w", err)
-	}
-
-	// Enable service
-	if err := runSystemctl("enable", serviceName); err != nil {
-		return fmt.Errorf("failed to enable service: %w", err)
+	// Create service file and enable it at boot
+	if err := manager.Install(binaryPath, service.InstallOptions{Watchdog: serviceWatchdog}); err != nil {
+		return err
 	}
+	fmt.Printf("Created service file: %s\n", manager.ServiceFile())
 
 	fmt.Println("Service installed and enabled successfully!")
 	fmt.Println("\nTo start the service, run:")
-	fmt.Printf("  sudo nodepulse service start\n")
+	fmt.Printf("  sudo nodepulse%s service start\n", instanceArg())
 	return nil
 }
 
@@ -144,7 +133,9 @@ func startService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("this command must be run as root (use sudo)")
 	}
 
-	// Check if daemon is already running
+	// Check if daemon is already running (at agent.pid_file, if the config sets one)
+	cfg, _ := config.Load(cfgFile)
+	configurePidFile(cfg)
 	isRunning, pid, err := pidfile.CheckRunning()
 	if err != nil {
 		fmt.Printf("Warning: failed to check daemon status: %v\n", err)
@@ -152,7 +143,12 @@ func startService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("agent is already running as daemon (PID %d)\nUse 'pulse stop' first", pid)
 	}
 
-	if err := runSystemctl("start", serviceName); err != nil {
+	manager, err := service.Detect(config.Instance())
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Start(); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
@@ -165,7 +161,12 @@ func stopService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("this command must be run as root (use sudo)")
 	}
 
-	if err := runSystemctl("stop", serviceName); err != nil {
+	manager, err := service.Detect(config.Instance())
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Stop(); err != nil {
 		return fmt.Errorf("failed to stop service: %w", err)
 	}
 
@@ -183,7 +184,12 @@ func restartService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("this command must be run as root (use sudo)")
 	}
 
-	if err := runSystemctl("restart", serviceName); err != nil {
+	manager, err := service.Detect(config.Instance())
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Restart(); err != nil {
 		return fmt.Errorf("failed to restart service: %w", err)
 	}
 
@@ -193,8 +199,13 @@ func restartService(cmd *cobra.Command, args []string) error {
 
 func statusService(cmd *cobra.Command, args []string) error {
 	// Status doesn't require root
-	output, err := exec.Command("systemctl", "status", serviceName).CombinedOutput()
-	fmt.Print(string(output))
+	manager, err := service.Detect(config.Instance())
+	if err != nil {
+		return err
+	}
+
+	output, err := manager.Status()
+	fmt.Print(output)
 	return err
 }
 
@@ -203,35 +214,25 @@ func uninstallService(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("this command must be run as root (use sudo)")
 	}
 
-	// Stop service if running
-	runSystemctl("stop", serviceName)
-
-	// Disable service
-	if err := runSystemctl("disable", serviceName); err != nil {
-		fmt.Printf("Warning: failed to disable service: %v\n", err)
-	}
-
-	// Remove service file
-	if err := os.Remove(serviceFile); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove service file: %w", err)
+	manager, err := service.Detect(config.Instance())
+	if err != nil {
+		return err
 	}
 
-	// Reload systemd
-	if err := runSystemctl("daemon-reload"); err != nil {
-		return fmt.Errorf("failed to reload systemd: %w", err)
+	if err := manager.Uninstall(); err != nil {
+		return err
 	}
 
 	fmt.Println("Service uninstalled successfully!")
 	return nil
 }
 
-func runSystemctl(args ...string) error {
-	cmd := exec.Command("systemctl", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%w: %s", err, string(output))
+// instanceArg returns the --instance flag to repeat in suggested commands ("" for the default instance)
+func instanceArg() string {
+	if instanceFlag == "" {
+		return ""
 	}
-	return nil
+	return " --instance " + instanceFlag
 }
 
 func copyFile(src, dst string) error {
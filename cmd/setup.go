@@ -2,17 +2,26 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"net/url"
+	"os"
 	"strings"
 
+	"github.com/node-pulse/agent/internal/config"
 	"github.com/node-pulse/agent/internal/installer"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	// Config flags
-	flagEndpointURL string
-	flagServerID    string
+	// Config flags (bound by registerSetupFlags)
+	setupOpts installer.ConfigOptions
+
+	// Accepted for compatibility - interactive mode was removed, setup is always non-interactive
+	flagYes bool
+
+	// Skip the exporter reachability probe (e.g. provisioning an image offline)
+	flagSkipChecks bool
 )
 
 // setupCmd represents the setup command
@@ -29,19 +38,54 @@ For development/testing only. Production deployments use Ansible.`,
 func init() {
 	rootCmd.AddCommand(setupCmd)
 
-	// Only two flags needed - everything else uses hardcoded defaults
-	setupCmd.Flags().StringVar(&flagEndpointURL, "endpoint-url", "", "Dashboard endpoint URL (required)")
-	setupCmd.Flags().StringVar(&flagServerID, "server-id", "", "Server ID (auto-generated UUID if not provided)")
+	// Only --endpoint-url is required - everything else has sensible defaults
+	registerSetupFlags(setupCmd.Flags(), &setupOpts)
+	setupCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Non-interactive mode (always on, accepted for compatibility)")
+	setupCmd.Flags().BoolVar(&flagSkipChecks, "skip-checks", false, "Don't check that the configured exporters respond")
+}
+
+// registerSetupFlags binds a flag for every installer.ConfigOptions field
+// Shared with 'config export --as-flags' so exported command lines always parse back
+func registerSetupFlags(fs *pflag.FlagSet, opts *installer.ConfigOptions) {
+	// Server options
+	fs.StringVar(&opts.Endpoint, "endpoint-url", "", "Dashboard endpoint URL (required)")
+	fs.StringVar(&opts.Timeout, "timeout", "5s", "HTTP request timeout")
+
+	// Auth options
+	fs.StringVar(&opts.AuthType, "auth-type", "none", "Ingest endpoint auth (none, bearer, header)")
+	fs.StringVar(&opts.AuthToken, "auth-token", "", "Auth token (prefer --auth-token-env or --auth-token-file)")
+	fs.SetAnnotation("auth-token", secretAnnotation, []string{"true"})
+	fs.StringVar(&opts.AuthTokenEnv, "auth-token-env", "", "Environment variable holding the auth token")
+	fs.StringVar(&opts.AuthTokenFile, "auth-token-file", "", "File holding the auth token")
+	fs.StringVar(&opts.AuthHeaderName, "auth-header-name", "", "Header name for --auth-type=header (e.g., X-API-Key)")
+
+	// Agent options
+	fs.StringVar(&opts.ServerID, "server-id", "", "Server ID (auto-generated UUID if not provided)")
+	fs.StringVar(&opts.Interval, "interval", "15s", "Default scrape interval (1s to 1h)")
+
+	// Buffer options
+	fs.StringVar(&opts.BufferPath, "buffer-path", installer.DefaultBufferPath, "Buffer directory")
+	fs.IntVar(&opts.BufferRetentionHours, "buffer-retention-hours", 48, "Hours to keep buffered reports")
+	fs.IntVar(&opts.BufferBatchSize, "buffer-batch-size", 5, "Reports to send per batch request")
+
+	// Logging options
+	fs.StringVar(&opts.LogLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	fs.StringVar(&opts.LogOutput, "log-output", "stdout", "Log output (stdout, file, both)")
+	fs.StringVar(&opts.LogFilePath, "log-file", installer.DefaultLogFilePath, "Log file path")
+	fs.IntVar(&opts.LogMaxSizeMB, "log-max-size-mb", 10, "Maximum log file size before rotation")
+	fs.IntVar(&opts.LogMaxBackups, "log-max-backups", 3, "Number of rotated log files to keep")
+	fs.IntVar(&opts.LogMaxAgeDays, "log-max-age-days", 7, "Days to keep rotated log files")
+	fs.BoolVar(&opts.LogCompress, "log-compress", true, "Compress rotated log files")
 }
 
 func runSetup(cmd *cobra.Command, args []string) error {
 	// Validate that endpoint URL is provided
-	if flagEndpointURL == "" {
+	if setupOpts.Endpoint == "" {
 		return fmt.Errorf("--endpoint-url is required")
 	}
 
 	// Validate endpoint URL format
-	if err := validateEndpointURL(flagEndpointURL); err != nil {
+	if err := validateEndpointURL(setupOpts.Endpoint); err != nil {
 		return fmt.Errorf("invalid endpoint URL: %w", err)
 	}
 
@@ -78,12 +122,12 @@ func runSetup(cmd *cobra.Command, args []string) error {
 
 	// Handle server ID
 	var finalServerID string
-	if flagServerID != "" {
+	if setupOpts.ServerID != "" {
 		// Use provided server ID
-		if err := installer.ValidateServerID(flagServerID); err != nil {
+		if err := installer.ValidateServerID(setupOpts.ServerID); err != nil {
 			return fmt.Errorf("invalid server ID: %w", err)
 		}
-		finalServerID = flagServerID
+		finalServerID = setupOpts.ServerID
 		fmt.Printf("Using provided server ID: %s\n", finalServerID)
 	} else if existing.HasServerID {
 		// Keep existing server ID
@@ -101,35 +145,23 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		fmt.Printf("✓\n  %s\n", finalServerID)
 	}
 
-	// Build config options with hardcoded defaults
-	opts := installer.ConfigOptions{
-		// Server options
-		Endpoint: flagEndpointURL,
-		Timeout:  "5s",
+	// Build config options from flags
+	opts := setupOpts
+	opts.ServerID = finalServerID
 
-		// Agent options
-		ServerID: finalServerID,
-		Interval: "15s",
-
-		// Buffer options (hardcoded defaults)
-		BufferPath:           "/var/lib/nodepulse/buffer",
-		BufferRetentionHours: 48,
-
-		// Logging options (hardcoded defaults)
-		LogLevel:      "info",
-		LogOutput:     "stdout",
-		LogFilePath:   "/var/log/nodepulse/agent.log",
-		LogMaxSizeMB:  10,
-		LogMaxBackups: 3,
-		LogMaxAgeDays: 7,
-		LogCompress:   true,
+	// The flag defaults are the default instance's paths, so namespace them under --instance
+	if !cmd.Flags().Changed("buffer-path") {
+		opts.BufferPath = installer.BufferPath()
+	}
+	if !cmd.Flags().Changed("log-file") {
+		opts.LogFilePath = installer.LogFilePath()
 	}
 
 	fmt.Println()
 	fmt.Printf("Configuration:\n")
 	fmt.Printf("  Endpoint:  %s\n", opts.Endpoint)
 	fmt.Printf("  Server ID: %s\n", opts.ServerID)
-	fmt.Printf("  Interval:  %s (default)\n", opts.Interval)
+	fmt.Printf("  Interval:  %s\n", opts.Interval)
 	fmt.Println()
 
 	// Perform installation
@@ -149,6 +181,16 @@ func performInstallation(opts installer.ConfigOptions) error {
 	}
 	fmt.Println("✓")
 
+	// Create log directory up front when logging to file
+	if opts.LogOutput == "file" || opts.LogOutput == "both" {
+		fmt.Print("Preparing log directory... ")
+		if err := installer.PrepareLogDirectory(opts.LogFilePath); err != nil {
+			fmt.Println("✗")
+			return err
+		}
+		fmt.Println("✓")
+	}
+
 	// Persist server ID
 	fmt.Print("Persisting server ID... ")
 	if err := installer.PersistServerID(opts.ServerID); err != nil {
@@ -181,21 +223,61 @@ func performInstallation(opts installer.ConfigOptions) error {
 	}
 	fmt.Println("✓")
 
+	// Probe exporters - an unreachable one is only a warning, it may be installed later
+	if !flagSkipChecks {
+		cfg, err := config.Load(installer.ConfigPath())
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		probeExporters(os.Stdout, cfg.Exporters)
+	}
+
 	// Success
 	fmt.Println()
 	fmt.Println("✓ Node Pulse agent set up successfully!")
 	fmt.Println()
 	fmt.Printf("Server ID: %s\n", opts.ServerID)
-	fmt.Printf("Config:    %s\n", installer.DefaultConfigPath)
+	fmt.Printf("Config:    %s\n", installer.ConfigPath())
 	fmt.Println()
+	command := "nodepulse"
+	if config.Instance() != "" {
+		command += " --instance " + config.Instance()
+	}
 	fmt.Println("Next steps:")
-	fmt.Println("  1. Start the agent:    nodepulse start")
-	fmt.Println("  2. Install service:    sudo nodepulse service install")
+	fmt.Printf("  1. Start the agent:    %s start\n", command)
+	fmt.Printf("  2. Install service:    sudo %s service install\n", command)
 	fmt.Println()
 
 	return nil
 }
 
+// probeExporters checks that each enabled exporter responds, printing a warning for those that don't
+func probeExporters(w io.Writer, exporterCfgs []config.ExporterConfig) {
+	for _, exporterCfg := range exporterCfgs {
+		if !exporterCfg.Enabled {
+			continue
+		}
+
+		fmt.Fprintf(w, "Checking %s (%s)... ", exporterCfg.Name, exporterCfg.Endpoint)
+		exp, err := newExporter(exporterCfg)
+		if err == nil {
+			err = exp.Verify()
+		}
+		if err == nil {
+			fmt.Fprintln(w, "✓")
+			continue
+		}
+
+		fmt.Fprintln(w, "⚠")
+		fmt.Fprintf(w, "  Warning: %s is not reachable: %v\n", exporterCfg.Name, classifiedError(err))
+		if u, parseErr := url.Parse(exporterCfg.Endpoint); parseErr == nil && u.Port() != "" {
+			fmt.Fprintf(w, "  Check that %s is running and listening on port %s\n", exporterCfg.Name, u.Port())
+		} else {
+			fmt.Fprintf(w, "  Check that %s is running and the endpoint is correct\n", exporterCfg.Name)
+		}
+	}
+}
+
 // validateEndpointURL validates endpoint URL format
 func validateEndpointURL(endpointURL string) error {
 	// Parse and validate URL
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+func TestProbeExporters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "node_load1 0.5")
+	}))
+	defer server.Close()
+
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	closedAddr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+
+	var out bytes.Buffer
+	probeExporters(&out, []config.ExporterConfig{
+		{Name: "node_exporter", Enabled: true, Endpoint: server.URL, Timeout: time.Second},
+		{Name: "process_exporter", Enabled: true, Endpoint: fmt.Sprintf("http://%s/metrics", closedAddr), Timeout: time.Second},
+		{Name: "postgres_exporter", Enabled: false, Endpoint: "http://localhost:9187/metrics", Timeout: time.Second},
+	})
+
+	output := out.String()
+	if !strings.Contains(output, "node_exporter ("+server.URL+")... ✓") {
+		t.Errorf("Expected node_exporter to be reachable:\n%s", output)
+	}
+	if !strings.Contains(output, "Warning: process_exporter is not reachable") {
+		t.Errorf("Expected a warning for process_exporter:\n%s", output)
+	}
+	if !strings.Contains(output, fmt.Sprintf("listening on port %d", closedAddr.Port)) {
+		t.Errorf("Expected a port hint for process_exporter:\n%s", output)
+	}
+	if strings.Contains(output, "postgres_exporter") {
+		t.Errorf("Expected disabled exporters to be skipped:\n%s", output)
+	}
+}
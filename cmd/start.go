@@ -13,13 +13,20 @@ import (
 	"github.com/node-pulse/agent/internal/config"
 	"github.com/node-pulse/agent/internal/exporters"
 	"github.com/node-pulse/agent/internal/logger"
+	"github.com/node-pulse/agent/internal/oom"
 	"github.com/node-pulse/agent/internal/pidfile"
 	"github.com/node-pulse/agent/internal/prometheus"
 	"github.com/node-pulse/agent/internal/report"
+	"github.com/node-pulse/agent/internal/rlimit"
+	"github.com/node-pulse/agent/internal/sdnotify"
+	"github.com/node-pulse/agent/internal/telemetry"
 	"github.com/spf13/cobra"
 )
 
-var daemonFlag bool
+var (
+	daemonFlag     bool
+	supervisedFlag bool
+)
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
@@ -32,6 +39,8 @@ var startCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.Flags().BoolVarP(&daemonFlag, "daemon", "d", false, "Run in background (for development/debugging only)")
+	startCmd.Flags().BoolVar(&supervisedFlag, "supervised", false, "Run under a process supervisor (runit, supervisord, ...): stay in the foreground without a PID file")
+	startCmd.MarkFlagsMutuallyExclusive("daemon", "supervised")
 }
 
 func runAgent(cmd *cobra.Command, args []string) error {
@@ -40,16 +49,24 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Load configuration
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	configurePidFile(cfg)
+
 	// Handle daemon mode
 	if daemonFlag {
 		return runInBackground()
 	}
 
-	// Check if running under systemd (systemd sets INVOCATION_ID for all services)
-	isSystemdManaged := os.Getenv("INVOCATION_ID") != ""
+	// Only manage PID file if no supervisor tracks the process
+	if managesPidFile(supervisedFlag) {
+		if err := pidfile.CheckWritable(); err != nil {
+			return err
+		}
 
-	// Only manage PID file if NOT running under systemd
-	if !isSystemdManaged {
 		// Check if agent is already running
 		isRunning, existingPid, err := pidfile.CheckRunning()
 		if err != nil {
@@ -66,12 +83,6 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		defer pidfile.RemovePidFile()
 	}
 
-	// Load configuration
-	cfg, err := config.Load(cfgFile)
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
 	// Initialize logger
 	if err := logger.Initialize(cfg.Logging); err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
@@ -82,6 +93,15 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	for _, migration := range cfg.Migrations {
+		logger.Info("Migrated v1 config setting, update the config file to silence this",
+			logger.String("migration", migration),
+			logger.String("config_file", cfg.ConfigFile))
+	}
+
+	// Log effective resource limits (a low nofile limit otherwise surfaces as random scrape failures)
+	logResourceLimits()
+
 	// Create exporter registry
 	registry := exporters.NewRegistry()
 
@@ -92,37 +112,30 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	// registry.Register(exporters.NewMysqlExporter("", 0))
 
 	// Initialize enabled exporters from config
-	activeExporters := []exporters.Exporter{}
+	configured := []configuredExporter{}
 	for _, exporterCfg := range cfg.Exporters {
 		if !exporterCfg.Enabled {
 			continue
 		}
 
-		// Create exporter instance with configured endpoint and timeout
-		var exp exporters.Exporter
-		switch exporterCfg.Name {
-		case "node_exporter":
-			exp = exporters.NewNodeExporter(exporterCfg.Endpoint, exporterCfg.Timeout)
-		case "process_exporter":
-			exp = exporters.NewProcessExporter(exporterCfg.Endpoint, exporterCfg.Timeout)
-		default:
-			logger.Warn("Unknown exporter type, skipping", logger.String("name", exporterCfg.Name))
+		// Create exporter instance with configured endpoint, timeout, and transport options
+		exp, err := newExporter(exporterCfg)
+		if err != nil {
+			logger.Warn("Skipping exporter", logger.String("name", exporterCfg.Name), logger.Err(err))
 			continue
 		}
 
-		// Verify exporter is accessible
-		if err := exp.Verify(); err != nil {
-			logger.Warn("Exporter verification failed, skipping",
-				logger.String("name", exporterCfg.Name),
-				logger.String("endpoint", exporterCfg.Endpoint),
-				logger.Err(err))
-			continue
-		}
+		configured = append(configured, configuredExporter{exporter: exp, config: exporterCfg})
+	}
 
-		activeExporters = append(activeExporters, exp)
-		logger.Info("Exporter initialized",
-			logger.String("name", exporterCfg.Name),
-			logger.String("endpoint", exporterCfg.Endpoint))
+	// Verify exporters are accessible, retrying for up to agent.wait_for_exporters
+	// (interruptible so a stop during a boot race doesn't hang)
+	waitCtx, stopWait := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	activeExporters, stopped := verifyExporters(waitCtx, configured, cfg.Agent.WaitForExporters, exporterRetryInterval)
+	stopWait()
+	if stopped {
+		logger.Info("Stop requested while waiting for exporters, exiting")
+		return nil
 	}
 
 	if len(activeExporters) == 0 {
@@ -136,9 +149,26 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	}
 	defer sender.Close()
 
+	if sender.GetBufferStatus().FilesystemReadOnly {
+		logger.Warn("Continuing with read-only buffer filesystem - scrapes will be dropped until it is writable again",
+			logger.String("buffer_path", cfg.Buffer.Path))
+	}
+
+	if cfg.Server.TLS.InsecureSkipVerify {
+		logger.Warn("server.tls.insecure_skip_verify is enabled - the ingest endpoint's certificate is not verified")
+	} else if cfg.Server.TLS.AllowClockSkew {
+		logger.Warn("server.tls.allow_clock_skew is enabled - ingest certificates outside their validity period will be accepted")
+	}
+
 	// Start background draining goroutine (WAL pattern)
 	sender.StartDraining()
 
+	if cfg.Agent.HeartbeatInterval > 0 {
+		if err := sender.StartHeartbeat(cfg.Agent.ServerID, cfg.Agent.HeartbeatInterval); err != nil {
+			return err
+		}
+	}
+
 	// Setup signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -152,24 +182,86 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	// SIGUSR2 forces an immediate drain of the buffer (e.g. before maintenance)
+	flushChan := make(chan os.Signal, 1)
+	signal.Notify(flushChan, syscall.SIGUSR2)
+	defer signal.Stop(flushChan)
+	go handleFlushSignals(ctx, flushChan, sender.Flush)
+
 	// Launch independent scraper goroutine for each exporter (Phase 2)
 	var wg sync.WaitGroup
+	health := exporters.NewHealthTracker()
+
+	// Optional /metrics endpoint with the agent's own metrics
+	if cfg.Agent.TelemetryAddr != "" {
+		telemetryServer := telemetry.NewServer(sender, health, cfg.Agent.TelemetryToken)
+		if err := telemetryServer.Start(cfg.Agent.TelemetryAddr); err != nil {
+			logger.Warn("Telemetry server disabled", logger.Err(err))
+		} else {
+			defer func() {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				telemetryServer.Shutdown(shutdownCtx)
+			}()
+		}
+	}
 
 	logger.Info("Agent started",
+		logger.String("version", currentBuildInfo().String()),
 		logger.String("server_id", cfg.Agent.ServerID),
 		logger.Int("exporters", len(activeExporters)),
 		logger.String("server_endpoint", cfg.Server.Endpoint))
 
-	for i, exp := range activeExporters {
-		exporterCfg := cfg.Exporters[i]
+	// Inline timestamps for buffered metrics (some ingest parsers want seconds, or none at all)
+	// Only generic exporters' text reaches the ingest server, the parsers ignore them
+	timestamps := prometheus.TimestampOptions{
+		Disabled: !cfg.Server.InlineTimestamps,
+		Unit:     cfg.Server.TimestampUnit,
+	}
+
+	// OOM kills are read from the kernel log and reported with node_exporter scrapes
+	var oomWatcher *oom.Watcher
+	for _, active := range activeExporters {
+		if active.exporter.Name() == "node_exporter" {
+			oomWatcher = newOOMWatcher(cfg.Metrics.OOMSource)
+			break
+		}
+	}
+	if oomWatcher != nil {
+		defer oomWatcher.Close()
+	}
+
+	for _, active := range activeExporters {
+		exp := active.exporter
+		exporterCfg := active.config
 		interval := exporterCfg.ParsedInterval
 		timeout := exporterCfg.Timeout
 
+		opts := scrapeOptions{timestamps: timestamps, alignTimestamps: cfg.Agent.AlignTimestamps, maxLineBytes: cfg.Metrics.MaxLineBytes}
+		// Patterns were checked when the config was loaded
+		include, _ := config.CompileMetricPatterns(exporterCfg.MetricFilter.Include)
+		exclude, _ := config.CompileMetricPatterns(exporterCfg.MetricFilter.Exclude)
+		opts.filter = prometheus.NewMetricFilter(include, exclude)
+		if exp.Name() == "node_exporter" && oomWatcher != nil {
+			opts.oom = oomWatcher
+			oomWatcher = nil // Attach to one loop only, Collect isn't safe for concurrent use
+		}
+		if exp.Name() == "node_exporter" {
+			opts.checks = &nodeHealthChecks{parseOpts: report.NodeParseOptions(cfg)}
+		}
+
+		if interval < config.RecommendedMinInterval {
+			logger.Warn("Exporter interval is below the recommended minimum, expect higher load on the exporter and ingest endpoint",
+				logger.String("exporter", exp.Name()),
+				logger.Duration("interval", interval),
+				logger.Duration("recommended_min", config.RecommendedMinInterval))
+		}
+
 		wg.Add(1)
-		go func(exporter exporters.Exporter, scrapeInterval time.Duration, scrapeTimeout time.Duration) {
+		go func(exporter exporters.Exporter, scrapeInterval time.Duration, scrapeTimeout time.Duration, opts scrapeOptions) {
 			defer wg.Done()
-			runScraperLoop(ctx, exporter, sender, cfg.Agent.ServerID, scrapeInterval, scrapeTimeout)
-		}(exp, interval, timeout)
+			runScraperLoop(ctx, exporter, sender, health, cfg.Agent.ServerID, scrapeInterval, scrapeTimeout, opts)
+		}(exp, interval, timeout, opts)
 
 		logger.Info("Started scraper loop",
 			logger.String("exporter", exp.Name()),
@@ -177,28 +269,208 @@ func runAgent(cmd *cobra.Command, args []string) error {
 			logger.Duration("timeout", timeout))
 	}
 
+	// Tell systemd startup is complete (Type=notify) and start pinging the watchdog (WatchdogSec)
+	if _, err := sdnotify.Notify(sdnotify.Ready); err != nil {
+		logger.Warn("Failed to notify systemd of readiness", logger.Err(err))
+	}
+	watchdogInterval, err := sdnotify.WatchdogInterval()
+	if err != nil {
+		logger.Warn("Ignoring invalid systemd watchdog settings", logger.Err(err))
+	} else if watchdogInterval > 0 {
+		go sdnotify.RunWatchdog(ctx, watchdogInterval)
+		logger.Info("Systemd watchdog enabled", logger.Duration("timeout", watchdogInterval))
+	}
+
 	// Wait for shutdown signal
 	<-ctx.Done()
+	sdnotify.Notify(sdnotify.Stopping)
 
 	// Wait for all scraper goroutines to finish
 	logger.Info("Waiting for all scrapers to stop...")
 	wg.Wait()
 
+	// Give the drain goroutine a bounded chance to deliver what was scraped before shutdown
+	if timeout := cfg.Buffer.ShutdownFlushTimeout; timeout > 0 {
+		logger.Info("Flushing buffer before shutdown", logger.Duration("timeout", timeout))
+		if remaining := sender.FlushBeforeClose(timeout); remaining > 0 {
+			logger.Warn("Buffered files not delivered before shutdown, they will be sent after the next start",
+				logger.Int("files_remaining", remaining))
+		}
+	}
+
 	logger.Info("All scrapers stopped, agent shutdown complete")
 	return nil
 }
 
+// handleFlushSignals calls flush for each signal received until ctx is cancelled
+func handleFlushSignals(ctx context.Context, signals <-chan os.Signal, flush func()) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			logger.Info("Received flush signal, draining buffer now", logger.String("signal", sig.String()))
+			flush()
+		}
+	}
+}
+
+// exporterRetryInterval is the delay between verification attempts while waiting for exporters
+const exporterRetryInterval = 5 * time.Second
+
+// configuredExporter pairs an exporter instance with its config
+type configuredExporter struct {
+	exporter exporters.Exporter
+	config   config.ExporterConfig
+}
+
+// verifyExporters waits for the exporters (see waitForExporters) and reports whether ctx was
+// cancelled meanwhile. The stop signal is consumed by the wait, so the caller must exit rather
+// than start with the exporters verified so far
+func verifyExporters(ctx context.Context, configured []configuredExporter, wait, retryInterval time.Duration) ([]configuredExporter, bool) {
+	active := waitForExporters(ctx, configured, wait, retryInterval)
+	return active, ctx.Err() != nil
+}
+
+// waitForExporters verifies each exporter, retrying failed ones every retryInterval until all
+// are verified or wait has elapsed. Returns the verified exporters in config order.
+// With wait = 0, each exporter is verified once (fail fast).
+func waitForExporters(ctx context.Context, configured []configuredExporter, wait, retryInterval time.Duration) []configuredExporter {
+	deadline := time.Now().Add(wait)
+	verified := make([]bool, len(configured))
+	remaining := len(configured)
+
+retry:
+	for attempt := 1; ; attempt++ {
+		for i, c := range configured {
+			if verified[i] {
+				continue
+			}
+
+			if err := c.exporter.Verify(); err != nil {
+				logger.Warn("Exporter verification failed",
+					logger.String("name", c.config.Name),
+					logger.String("endpoint", c.config.Endpoint),
+					logger.String("error_class", exporters.ClassifyScrapeError(err)),
+					logger.Int("attempt", attempt),
+					logger.Err(err))
+				continue
+			}
+
+			verified[i] = true
+			remaining--
+			logger.Info("Exporter initialized",
+				logger.String("name", c.config.Name),
+				logger.String("endpoint", c.config.Endpoint))
+		}
+
+		if remaining == 0 || time.Now().Add(retryInterval).After(deadline) {
+			break
+		}
+
+		logger.Info("Waiting for exporters to become available",
+			logger.Int("pending", remaining),
+			logger.Duration("retry_in", retryInterval),
+			logger.Duration("time_left", time.Until(deadline).Round(time.Second)))
+
+		select {
+		case <-ctx.Done():
+			break retry
+		case <-time.After(retryInterval):
+		}
+	}
+
+	active := []configuredExporter{}
+	for i, c := range configured {
+		if verified[i] {
+			active = append(active, c)
+		} else {
+			logger.Warn("Skipping unavailable exporter", logger.String("name", c.config.Name))
+		}
+	}
+	return active
+}
+
+// newExporter creates an exporter instance from its config
+func newExporter(exporterCfg config.ExporterConfig) (exporters.Exporter, error) {
+	opts, err := exporterOptions(exporterCfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporterCfg.Type == config.ExporterTypeGeneric {
+		return exporters.NewGenericExporter(exporterCfg.Name, exporterCfg.Endpoint, exporterCfg.Timeout, opts...), nil
+	}
+	switch exporterCfg.Name {
+	case "node_exporter":
+		return exporters.NewNodeExporter(exporterCfg.Endpoint, exporterCfg.Timeout, opts...), nil
+	case "process_exporter":
+		return exporters.NewProcessExporter(exporterCfg.Endpoint, exporterCfg.Timeout, opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown exporter type: %s (set type: generic to forward its metrics unparsed)", exporterCfg.Name)
+	}
+}
+
+// exporterOptions builds the HTTP client options for an exporter from its config
+func exporterOptions(exporterCfg config.ExporterConfig) ([]exporters.Option, error) {
+	var opts []exporters.Option
+	if exporterCfg.TLS.PinSHA256 != "" {
+		opts = append(opts, exporters.WithPinnedCertificate(exporterCfg.TLS.PinSHA256))
+	}
+
+	auth := exporterCfg.Auth
+	if auth.Username != "" {
+		password, err := auth.ResolvePassword()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s password: %w", exporterCfg.Name, err)
+		}
+		opts = append(opts, exporters.WithBasicAuth(auth.Username, password))
+	}
+	token, err := auth.ResolveToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s token: %w", exporterCfg.Name, err)
+	}
+	if token != "" {
+		opts = append(opts, exporters.WithBearerToken(token))
+	}
+
+	return opts, nil
+}
+
+// scrapeOptions holds the per-exporter settings of a scraper loop
+type scrapeOptions struct {
+	timestamps      prometheus.TimestampOptions
+	alignTimestamps bool                     // Truncate collection times to the interval (agent.align_timestamps)
+	maxLineBytes    int                      // Longer lines are dropped from the scrape (metrics.max_line_bytes)
+	filter          *prometheus.MetricFilter // Drops unwanted metric families before buffering (nil = keep all)
+	oom             *oom.Watcher             // Appends OOM kills to the scrape (node_exporter only, nil = disabled)
+	checks          *nodeHealthChecks        // Logs host conditions seen in the scrape (node_exporter only)
+}
+
+// newOOMWatcher starts OOM kill detection, or returns nil when it's disabled or the source can't be read
+func newOOMWatcher(source string) *oom.Watcher {
+	watcher, err := oom.NewWatcher(source)
+	if err != nil {
+		logger.Warn("OOM kill detection disabled", logger.String("source", source), logger.Err(err))
+		return nil
+	}
+	if watcher != nil {
+		logger.Info("OOM kill detection enabled", logger.String("source", source))
+	}
+	return watcher
+}
+
 // runScraperLoop runs an independent scrape loop for a single exporter
 // Each exporter has its own ticker and runs at its configured interval
 func runScraperLoop(ctx context.Context, exporter exporters.Exporter,
-	sender *report.Sender, serverID string, interval time.Duration, timeout time.Duration) {
+	sender *report.Sender, health *exporters.HealthTracker, serverID string, interval time.Duration, timeout time.Duration,
+	opts scrapeOptions) {
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	// Scrape immediately on start with aligned timestamp (UTC)
-	collectionTime := time.Now().UTC().Truncate(interval)
-	scrapeAndBuffer(ctx, exporter, sender, serverID, collectionTime, timeout)
+	// Scrape immediately on start
+	collectionTime := scrapeCollectionTime(time.Now(), interval, opts.alignTimestamps)
+	scrapeAndBuffer(ctx, exporter, sender, health, serverID, collectionTime, timeout, opts)
 
 	// Continue with ticker
 	for {
@@ -208,16 +480,25 @@ func runScraperLoop(ctx context.Context, exporter exporters.Exporter,
 			return
 
 		case tickTime := <-ticker.C:
-			// Align collection time to interval boundary (UTC)
-			collectionTime := tickTime.UTC().Truncate(interval)
-			scrapeAndBuffer(ctx, exporter, sender, serverID, collectionTime, timeout)
+			collectionTime := scrapeCollectionTime(tickTime, interval, opts.alignTimestamps)
+			scrapeAndBuffer(ctx, exporter, sender, health, serverID, collectionTime, timeout, opts)
 		}
 	}
 }
 
+// scrapeCollectionTime returns the collection time of a scrape triggered at tickTime (UTC):
+// aligned to the interval boundary, or the exact current time when align is false
+func scrapeCollectionTime(tickTime time.Time, interval time.Duration, align bool) time.Time {
+	if !align {
+		return time.Now().UTC()
+	}
+	return tickTime.UTC().Truncate(interval)
+}
+
 // scrapeAndBuffer performs a single scrape operation for an exporter
 func scrapeAndBuffer(ctx context.Context, exporter exporters.Exporter,
-	sender *report.Sender, serverID string, collectionTime time.Time, timeout time.Duration) {
+	sender *report.Sender, health *exporters.HealthTracker, serverID string, collectionTime time.Time, timeout time.Duration,
+	opts scrapeOptions) {
 
 	// Create timeout context for scrape
 	scrapeCtx, cancel := context.WithTimeout(ctx, timeout)
@@ -226,14 +507,52 @@ func scrapeAndBuffer(ctx context.Context, exporter exporters.Exporter,
 	// Scrape metrics
 	data, err := exporter.Scrape(scrapeCtx)
 	if err != nil {
+		errorClass := health.RecordFailure(exporter.Name(), err)
 		logger.Warn("Failed to scrape exporter",
+			logger.String("exporter", exporter.Name()),
+			logger.String("error_class", errorClass),
+			logger.Int("consecutive_failures", health.Get(exporter.Name()).ConsecutiveFailures),
+			logger.Err(err))
+		return
+	}
+	health.RecordSuccess(exporter.Name())
+
+	// Skip overlong lines (e.g. a runaway label set) rather than losing the whole scrape
+	data, dropped := prometheus.DropLongLines(data, opts.maxLineBytes)
+	if dropped > 0 {
+		health.RecordDroppedLines(exporter.Name(), dropped)
+		logger.Warn("Dropped lines longer than metrics.max_line_bytes from scrape",
+			logger.String("exporter", exporter.Name()),
+			logger.Int("lines", dropped),
+			logger.Int("max_line_bytes", opts.maxLineBytes))
+	}
+
+	if opts.checks != nil {
+		opts.checks.check(data)
+	}
+
+	// Drop unwanted metric families before they take up buffer space and bandwidth
+	data, err = opts.filter.Apply(data)
+	if err != nil {
+		logger.Warn("Dropping scrape with an unparseable line",
 			logger.String("exporter", exporter.Name()),
 			logger.Err(err))
 		return
 	}
 
-	// Add explicit timestamps to metrics (aligned to collection time)
-	dataWithTimestamp := prometheus.AddTimestamps(data, collectionTime)
+	// Report OOM kills since the previous scrape alongside the exporter's metrics
+	if opts.oom != nil {
+		data = appendOOMKills(data, opts.oom)
+	}
+
+	// Add explicit timestamps to metrics (the collection time)
+	dataWithTimestamp, err := prometheus.AddTimestamps(data, collectionTime, opts.timestamps)
+	if err != nil {
+		logger.Warn("Dropping scrape with an unparseable line",
+			logger.String("exporter", exporter.Name()),
+			logger.Err(err))
+		return
+	}
 
 	// Save raw Prometheus text to buffer (WAL pattern)
 	if err := sender.BufferPrometheus(dataWithTimestamp, serverID, exporter.Name()); err != nil {
@@ -249,6 +568,112 @@ func scrapeAndBuffer(ctx context.Context, exporter exporters.Exporter,
 		logger.String("collection_time", collectionTime.Format(time.RFC3339)))
 }
 
+// appendOOMKills appends OOM kills logged since the previous scrape to the scraped metrics
+func appendOOMKills(data []byte, watcher *oom.Watcher) []byte {
+	kills, err := watcher.Collect()
+	if err != nil {
+		logger.Warn("Failed to read OOM kills", logger.String("source", watcher.Source()), logger.Err(err))
+	}
+	if len(kills) == 0 {
+		return data
+	}
+
+	for _, kill := range kills {
+		logger.Warn("Process killed by the OOM killer",
+			logger.String("process", kill.Process),
+			logger.Int("pid", kill.PID))
+	}
+
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	return append(data, oom.FormatMetrics(kills)...)
+}
+
+// nodeHealthChecks logs host conditions seen in node_exporter scrapes. They run at scrape
+// time rather than when the buffer drains, so warnings describe the host now, not backlog
+// One per scraper loop (not safe for concurrent use)
+type nodeHealthChecks struct {
+	parseOpts         prometheus.ParseOptions
+	entropyLow        bool // Whether the last scrape had low entropy, to log each drop and recovery once
+	memEstimateLogged bool // Whether the MemAvailable fallback was logged
+
+	// Whether the configured primary disk/interface was missing from the last scrape
+	primaryDiskMissing    bool
+	primaryNetworkMissing bool
+}
+
+// check parses a node_exporter scrape and logs changes in the conditions it tracks
+func (c *nodeHealthChecks) check(data []byte) {
+	snapshot, err := prometheus.ParseNodeExporterMetricsWithOptions(data, c.parseOpts)
+	if err != nil {
+		return // Reported when the buffer drains
+	}
+
+	if snapshot.MemoryAvailableEstimated && !c.memEstimateLogged {
+		c.memEstimateLogged = true
+		logger.Info("MemAvailable not reported (kernel older than 3.14?), estimating from MemFree + Buffers + Cached")
+	}
+
+	// Low entropy can stall crypto operations (including the agent's own TLS)
+	if low := snapshot.EntropyLow(); low != c.entropyLow {
+		c.entropyLow = low
+		if low {
+			logger.Warn("Available entropy is critically low, crypto operations (including TLS) may stall",
+				logger.Int64("entropy_available_bits", snapshot.EntropyAvailableBits),
+				logger.Int64("entropy_pool_size_bits", snapshot.EntropyPoolSizeBits))
+		} else {
+			logger.Info("Available entropy recovered",
+				logger.Int64("entropy_available_bits", snapshot.EntropyAvailableBits))
+		}
+	}
+
+	checkPrimaryDevice("node_exporter.primary_disk", c.parseOpts.PrimaryDisk, snapshot.DiskPrimaryDevice, &c.primaryDiskMissing)
+	checkPrimaryDevice("node_exporter.primary_network", c.parseOpts.PrimaryNetwork, snapshot.NetworkPrimaryInterface, &c.primaryNetworkMissing)
+}
+
+// checkPrimaryDevice logs once when a configured primary device is missing from the scrape
+// (and the parser fell back to another one), and once when it's back
+func checkPrimaryDevice(setting, configured, using string, missing *bool) {
+	if configured == "" {
+		return
+	}
+	if gone := using != configured; gone != *missing {
+		*missing = gone
+		if gone {
+			logger.Warn("Configured primary device not found in node_exporter scrape, reporting another one",
+				logger.String("setting", setting),
+				logger.String("configured", configured),
+				logger.String("using", using))
+		} else {
+			logger.Info("Configured primary device found in node_exporter scrape again",
+				logger.String("setting", setting),
+				logger.String("device", configured))
+		}
+	}
+}
+
+// managesPidFile reports whether the agent writes a PID file (for 'nodepulse stop')
+// Not under systemd (which sets INVOCATION_ID for all services) or with --supervised,
+// where the supervisor tracks the process and a stale PID file would only block restarts
+func managesPidFile(supervised bool) bool {
+	return !supervised && os.Getenv("INVOCATION_ID") == ""
+}
+
+// configurePidFile points the pidfile package at --pid-file, or agent.pid_file if set,
+// or the instance's default PID file with --instance
+// cfg may be nil for commands that also run without a usable config (e.g. stop)
+func configurePidFile(cfg *config.Config) {
+	switch {
+	case pidFileFlag != "":
+		pidfile.SetPath(pidFileFlag)
+	case cfg != nil && cfg.Agent.PidFile != "":
+		pidfile.SetPath(cfg.Agent.PidFile)
+	default:
+		pidfile.SetPath(config.InstancePath(pidfile.DefaultPath()))
+	}
+}
+
 func runInBackground() error {
 	// Check config exists
 	if err := config.RequireConfig(cfgFile); err != nil {
@@ -279,6 +704,12 @@ func runInBackground() error {
 	if cfgFile != "" {
 		args = append(args, "--config", cfgFile)
 	}
+	if pidFileFlag != "" {
+		args = append(args, "--pid-file", pidFileFlag)
+	}
+	if instanceFlag != "" {
+		args = append(args, "--instance", instanceFlag)
+	}
 
 	// Get the current executable path
 	executable, err := os.Executable()
@@ -304,3 +735,22 @@ func runInBackground() error {
 
 	return nil
 }
+
+// logResourceLimits logs the agent's effective rlimits and warns if the open files limit is low
+func logResourceLimits() {
+	limits, err := rlimit.Current()
+	if err != nil {
+		logger.Warn("Failed to read resource limits", logger.Err(err))
+		return
+	}
+
+	logger.Info("Resource limits",
+		logger.String("nofile", limits.NoFile.String()),
+		logger.String("nproc", limits.NProc.String()))
+
+	if limits.NoFileLow() {
+		logger.Warn("Open files limit is low, scrapes and sends may fail with 'too many open files' (raise LimitNOFILE or ulimit -n)",
+			logger.String("nofile", limits.NoFile.String()),
+			logger.Int("recommended_min", rlimit.MinNoFile))
+	}
+}
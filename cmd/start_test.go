@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/exporters"
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+// newFlakyExporterServer returns a server that fails until the given number of requests have been made
+func newFlakyExporterServer(t *testing.T, failures int32) *httptest.Server {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "node_load1 0.5")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newConfiguredExporter(name, endpoint string) configuredExporter {
+	cfg := config.ExporterConfig{Name: name, Endpoint: endpoint, Timeout: time.Second}
+	return configuredExporter{
+		exporter: exporters.NewNodeExporter(endpoint, time.Second),
+		config:   cfg,
+	}
+}
+
+func TestWaitForExporters_BecomeAvailable(t *testing.T) {
+	server := newFlakyExporterServer(t, 3)
+	configured := []configuredExporter{newConfiguredExporter("node_exporter", server.URL)}
+
+	active := waitForExporters(context.Background(), configured, 2*time.Second, 10*time.Millisecond)
+	if len(active) != 1 {
+		t.Fatalf("Expected exporter to become available within the wait window, got %d active", len(active))
+	}
+}
+
+func TestWaitForExporters_FailFast(t *testing.T) {
+	server := newFlakyExporterServer(t, 3)
+	configured := []configuredExporter{newConfiguredExporter("node_exporter", server.URL)}
+
+	start := time.Now()
+	active := waitForExporters(context.Background(), configured, 0, 10*time.Millisecond)
+	if len(active) != 0 {
+		t.Errorf("Expected no active exporters without a wait, got %d", len(active))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected fail fast, took %s", elapsed)
+	}
+}
+
+func TestWaitForExporters_GivesUpAfterWait(t *testing.T) {
+	up := newFlakyExporterServer(t, 0)
+	down := newFlakyExporterServer(t, 1000)
+	configured := []configuredExporter{
+		newConfiguredExporter("down", down.URL),
+		newConfiguredExporter("up", up.URL),
+	}
+
+	active := waitForExporters(context.Background(), configured, 100*time.Millisecond, 10*time.Millisecond)
+	if len(active) != 1 || active[0].config.Name != "up" {
+		t.Fatalf("Expected only the available exporter, got %+v", active)
+	}
+}
+
+func TestVerifyExporters_StoppedDuringWait(t *testing.T) {
+	up := newFlakyExporterServer(t, 0)
+	down := newFlakyExporterServer(t, 1000)
+	configured := []configuredExporter{
+		newConfiguredExporter("down", down.URL),
+		newConfiguredExporter("up", up.URL),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	active, stopped := verifyExporters(ctx, configured, time.Minute, 10*time.Millisecond)
+	if !stopped {
+		t.Errorf("Expected startup to stop after cancellation, got %d active exporters", len(active))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the wait to end on cancellation, took %v", elapsed)
+	}
+
+	if _, stopped := verifyExporters(context.Background(), configured[1:], 0, 10*time.Millisecond); stopped {
+		t.Error("Expected startup to continue without a stop")
+	}
+}
+
+func TestHandleFlushSignals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	flushed := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	go func() {
+		handleFlushSignals(ctx, signals, func() { flushed <- struct{}{} })
+		close(done)
+	}()
+
+	signals <- syscall.SIGUSR2
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected flush signal to trigger a drain attempt")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handleFlushSignals did not stop after context cancellation")
+	}
+}
+
+func TestManagesPidFile(t *testing.T) {
+	t.Setenv("INVOCATION_ID", "")
+	if !managesPidFile(false) {
+		t.Error("Expected a PID file when run from a terminal")
+	}
+	if managesPidFile(true) {
+		t.Error("Expected no PID file with --supervised")
+	}
+
+	t.Setenv("INVOCATION_ID", "0123456789abcdef")
+	if managesPidFile(false) {
+		t.Error("Expected no PID file under systemd")
+	}
+}
+
+func TestScrapeCollectionTime(t *testing.T) {
+	tickTime := time.Date(2025, 10, 15, 12, 0, 7, 250*int(time.Millisecond), time.UTC)
+
+	if got := scrapeCollectionTime(tickTime, 15*time.Second, true); !got.Equal(time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Aligned collection time = %v, want the 15s boundary", got)
+	}
+
+	before := time.Now().UTC()
+	got := scrapeCollectionTime(tickTime, 15*time.Second, false)
+	if got.Before(before) || got.After(time.Now().UTC()) || got.Location() != time.UTC {
+		t.Errorf("Unaligned collection time = %v, want the current UTC time", got)
+	}
+}
+
+func TestNodeHealthChecks(t *testing.T) {
+	checks := &nodeHealthChecks{}
+
+	steps := []struct {
+		name         string
+		scrape       string
+		wantLow      bool
+		wantEstimate bool
+	}{
+		{"plenty of entropy", "node_entropy_available_bits 3527\nnode_entropy_pool_size_bits 4096\n", false, false},
+		{"critically low", "node_entropy_available_bits 42\nnode_entropy_pool_size_bits 4096\n", true, false},
+		{"still low", "node_entropy_available_bits 50\nnode_entropy_pool_size_bits 4096\n", true, false},
+		{"recovered, no MemAvailable", "node_entropy_available_bits 1024\nnode_entropy_pool_size_bits 4096\nnode_memory_MemTotal_bytes 4e+09\nnode_memory_MemFree_bytes 1e+09\n", false, true},
+		{"collector disabled", "node_load1 0.5\n", false, true},
+	}
+
+	for _, step := range steps {
+		checks.check([]byte(step.scrape))
+		if checks.entropyLow != step.wantLow {
+			t.Errorf("%s: entropyLow = %v, want %v", step.name, checks.entropyLow, step.wantLow)
+		}
+		if checks.memEstimateLogged != step.wantEstimate {
+			t.Errorf("%s: memEstimateLogged = %v, want %v", step.name, checks.memEstimateLogged, step.wantEstimate)
+		}
+	}
+}
+
+func TestNodeHealthChecks_PrimaryDevice(t *testing.T) {
+	checks := &nodeHealthChecks{parseOpts: prometheus.ParseOptions{PrimaryDisk: "xvda", PrimaryNetwork: "eth1"}}
+
+	steps := []struct {
+		name        string
+		scrape      string
+		wantDisk    bool
+		wantNetwork bool
+	}{
+		{"both present", "node_disk_reads_completed_total{device=\"xvda\"} 1\nnode_network_receive_bytes_total{device=\"eth1\"} 1\n", false, false},
+		{"disk missing", "node_disk_reads_completed_total{device=\"sda\"} 1\nnode_network_receive_bytes_total{device=\"eth1\"} 1\n", true, false},
+		{"both missing", "node_disk_reads_completed_total{device=\"sda\"} 1\nnode_network_receive_bytes_total{device=\"eth0\"} 1\n", true, true},
+		{"both back", "node_disk_reads_completed_total{device=\"xvda\"} 1\nnode_network_receive_bytes_total{device=\"eth1\"} 1\n", false, false},
+	}
+
+	for _, step := range steps {
+		checks.check([]byte(step.scrape))
+		if checks.primaryDiskMissing != step.wantDisk || checks.primaryNetworkMissing != step.wantNetwork {
+			t.Errorf("%s: missing disk/network = %v/%v, want %v/%v", step.name,
+				checks.primaryDiskMissing, checks.primaryNetworkMissing, step.wantDisk, step.wantNetwork)
+		}
+	}
+}
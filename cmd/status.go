@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/report"
+	"github.com/node-pulse/agent/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -38,10 +46,17 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println("=====================")
 	fmt.Println()
 
+	fmt.Printf("Version:       %s\n", currentBuildInfo())
+	fmt.Println()
+
 	// Server ID
 	serverIDPath := config.GetServerIDPath()
 	fmt.Printf("Server ID:     %s\n", cfg.Agent.ServerID)
-	fmt.Printf("Persisted at:  %s\n", serverIDPath)
+	if envID := strings.TrimSpace(os.Getenv("NODEPULSE_SERVER_ID")); envID != "" && envID == cfg.Agent.ServerID {
+		fmt.Printf("Source:        NODEPULSE_SERVER_ID environment variable\n")
+	} else {
+		fmt.Printf("Persisted at:  %s\n", serverIDPath)
+	}
 	fmt.Println()
 
 	// Configuration
@@ -57,17 +72,53 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Agent/Service Status
 	serviceStatus := getServiceStatus()
 	fmt.Printf("Agent:         %s\n", serviceStatus)
+
+	// Warn if this binary differs from the one the service runs
+	if exePath, err := os.Executable(); err == nil {
+		warning, err := checkInstalledBinary(exePath, binaryPath)
+		if err != nil {
+			fmt.Printf("  WARNING:     could not compare with installed binary: %v\n", err)
+		} else if warning != "" {
+			fmt.Printf("  WARNING:     %s\n", warning)
+		}
+	}
 	fmt.Println()
 
 	// Buffer Status (always enabled in new architecture)
-	bufferCount, err := countBufferFiles(cfg.Buffer.Path)
+	// Read from disk rather than through a Sender, which would create the buffer directory,
+	// write a probe file, and fail without the ingest auth token
+	status, err := report.ReadStatus(cfg)
+	bufferStatus, deliveryStats, health := status.Buffer, status.Delivery, status.Health
+
 	if err != nil {
-		fmt.Printf("Buffer:        error checking: %v\n", err)
-	} else if bufferCount > 0 {
-		fmt.Printf("Buffer:        %d report(s) pending in %s\n", bufferCount, cfg.Buffer.Path)
+		fmt.Printf("Last delivery: error reading: %v\n", err)
+	} else {
+		fmt.Printf("Last delivery: %s\n", formatLastDelivery(deliveryStats, time.Now()))
+	}
+	if skew := deliveryStats.ClockSkew; skew != nil {
+		fmt.Printf("Clock skew:    %s\n", formatClockSkew(*skew))
+		if *skew > report.ClockSkewThreshold || *skew < -report.ClockSkewThreshold {
+			fmt.Printf("  WARNING:     metric timestamps are off by the same amount (check NTP, e.g. 'timedatectl status')\n")
+		}
+	}
+
+	if bufferStatus.HasBuffered {
+		fmt.Printf("Buffer:        %d report(s) pending in %s\n", bufferStatus.ReportCount, cfg.Buffer.Path)
+		fmt.Printf("  Files:       %d\n", bufferStatus.FileCount)
+		fmt.Printf("  Oldest:      %s (%s ago)\n",
+			bufferStatus.OldestFile.Format("2006-01-02 15:04:05"),
+			time.Since(bufferStatus.OldestFile).Round(time.Second))
+		fmt.Printf("  Total Size:  %d KB\n", bufferStatus.TotalSizeKB)
+		if health.Degraded {
+			fmt.Printf("  DEGRADED:    oldest buffered report is %s old (buffer.stale_threshold: %s)\n",
+				formatStaleAge(health.OldestAge), health.Threshold)
+		}
 	} else {
 		fmt.Printf("Buffer:        no pending reports\n")
 	}
+	if bufferStatus.FilesystemReadOnly {
+		fmt.Printf("  WARNING:     buffer filesystem is read-only (check 'dmesg' and remount read-write)\n")
+	}
 	fmt.Println()
 
 	// Logging
@@ -80,10 +131,57 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// formatLastDelivery describes the last successful delivery, e.g. "2m ago (1423 batches)"
+func formatLastDelivery(stats report.DeliveryStats, now time.Time) string {
+	if stats.LastDelivery.IsZero() {
+		return "never"
+	}
+
+	batches := "batches"
+	if stats.BatchesSent == 1 {
+		batches = "batch"
+	}
+	return fmt.Sprintf("%s ago (%d %s)", formatAge(now.Sub(stats.LastDelivery)), stats.BatchesSent, batches)
+}
+
+// formatClockSkew describes the agent clock relative to the ingest server, e.g. "+7.2s (agent ahead of ingest server)"
+func formatClockSkew(skew time.Duration) string {
+	switch {
+	case skew > 0:
+		return fmt.Sprintf("+%s (agent ahead of ingest server)", skew)
+	case skew < 0:
+		return fmt.Sprintf("%s (agent behind ingest server)", skew)
+	default:
+		return "0s (in sync with ingest server)"
+	}
+}
+
+// formatStaleAge formats a backlog age to the minute, e.g. "3h12m" (seconds below a minute)
+func formatStaleAge(d time.Duration) string {
+	if d < time.Minute {
+		return d.Truncate(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Truncate(time.Minute).String(), "0s")
+}
+
+// formatAge formats a duration in its largest whole unit (e.g. 45s, 2m, 3h, 5d)
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 // getServiceStatus checks if the systemd service is running
 func getServiceStatus() string {
 	// Try to check systemd status
-	cmd := exec.Command("systemctl", "is-active", "nodepulse")
+	cmd := exec.Command("systemctl", "is-active", service.UnitName(config.Instance()))
 	output, err := cmd.Output()
 
 	if err == nil && string(output) == "active\n" {
@@ -91,7 +189,7 @@ func getServiceStatus() string {
 	}
 
 	// Check if service exists but is not active
-	cmd = exec.Command("systemctl", "is-enabled", "nodepulse")
+	cmd = exec.Command("systemctl", "is-enabled", service.UnitName(config.Instance()))
 	_, err = cmd.Output()
 
 	if err == nil {
@@ -101,12 +199,47 @@ func getServiceStatus() string {
 	return "not installed as systemd service"
 }
 
-// countBufferFiles counts the number of .prom files in the buffer directory
-func countBufferFiles(bufferPath string) (int, error) {
-	pattern := filepath.Join(bufferPath, "*.prom")
-	files, err := filepath.Glob(pattern)
+// checkInstalledBinary compares the running executable with the installed service binary
+// Returns a warning message if they differ, or empty string if they match or no binary is installed
+func checkInstalledBinary(runningPath, installedPath string) (string, error) {
+	if _, err := os.Stat(installedPath); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	// Same file (e.g., status run from the installed binary itself)
+	if resolved, err := filepath.EvalSymlinks(runningPath); err == nil && resolved == installedPath {
+		return "", nil
+	}
+
+	runningHash, err := fileSHA256(runningPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash running binary: %w", err)
+	}
+	installedHash, err := fileSHA256(installedPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash installed binary: %w", err)
+	}
+
+	if runningHash == installedHash {
+		return "", nil
+	}
+
+	return fmt.Sprintf("running binary (%s, sha256 %s) differs from installed service binary (%s, sha256 %s)\n"+
+		"               Run 'sudo nodepulse service install' to update the service binary",
+		runningPath, runningHash[:12], installedPath, installedHash[:12]), nil
+}
+
+// fileSHA256 returns the hex-encoded SHA-256 hash of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return 0, err
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
 	}
-	return len(files), nil
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
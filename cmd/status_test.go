@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/report"
+)
+
+func writeFakeBinary(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake binary: %v", err)
+	}
+	return path
+}
+
+func TestCheckInstalledBinary(t *testing.T) {
+	dir := t.TempDir()
+	running := writeFakeBinary(t, dir, "nodepulse-new", "\x7fELF fake binary v2")
+	installed := writeFakeBinary(t, dir, "nodepulse-installed", "\x7fELF fake binary v1")
+	identical := writeFakeBinary(t, dir, "nodepulse-copy", "\x7fELF fake binary v1")
+
+	t.Run("different hashes warn", func(t *testing.T) {
+		warning, err := checkInstalledBinary(running, installed)
+		if err != nil {
+			t.Fatalf("checkInstalledBinary failed: %v", err)
+		}
+		if !strings.Contains(warning, "differs from installed service binary") {
+			t.Errorf("Expected mismatch warning, got: %q", warning)
+		}
+	})
+
+	t.Run("identical hashes do not warn", func(t *testing.T) {
+		warning, err := checkInstalledBinary(identical, installed)
+		if err != nil {
+			t.Fatalf("checkInstalledBinary failed: %v", err)
+		}
+		if warning != "" {
+			t.Errorf("Expected no warning, got: %q", warning)
+		}
+	})
+
+	t.Run("no installed binary", func(t *testing.T) {
+		warning, err := checkInstalledBinary(running, filepath.Join(dir, "missing"))
+		if err != nil {
+			t.Fatalf("checkInstalledBinary failed: %v", err)
+		}
+		if warning != "" {
+			t.Errorf("Expected no warning when service binary is not installed, got: %q", warning)
+		}
+	})
+}
+
+func TestFormatLastDelivery(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		stats report.DeliveryStats
+		want  string
+	}{
+		{"never delivered", report.DeliveryStats{}, "never"},
+		{"seconds", report.DeliveryStats{LastDelivery: now.Add(-45 * time.Second), BatchesSent: 1}, "45s ago (1 batch)"},
+		{"minutes", report.DeliveryStats{LastDelivery: now.Add(-2*time.Minute - 10*time.Second), BatchesSent: 1423}, "2m ago (1423 batches)"},
+		{"hours", report.DeliveryStats{LastDelivery: now.Add(-3 * time.Hour), BatchesSent: 7}, "3h ago (7 batches)"},
+		{"days", report.DeliveryStats{LastDelivery: now.Add(-50 * time.Hour), BatchesSent: 7}, "2d ago (7 batches)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLastDelivery(tt.stats, now); got != tt.want {
+				t.Errorf("formatLastDelivery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatClockSkew(t *testing.T) {
+	tests := []struct {
+		skew time.Duration
+		want string
+	}{
+		{7200 * time.Millisecond, "+7.2s (agent ahead of ingest server)"},
+		{-3 * time.Second, "-3s (agent behind ingest server)"},
+		{0, "0s (in sync with ingest server)"},
+	}
+
+	for _, tt := range tests {
+		if got := formatClockSkew(tt.skew); got != tt.want {
+			t.Errorf("formatClockSkew(%s) = %q, want %q", tt.skew, got, tt.want)
+		}
+	}
+}
+
+func TestFormatStaleAge(t *testing.T) {
+	tests := map[time.Duration]string{
+		3*time.Hour + 12*time.Minute + 40*time.Second: "3h12m",
+		2 * time.Hour:                         "2h0m",
+		45*time.Second + 300*time.Millisecond: "45s",
+	}
+	for age, want := range tests {
+		if got := formatStaleAge(age); got != want {
+			t.Errorf("formatStaleAge(%s) = %q, want %q", age, got, want)
+		}
+	}
+}
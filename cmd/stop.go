@@ -7,7 +7,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/node-pulse/agent/internal/config"
 	"github.com/node-pulse/agent/internal/pidfile"
+	"github.com/node-pulse/agent/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +26,10 @@ func init() {
 }
 
 func stopAgent(cmd *cobra.Command, args []string) error {
+	// agent.pid_file may move the PID file; without a usable config the default path is used
+	cfg, _ := config.Load(cfgFile)
+	configurePidFile(cfg)
+
 	// Check if agent is running
 	isRunning, pid, err := pidfile.CheckRunning()
 	if err != nil {
@@ -89,6 +95,6 @@ func stopAgent(cmd *cobra.Command, args []string) error {
 
 // isSystemdServiceActive checks if the nodepulse systemd service is active
 func isSystemdServiceActive() bool {
-	cmd := exec.Command("systemctl", "is-active", "--quiet", "nodepulse")
+	cmd := exec.Command("systemctl", "is-active", "--quiet", service.UnitName(config.Instance()))
 	return cmd.Run() == nil
 }
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/exporters"
+	"github.com/node-pulse/agent/internal/prometheus"
+	"github.com/node-pulse/agent/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Validate exporter and ingest connectivity end-to-end",
+	Long: `Scrapes each enabled exporter once, parses the metrics, and sends a single report
+to the ingest endpoint, printing the result and timing of each step.
+
+Exits non-zero if any exporter is unreachable or the endpoint rejects the payload,
+so it can be used in provisioning scripts before enabling the service.`,
+	SilenceUsage: true,
+	RunE:         runTest,
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+}
+
+// testStep is the outcome of a single connectivity check step
+type testStep struct {
+	Name     string
+	Duration time.Duration
+	Detail   string
+	Err      error
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	// Check config exists
+	if err := config.RequireConfig(cfgFile); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fmt.Println("Node Pulse Connectivity Test")
+	fmt.Println("============================")
+	fmt.Println()
+
+	failed := false
+	payload := report.Payload{DeployID: cfg.Agent.DeployID}
+
+	for _, exporterCfg := range cfg.Exporters {
+		if !exporterCfg.Enabled {
+			continue
+		}
+
+		fmt.Printf("%s (%s)\n", exporterCfg.Name, exporterCfg.Endpoint)
+		steps := checkExporter(cmd.Context(), exporterCfg, report.NodeParseOptions(cfg), &payload)
+		for _, step := range steps {
+			printTestStep(step)
+			if step.Err != nil {
+				failed = true
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("ingest (%s)\n", cfg.Server.Endpoint)
+	if payload.Empty() {
+		printTestStep(testStep{Name: "send", Err: fmt.Errorf("skipped: no metrics collected")})
+		failed = true
+	} else {
+		step := sendTestPayload(cfg, payload)
+		printTestStep(step)
+		if step.Err != nil {
+			failed = true
+		}
+	}
+	fmt.Println()
+
+	if failed {
+		return fmt.Errorf("connectivity test failed")
+	}
+
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// checkExporter verifies, scrapes, and parses a single exporter
+// Returns the steps performed; parsed metrics are added to payload only if every step succeeded
+func checkExporter(ctx context.Context, exporterCfg config.ExporterConfig, nodeOpts prometheus.ParseOptions, payload *report.Payload) []testStep {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	exp, err := newExporter(exporterCfg)
+	if err != nil {
+		return []testStep{{Name: "verify", Err: err}}
+	}
+
+	var steps []testStep
+
+	// Verify
+	start := time.Now()
+	err = exp.Verify()
+	steps = append(steps, testStep{Name: "verify", Duration: time.Since(start), Err: classifiedError(err)})
+	if err != nil {
+		return steps
+	}
+
+	// Scrape
+	start = time.Now()
+	data, err := exp.Scrape(ctx)
+	step := testStep{Name: "scrape", Duration: time.Since(start), Err: classifiedError(err)}
+	if err == nil {
+		step.Detail = fmt.Sprintf("%d bytes", len(data))
+	}
+	steps = append(steps, step)
+	if err != nil {
+		return steps
+	}
+
+	// Parse
+	start = time.Now()
+	detail, err := parseTestScrape(exporterCfg, nodeOpts, data, payload)
+	steps = append(steps, testStep{Name: "parse", Duration: time.Since(start), Detail: detail, Err: err})
+
+	return steps
+}
+
+// parseTestScrape parses scraped data and adds it to the payload, as the drain goroutine does
+func parseTestScrape(exporterCfg config.ExporterConfig, nodeOpts prometheus.ParseOptions, data []byte, payload *report.Payload) (string, error) {
+	if exporterCfg.Type == config.ExporterTypeGeneric {
+		// Forwarded unparsed, so only check it's valid text format
+		if err := prometheus.ValidateText(data); err != nil {
+			return "", err
+		}
+		payload.AddGeneric(exporterCfg.Name, report.GenericSnapshot{Timestamp: time.Now().UTC(), Metrics: string(data)})
+		return fmt.Sprintf("%d bytes, forwarded unparsed", len(data)), nil
+	}
+
+	switch exporterName := exporterCfg.Name; exporterName {
+	case "node_exporter":
+		snapshot, err := prometheus.ParseNodeExporterMetricsWithOptions(data, nodeOpts)
+		if err != nil {
+			return "", err
+		}
+		payload.NodeExporter = append(payload.NodeExporter, *snapshot)
+		return "1 snapshot", nil
+	case "process_exporter":
+		snapshots, err := prometheus.ParseProcessExporterMetrics(data)
+		if err != nil {
+			return "", err
+		}
+		payload.ProcessExporter = append(payload.ProcessExporter, snapshots...)
+		return fmt.Sprintf("%d process groups", len(snapshots)), nil
+	default:
+		return "", fmt.Errorf("unknown exporter type: %s", exporterName)
+	}
+}
+
+// sendTestPayload sends the parsed metrics to the ingest endpoint in a single request
+func sendTestPayload(cfg *config.Config, payload report.Payload) testStep {
+	sender, err := report.NewSender(cfg)
+	if err != nil {
+		return testStep{Name: "send", Err: err}
+	}
+	defer sender.Close()
+
+	start := time.Now()
+	err = sender.SendOnce(payload, cfg.Agent.ServerID)
+	return testStep{Name: "send", Duration: time.Since(start), Err: err}
+}
+
+// classifiedError prefixes a scrape error with its error class
+func classifiedError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", exporters.ClassifyScrapeError(err), err)
+}
+
+// printTestStep prints a single step result
+func printTestStep(step testStep) {
+	if step.Err != nil {
+		fmt.Printf("  %-8s FAIL  %-8s %v\n", step.Name, formatStepDuration(step.Duration), step.Err)
+		return
+	}
+	fmt.Printf("  %-8s OK    %-8s %s\n", step.Name, formatStepDuration(step.Duration), step.Detail)
+}
+
+// formatStepDuration rounds step timings for display
+func formatStepDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
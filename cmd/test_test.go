@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/prometheus"
+	"github.com/node-pulse/agent/internal/report"
+)
+
+func TestCheckExporter(t *testing.T) {
+	t.Run("reachable exporter", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "node_load1 0.5")
+			fmt.Fprintln(w, "node_memory_MemTotal_bytes 1024")
+		}))
+		defer server.Close()
+
+		var payload report.Payload
+		steps := checkExporter(context.Background(), config.ExporterConfig{
+			Name:     "node_exporter",
+			Endpoint: server.URL,
+			Timeout:  time.Second,
+		}, prometheus.ParseOptions{}, &payload)
+
+		if len(steps) != 3 {
+			t.Fatalf("Expected 3 steps (verify, scrape, parse), got %d", len(steps))
+		}
+		for _, step := range steps {
+			if step.Err != nil {
+				t.Errorf("Step %s failed: %v", step.Name, step.Err)
+			}
+		}
+
+		snapshots := payload.NodeExporter
+		if len(snapshots) != 1 {
+			t.Fatalf("Expected one node_exporter snapshot, got %#v", payload)
+		}
+		if snapshots[0].Load1Min != 0.5 {
+			t.Errorf("Expected load1 0.5, got %v", snapshots[0].Load1Min)
+		}
+	})
+
+	t.Run("unreachable exporter", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		var payload report.Payload
+		steps := checkExporter(context.Background(), config.ExporterConfig{
+			Name:     "node_exporter",
+			Endpoint: url,
+			Timeout:  time.Second,
+		}, prometheus.ParseOptions{}, &payload)
+
+		if !payload.Empty() {
+			t.Errorf("Expected no metrics, got %#v", payload)
+		}
+		if len(steps) != 1 || steps[0].Name != "verify" || steps[0].Err == nil {
+			t.Fatalf("Expected a single failed verify step, got %+v", steps)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Run: func(cmd *cobra.Command, args []string) {
+		printBuildInfo(os.Stdout, currentBuildInfo())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
+
+// buildInfo describes the running binary
+type buildInfo struct {
+	Version    string
+	Commit     string
+	BuildDate  string
+	CommitDate string // Only set when the build date is unknown
+	GoVersion  string
+	Platform   string // GOOS/GOARCH
+}
+
+// currentBuildInfo returns the build metadata of the running binary
+// For binaries built without -ldflags, the commit falls back to the VCS info Go embeds in
+// module builds (plain 'go build' from a checkout). That only has the commit time, not the
+// build time, so it's reported as CommitDate and the build date stays unknown
+func currentBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.CommitDate = setting.Value
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// String returns a one-line summary, e.g. "1.4.2 (commit 1a2b3c4, built 2025-10-15T12:00:00Z)"
+// or "(commit 1a2b3c4, committed ...)" when only the commit time is known
+func (b buildInfo) String() string {
+	commit := b.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if b.CommitDate != "" {
+		return fmt.Sprintf("%s (commit %s, committed %s)", b.Version, commit, b.CommitDate)
+	}
+	return fmt.Sprintf("%s (commit %s, built %s)", b.Version, commit, b.BuildDate)
+}
+
+// printBuildInfo prints the build metadata, one field per line
+func printBuildInfo(w io.Writer, info buildInfo) {
+	fmt.Fprintf(w, "Version:    %s\n", info.Version)
+	fmt.Fprintf(w, "Commit:     %s\n", info.Commit)
+	fmt.Fprintf(w, "Build date: %s\n", info.BuildDate)
+	if info.CommitDate != "" {
+		fmt.Fprintf(w, "Committed:  %s\n", info.CommitDate)
+	}
+	fmt.Fprintf(w, "Go version: %s\n", info.GoVersion)
+	fmt.Fprintf(w, "OS/Arch:    %s\n", info.Platform)
+}
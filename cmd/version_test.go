@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	info := buildInfo{
+		Version:   "1.4.2",
+		Commit:    "1a2b3c4d5e6f7a8b9c0d",
+		BuildDate: "2025-10-15T12:00:00Z",
+		GoVersion: "go1.24.0",
+		Platform:  "linux/amd64",
+	}
+
+	if got, want := info.String(), "1.4.2 (commit 1a2b3c4, built 2025-10-15T12:00:00Z)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	var out bytes.Buffer
+	printBuildInfo(&out, info)
+	for _, want := range []string{"Version:    1.4.2", "Commit:     1a2b3c4d5e6f7a8b9c0d", "Go version: go1.24.0", "OS/Arch:    linux/amd64"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestBuildInfo_CommitDateOnly(t *testing.T) {
+	info := buildInfo{Version: "dev", Commit: "1a2b3c4d5e6f", BuildDate: "unknown", CommitDate: "2025-10-14T09:30:00Z"}
+
+	if got, want := info.String(), "dev (commit 1a2b3c4, committed 2025-10-14T09:30:00Z)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	var out bytes.Buffer
+	printBuildInfo(&out, info)
+	for _, want := range []string{"Build date: unknown", "Committed:  2025-10-14T09:30:00Z"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCurrentBuildInfo_Defaults(t *testing.T) {
+	info := currentBuildInfo()
+	if info.Version != Version {
+		t.Errorf("Version = %q, want %q", info.Version, Version)
+	}
+	// Never empty, even for binaries built without -ldflags or VCS info
+	if info.Commit == "" || info.BuildDate == "" {
+		t.Errorf("Expected commit and build date placeholders, got %+v", info)
+	}
+}
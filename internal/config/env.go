@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envPrefix is the prefix of environment variables overriding config keys
+// e.g. NODEPULSE_SERVER_ENDPOINT overrides server.endpoint
+const envPrefix = "NODEPULSE"
+
+// bindEnv makes every config key overridable by an environment variable named after it:
+// NODEPULSE_ + the key in upper case with dots replaced by underscores
+// Precedence: environment > config file > defaults. List entries (exporters) and map entries
+// (server.headers) can't be overridden, as they have no fixed key
+func bindEnv(v *viper.Viper) {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// AutomaticEnv only covers keys viper already knows (defaults or the file) when
+	// unmarshaling, so bind the rest explicitly
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		v.BindEnv(key)
+	}
+}
+
+// configKeys returns the dotted keys of a config struct's settings, from its mapstructure tags
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		key := prefix + name
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(field.Type, key+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			// Lists of sections (exporters) have no fixed keys
+		case field.Type.Kind() == reflect.Map:
+			// Nor do maps (server.headers)
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
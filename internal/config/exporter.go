@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// UnixSocketPath returns the socket path of a unix:// or socket: endpoint,
+// e.g. "/run/node_exporter.sock" for "unix:///run/node_exporter.sock"
+func UnixSocketPath(endpoint string) (string, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "unix" && u.Scheme != "socket") {
+		return "", false
+	}
+	// "unix://run/x.sock" parses with host "run"; keep it so validation rejects the relative path
+	return u.Host + u.Path, true
+}
+
+// NormalizeFingerprint lowercases a hex fingerprint and strips colon separators
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
+
+// ValidateFingerprint checks that a fingerprint is a hex-encoded SHA-256 hash
+func ValidateFingerprint(fingerprint string) error {
+	normalized := NormalizeFingerprint(fingerprint)
+	decoded, err := hex.DecodeString(normalized)
+	if err != nil {
+		return fmt.Errorf("fingerprint must be hex-encoded: %w", err)
+	}
+	if len(decoded) != sha256.Size {
+		return fmt.Errorf("fingerprint must be a SHA-256 hash (%d bytes), got %d bytes", sha256.Size, len(decoded))
+	}
+	return nil
+}
+
+// CompileMetricPatterns compiles metric_filter patterns: globs matched against the whole
+// family name (node_cpu_*, node_?isk_*), or regular expressions between slashes (/^node_(cpu|memory)_/)
+func CompileMetricPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		var expr string
+		if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expr = pattern[1 : len(pattern)-1]
+		} else {
+			expr = "^" + strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(regexp.QuoteMeta(pattern)) + "$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		path     string
+		ok       bool
+	}{
+		{"unix:///run/node_exporter.sock", "/run/node_exporter.sock", true},
+		{"socket:/run/node_exporter.sock", "/run/node_exporter.sock", true},
+		{"http://localhost:9100/metrics", "", false},
+	}
+
+	for _, tt := range tests {
+		path, ok := UnixSocketPath(tt.endpoint)
+		if path != tt.path || ok != tt.ok {
+			t.Errorf("UnixSocketPath(%q) = (%q, %v), want (%q, %v)", tt.endpoint, path, ok, tt.path, tt.ok)
+		}
+	}
+}
+
+func TestValidateFingerprint(t *testing.T) {
+	valid := strings.Repeat("ab", sha256.Size)
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		wantErr     bool
+	}{
+		{"hex", valid, false},
+		{"uppercase with colons", "AB:" + strings.Repeat("AB:", sha256.Size-2) + "AB", false},
+		{"not hex", strings.Repeat("zz", sha256.Size), true},
+		{"too short", "abcd", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFingerprint(tt.fingerprint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFingerprint(%q) error = %v, wantErr %v", tt.fingerprint, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileMetricPatterns(t *testing.T) {
+	patterns, err := CompileMetricPatterns([]string{"node_cpu_*", "node_?isk_*", "/^go_/"})
+	if err != nil {
+		t.Fatalf("CompileMetricPatterns failed: %v", err)
+	}
+
+	matches := map[string][]bool{
+		"node_cpu_seconds_total":        {true, false, false},
+		"node_disk_reads_total":         {false, true, false},
+		"go_goroutines":                 {false, false, true},
+		"prefix_node_cpu_seconds_total": {false, false, false}, // Globs match the whole name
+	}
+	for name, want := range matches {
+		for i, re := range patterns {
+			if got := re.MatchString(name); got != want[i] {
+				t.Errorf("pattern %d on %s = %v, want %v", i, name, got, want[i])
+			}
+		}
+	}
+
+	if _, err := CompileMetricPatterns([]string{"/node_(/"}); err == nil {
+		t.Error("Expected an invalid regex to be rejected")
+	}
+}
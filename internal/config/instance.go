@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+// instanceNamePattern restricts instance names to what's safe in paths and systemd unit names
+var instanceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// instance is the agent instance name set with --instance (empty = the default instance)
+var instance string
+
+// SetInstance namespaces the agent's config, state, and PID file paths under name, so several
+// agents can run on one host. An empty name selects the default instance and today's paths
+func SetInstance(name string) error {
+	if name != "" && !instanceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q: use up to 32 lowercase letters, digits, '-' or '_'", name)
+	}
+	instance = name
+	return nil
+}
+
+// Instance returns the instance name set with SetInstance (empty for the default instance)
+func Instance() string {
+	return instance
+}
+
+// InstancePath returns the path of a file or directory for the current instance:
+// unchanged for the default instance, otherwise in a subdirectory named after the instance
+// e.g. /var/lib/nodepulse/buffer -> /var/lib/nodepulse/<instance>/buffer
+func InstancePath(path string) string {
+	if instance == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), instance, filepath.Base(path))
+}
+
+// instanceDir returns a directory for the current instance (a subdirectory named after it)
+func instanceDir(dir string) string {
+	if instance == "" {
+		return dir
+	}
+	return filepath.Join(dir, instance)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSetInstance(t *testing.T) {
+	t.Cleanup(func() { SetInstance("") })
+
+	for _, name := range []string{"Web", "../etc", "a b", "web@1", "abcdefghijklmnopqrstuvwxyz0123456"} {
+		if err := SetInstance(name); err == nil {
+			t.Errorf("Expected instance name %q to be rejected", name)
+		}
+	}
+
+	if err := SetInstance("db-1"); err != nil {
+		t.Fatalf("SetInstance failed: %v", err)
+	}
+	if Instance() != "db-1" {
+		t.Errorf("Instance() = %q, want db-1", Instance())
+	}
+}
+
+func TestInstancePath(t *testing.T) {
+	t.Cleanup(func() { SetInstance("") })
+
+	if got := InstancePath("/var/lib/nodepulse/buffer"); got != "/var/lib/nodepulse/buffer" {
+		t.Errorf("Expected the default instance to keep today's paths, got %s", got)
+	}
+
+	SetInstance("db")
+	tests := map[string]string{
+		"/var/lib/nodepulse/buffer":    "/var/lib/nodepulse/db/buffer",
+		"/var/lib/nodepulse/server_id": "/var/lib/nodepulse/db/server_id",
+		"/var/run/nodepulse.pid":       "/var/run/db/nodepulse.pid",
+	}
+	for path, want := range tests {
+		if got := InstancePath(path); got != want {
+			t.Errorf("InstancePath(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestLoad_InstanceBufferPath(t *testing.T) {
+	t.Cleanup(func() { SetInstance("") })
+	SetInstance("db")
+
+	path := writeTestConfig(t, `
+agent:
+  server_id: "test-server"
+exporters:
+  - name: node_exporter
+    enabled: true
+    endpoint: "http://localhost:9100/metrics"
+    timeout: 3s
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Buffer.Path != "/var/lib/nodepulse/db/buffer" {
+		t.Errorf("Expected the instance's default buffer path, got %s", cfg.Buffer.Path)
+	}
+	if cfg.Logging.File.Path != "/var/log/nodepulse/db/agent.log" {
+		t.Errorf("Expected the instance's default log file, got %s", cfg.Logging.File.Path)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// Migrate rewrites settings from the v1 config format, and renamed settings, so old config
+// files keep loading. v1 scraped a single node_exporter configured in a "prometheus" section
+// and had an optional buffer; v2 has an exporters array and the buffer is always on
+// Returns a description of each migration applied. It runs before the logger is initialized
+// (the logging settings may themselves need migrating), so the caller logs them (see Config.Migrations)
+func Migrate(v *viper.Viper) []string {
+	var applied []string
+
+	// v1: prometheus.endpoint -> v2: exporters[0] (node_exporter)
+	if !v.IsSet("exporters") && v.IsSet("prometheus.endpoint") {
+		enabled := true
+		if v.IsSet("prometheus.enabled") {
+			enabled = v.GetBool("prometheus.enabled")
+		}
+		timeout := v.GetString("prometheus.timeout")
+		if timeout == "" {
+			timeout = "3s"
+		}
+
+		v.Set("exporters", []interface{}{
+			map[string]interface{}{
+				"name":     "node_exporter",
+				"enabled":  enabled,
+				"endpoint": v.GetString("prometheus.endpoint"),
+				"timeout":  timeout,
+			},
+		})
+
+		applied = append(applied, fmt.Sprintf("converted the v1 prometheus section to a node_exporter entry in exporters (endpoint %s)",
+			v.GetString("prometheus.endpoint")))
+	}
+
+	// v1: buffer.enabled -> v2: the buffer is always on, so the key is ignored
+	if v.InConfig("buffer.enabled") {
+		applied = append(applied, "ignored the obsolete buffer.enabled setting (the buffer is always enabled)")
+	}
+
+	// metrics.process_scan_limit -> process_exporter.max_groups (it caps the payload, not the scan)
+	if v.InConfig("metrics.process_scan_limit") {
+		if v.InConfig("process_exporter.max_groups") {
+			applied = append(applied, "ignored metrics.process_scan_limit, process_exporter.max_groups is also set")
+		} else {
+			v.Set("process_exporter.max_groups", v.Get("metrics.process_scan_limit"))
+			applied = append(applied, "renamed metrics.process_scan_limit to process_exporter.max_groups")
+		}
+	}
+
+	return applied
+}
@@ -11,13 +11,26 @@ import (
 
 const (
 	serverIDFileName = "server_id"
+
+	// serverIDEnv sets the server ID without a persisted file (e.g. for containers)
+	serverIDEnv = "NODEPULSE_SERVER_ID"
+
+	// maxHostnameIDLength caps the hostname part of a hostname-based server ID
+	maxHostnameIDLength = 48
+)
+
+// Server ID strategies (agent.server_id_strategy)
+const (
+	ServerIDStrategyUUID     = "uuid"
+	ServerIDStrategyHostname = "hostname"
 )
 
 // EnsureServerID ensures a server ID exists, generating one if needed
 // Priority:
 // 1. Config file value (if valid)
-// 2. Persisted file value
-// 3. Auto-generate new UUID and persist it
+// 2. NODEPULSE_SERVER_ID environment variable (if valid; nothing is persisted)
+// 3. Persisted file value
+// 4. Auto-generate a new ID (per agent.server_id_strategy) and persist it
 func EnsureServerID(cfg *Config) error {
 	// If config has a valid server ID that's not the placeholder, use it
 	if cfg.Agent.ServerID != "" && cfg.Agent.ServerID != "00000000-0000-0000-0000-000000000000" {
@@ -26,6 +39,15 @@ func EnsureServerID(cfg *Config) error {
 		}
 	}
 
+	// Identity injected by the orchestrator
+	if envID := strings.TrimSpace(os.Getenv(serverIDEnv)); envID != "" {
+		if !isValidServerID(envID) {
+			return fmt.Errorf("invalid %s %q: use letters, digits, and dashes", serverIDEnv, envID)
+		}
+		cfg.Agent.ServerID = envID
+		return nil
+	}
+
 	// Try to load from persisted file
 	serverIDPath := GetServerIDPath()
 	if persistedID, err := loadServerID(serverIDPath); err == nil && isValidServerID(persistedID) {
@@ -33,8 +55,8 @@ func EnsureServerID(cfg *Config) error {
 		return nil
 	}
 
-	// Generate new UUID
-	newID, err := generateUUID()
+	// Generate a new ID
+	newID, err := generateServerID(cfg.Agent.ServerIDStrategy)
 	if err != nil {
 		return fmt.Errorf("failed to generate server ID: %w", err)
 	}
@@ -48,7 +70,60 @@ func EnsureServerID(cfg *Config) error {
 	return nil
 }
 
+// generateServerID generates a new server ID using the given strategy (empty = uuid)
+func generateServerID(strategy string) (string, error) {
+	switch strategy {
+	case "", ServerIDStrategyUUID:
+		return generateUUID()
+	case ServerIDStrategyHostname:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", fmt.Errorf("failed to get hostname: %w", err)
+		}
+		return hostnameServerID(hostname)
+	default:
+		return "", fmt.Errorf("unknown agent.server_id_strategy %q", strategy)
+	}
+}
+
+// hostnameServerID derives a readable server ID from a hostname, e.g. "Web_01.example.com" ->
+// "web-01-example-com-3fa2c1". The random suffix keeps hosts with identical hostnames (cloned
+// images, default container hostnames) apart; the ID is persisted, so it's generated only once
+func hostnameServerID(hostname string) (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	name := sanitizeHostname(hostname)
+	if name == "" {
+		name = "host"
+	}
+	return name + "-" + hex.EncodeToString(suffix), nil
+}
+
+// sanitizeHostname lowercases a hostname and maps it to the isValidServerID charset:
+// other characters become dashes, runs of dashes collapse, and leading/trailing dashes are trimmed
+func sanitizeHostname(hostname string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(hostname) {
+		if isAlphanumeric(c) {
+			b.WriteRune(c)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxHostnameIDLength {
+			break
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
 // GetServerIDPath returns the path where server_id is persisted
+// Instances other than the default use a subdirectory named after the instance
 func GetServerIDPath() string {
 	// Try standard locations
 	locations := []string{
@@ -58,16 +133,16 @@ func GetServerIDPath() string {
 		"./server_id", // Fallback to current directory
 	}
 
-	// Use first writable location
+	// Use first writable location (the instance subdirectory is created on save)
 	for _, path := range locations {
 		dir := filepath.Dir(path)
 		if isWritable(dir) {
-			return path
+			return InstancePath(path)
 		}
 	}
 
 	// Last resort: current directory
-	return "./server_id"
+	return InstancePath("./server_id")
 }
 
 // loadServerID loads server ID from file
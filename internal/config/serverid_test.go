@@ -0,0 +1,87 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnsureServerID_FromEnv(t *testing.T) {
+	t.Setenv(serverIDEnv, "web-01")
+
+	t.Run("env used when config has placeholder", func(t *testing.T) {
+		cfg := &Config{Agent: AgentConfig{ServerID: "00000000-0000-0000-0000-000000000000"}}
+		if err := EnsureServerID(cfg); err != nil {
+			t.Fatalf("EnsureServerID failed: %v", err)
+		}
+		if cfg.Agent.ServerID != "web-01" {
+			t.Errorf("ServerID = %q, want web-01 from %s", cfg.Agent.ServerID, serverIDEnv)
+		}
+	})
+
+	t.Run("config value takes precedence", func(t *testing.T) {
+		cfg := &Config{Agent: AgentConfig{ServerID: "from-config"}}
+		if err := EnsureServerID(cfg); err != nil {
+			t.Fatalf("EnsureServerID failed: %v", err)
+		}
+		if cfg.Agent.ServerID != "from-config" {
+			t.Errorf("ServerID = %q, want from-config", cfg.Agent.ServerID)
+		}
+	})
+
+	t.Run("invalid env rejected", func(t *testing.T) {
+		t.Setenv(serverIDEnv, "web_01!")
+		if err := EnsureServerID(&Config{}); err == nil {
+			t.Error("Expected an invalid server ID to be rejected")
+		}
+	})
+}
+
+func TestSanitizeHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"web-01", "web-01"},
+		{"Web_01.example.com", "web-01-example-com"},
+		{"--db..primary--", "db-primary"},
+		{"ünïcode", "n-code"},
+		{"___", ""},
+		{strings.Repeat("a", 80), strings.Repeat("a", maxHostnameIDLength)},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeHostname(tt.hostname); got != tt.want {
+			t.Errorf("sanitizeHostname(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestHostnameServerID(t *testing.T) {
+	first, err := hostnameServerID("Web_01.example.com")
+	if err != nil {
+		t.Fatalf("hostnameServerID failed: %v", err)
+	}
+	if !strings.HasPrefix(first, "web-01-example-com-") || !isValidServerID(first) {
+		t.Errorf("hostnameServerID = %q, want a valid ID starting with web-01-example-com-", first)
+	}
+
+	// Identical hostnames must not produce identical IDs
+	second, err := hostnameServerID("Web_01.example.com")
+	if err != nil {
+		t.Fatalf("hostnameServerID failed: %v", err)
+	}
+	if first == second {
+		t.Errorf("Expected different IDs for identical hostnames, both got %q", first)
+	}
+
+	// Hostnames with nothing usable still yield a valid ID
+	if id, err := hostnameServerID("___"); err != nil || !strings.HasPrefix(id, "host-") || !isValidServerID(id) {
+		t.Errorf("hostnameServerID(\"___\") = %q, %v; want a valid ID starting with host-", id, err)
+	}
+}
+
+func TestGenerateServerID_UnknownStrategy(t *testing.T) {
+	if _, err := generateServerID("fqdn"); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+}
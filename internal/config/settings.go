@@ -0,0 +1,96 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// secretSettings are setting names whose values are redacted by Settings
+var secretSettings = map[string]bool{"token": true, "password": true, "telemetry_token": true}
+
+// urlSettings are setting names holding URLs, whose userinfo (user:pass@) is redacted by Settings
+var urlSettings = map[string]bool{"endpoint": true, "proxy_url": true}
+
+// headerSettings are setting names holding HTTP headers, whose values are redacted by Settings
+// (they often carry API keys, e.g. for a CDN or WAF in front of the ingest endpoint)
+var headerSettings = map[string]bool{"headers": true}
+
+// Settings returns the resolved configuration as nested maps keyed like the YAML file,
+// for printing what the agent will actually use (defaults merged, server ID resolved)
+// Durations are formatted as strings (15s), computed fields are left out, and tokens,
+// passwords, URL credentials, and header values are redacted unless includeSecrets is set
+func Settings(cfg *Config, includeSecrets bool) map[string]interface{} {
+	return structSettings(reflect.ValueOf(*cfg), includeSecrets)
+}
+
+// structSettings converts a config struct to a map using its mapstructure tags
+func structSettings(v reflect.Value, includeSecrets bool) map[string]interface{} {
+	settings := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		value := settingValue(v.Field(i), includeSecrets)
+		if !includeSecrets && !v.Field(i).IsZero() {
+			value = redactSetting(name, value)
+		}
+		settings[name] = value
+	}
+	return settings
+}
+
+// redactSetting returns a set value with its secrets replaced by REDACTED
+func redactSetting(name string, value interface{}) interface{} {
+	switch {
+	case secretSettings[name]:
+		return "REDACTED"
+	case urlSettings[name]:
+		raw, _ := value.(string)
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "REDACTED" // Can't tell where the credentials are
+		}
+		if u.User != nil {
+			u.User = url.User("REDACTED")
+		}
+		return u.String()
+	case headerSettings[name]:
+		headers, _ := value.(map[string]string)
+		redacted := make(map[string]string, len(headers))
+		for header := range headers {
+			redacted[header] = "REDACTED"
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// settingValue converts a config value to a plain value for printing
+func settingValue(v reflect.Value, includeSecrets bool) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return structSettings(v, includeSecrets)
+	case reflect.Slice:
+		if v.IsNil() {
+			return []interface{}{}
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = settingValue(v.Index(i), includeSecrets)
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
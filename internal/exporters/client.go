@@ -0,0 +1,138 @@
+package exporters
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+// errCertificatePinMismatch is returned when the exporter's certificate doesn't match the pinned fingerprint
+var errCertificatePinMismatch = errors.New("certificate fingerprint does not match pinned sha256")
+
+// Option configures the HTTP client used to scrape an exporter
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	pinSHA256 string // Hex-encoded SHA-256 of the expected leaf certificate
+	auth      requestAuth
+}
+
+// requestAuth holds the credentials attached to every scrape request
+type requestAuth struct {
+	username string
+	password string
+	token    string
+}
+
+// apply sets the Authorization header on a scrape request, if credentials are configured
+func (a requestAuth) apply(req *http.Request) {
+	switch {
+	case a.token != "":
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	case a.username != "":
+		req.SetBasicAuth(a.username, a.password)
+	}
+}
+
+// WithPinnedCertificate validates the exporter's TLS certificate by SHA-256 fingerprint
+// instead of the system CA pool. Useful for single-host exporters with self-signed certs.
+// The fingerprint is hex-encoded and may contain colons (as printed by openssl)
+func WithPinnedCertificate(sha256Fingerprint string) Option {
+	return func(o *clientOptions) {
+		o.pinSHA256 = sha256Fingerprint
+	}
+}
+
+// WithBasicAuth scrapes the exporter with HTTP basic auth
+func WithBasicAuth(username, password string) Option {
+	return func(o *clientOptions) {
+		o.auth.username = username
+		o.auth.password = password
+	}
+}
+
+// WithBearerToken scrapes the exporter with an "Authorization: Bearer" header
+func WithBearerToken(token string) Option {
+	return func(o *clientOptions) {
+		o.auth.token = token
+	}
+}
+
+// newClientOptions applies the options over the defaults
+func newClientOptions(opts []Option) clientOptions {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// unixSocketRequestURL is the request URL for socket endpoints; the host is never dialed
+const unixSocketRequestURL = "http://unix/metrics"
+
+// resolveEndpoint returns the URL to request and, for socket endpoints, the socket to dial
+func resolveEndpoint(endpoint string) (requestURL, socketPath string) {
+	if path, ok := config.UnixSocketPath(endpoint); ok {
+		return unixSocketRequestURL, path
+	}
+	return endpoint, ""
+}
+
+// newHTTPClient builds the HTTP client for an exporter from the given options
+// A non-empty socketPath makes every connection dial that Unix domain socket
+func newHTTPClient(timeout time.Duration, socketPath string, o clientOptions) *http.Client {
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	if o.pinSHA256 == "" && socketPath == "" {
+		return client
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.pinSHA256 != "" {
+		transport.TLSClientConfig = &tls.Config{
+			// Chain verification is replaced by the fingerprint check below
+			InsecureSkipVerify:    true,
+			VerifyPeerCertificate: pinnedCertificateVerifier(o.pinSHA256),
+		}
+	}
+	if socketPath != "" {
+		var dialer net.Dialer
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+	client.Transport = transport
+
+	return client
+}
+
+// pinnedCertificateVerifier returns a VerifyPeerCertificate callback that accepts only
+// a leaf certificate whose SHA-256 fingerprint matches the pinned value
+func pinnedCertificateVerifier(fingerprint string) func([][]byte, [][]*x509.Certificate) error {
+	expected := config.NormalizeFingerprint(fingerprint)
+
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("%w: no certificate presented", errCertificatePinMismatch)
+		}
+
+		sum := sha256.Sum256(rawCerts[0])
+		actual := hex.EncodeToString(sum[:])
+		if actual != expected {
+			return fmt.Errorf("%w: got %s", errCertificatePinMismatch, actual)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package exporters
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPinnedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "node_load1 0.5")
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	good := hex.EncodeToString(sum[:])
+
+	t.Run("matching fingerprint", func(t *testing.T) {
+		exp := NewNodeExporter(server.URL, time.Second, WithPinnedCertificate(good))
+		if _, err := exp.Scrape(context.Background()); err != nil {
+			t.Fatalf("Scrape() with pinned fingerprint failed: %v", err)
+		}
+	})
+
+	t.Run("matching fingerprint with colons", func(t *testing.T) {
+		var parts []string
+		for i := 0; i < len(good); i += 2 {
+			parts = append(parts, strings.ToUpper(good[i:i+2]))
+		}
+		exp := NewNodeExporter(server.URL, time.Second, WithPinnedCertificate(strings.Join(parts, ":")))
+		if _, err := exp.Scrape(context.Background()); err != nil {
+			t.Fatalf("Scrape() with colon-separated fingerprint failed: %v", err)
+		}
+	})
+
+	t.Run("mismatched fingerprint", func(t *testing.T) {
+		wrong := strings.Repeat("00", sha256.Size)
+		exp := NewNodeExporter(server.URL, time.Second, WithPinnedCertificate(wrong))
+		_, err := exp.Scrape(context.Background())
+		if err == nil {
+			t.Fatal("Scrape() with wrong fingerprint succeeded, want error")
+		}
+		if got := ClassifyScrapeError(err); got != ErrorClassTLS {
+			t.Errorf("ClassifyScrapeError() = %q, want %q", got, ErrorClassTLS)
+		}
+	})
+}
+
+func TestUnixSocketEndpoint(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "node_exporter.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix sockets not supported: %v", err)
+	}
+
+	var gotPath string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprintln(w, "node_load1 0.5")
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	for _, endpoint := range []string{"unix://" + socketPath, "socket:" + socketPath} {
+		t.Run(endpoint, func(t *testing.T) {
+			gotPath = ""
+			exp := NewNodeExporter(endpoint, time.Second)
+			data, err := exp.Scrape(context.Background())
+			if err != nil {
+				t.Fatalf("Scrape() over socket failed: %v", err)
+			}
+			if !strings.Contains(string(data), "node_load1") {
+				t.Errorf("Scrape() = %q, want node_load1", data)
+			}
+			if gotPath != "/metrics" {
+				t.Errorf("request path = %q, want /metrics", gotPath)
+			}
+		})
+	}
+
+	t.Run("missing socket", func(t *testing.T) {
+		exp := NewProcessExporter("unix://"+filepath.Join(t.TempDir(), "missing.sock"), time.Second)
+		if _, err := exp.Scrape(context.Background()); err == nil {
+			t.Fatal("Scrape() of a missing socket succeeded, want error")
+		}
+	})
+}
+
+func TestScrapeAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); ok && user == "monitor" && pass == "s3cret" {
+			fmt.Fprintln(w, "pg_up 1")
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer tok" {
+			fmt.Fprintln(w, "pg_up 1")
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"basic auth", []Option{WithBasicAuth("monitor", "s3cret")}, false},
+		{"bearer token", []Option{WithBearerToken("tok")}, false},
+		{"wrong password", []Option{WithBasicAuth("monitor", "wrong")}, true},
+		{"no credentials", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := NewProcessExporter(server.URL, time.Second, tt.opts...)
+			_, err := exp.Scrape(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Scrape() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
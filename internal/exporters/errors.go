@@ -0,0 +1,130 @@
+package exporters
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Scrape error classes
+// Used in logs and the health tracker so operators can tell failure modes apart
+const (
+	ErrorClassDNS        = "dns"         // Hostname could not be resolved
+	ErrorClassConnect    = "connect"     // Connection refused, unreachable, reset
+	ErrorClassTimeout    = "timeout"     // Request or dial deadline exceeded
+	ErrorClassTLS        = "tls"         // Handshake or certificate verification failed
+	ErrorClassHTTPStatus = "http_status" // Exporter returned a non-200 status
+	ErrorClassRead       = "read"        // Failed while reading the response body
+	ErrorClassUnknown    = "unknown"
+)
+
+// ScrapeError is a classified scrape failure
+type ScrapeError struct {
+	Class      string
+	StatusCode int // Set for ErrorClassHTTPStatus
+	Err        error
+}
+
+func (e *ScrapeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ScrapeError) Unwrap() error {
+	return e.Err
+}
+
+// newRequestError classifies an error returned by http.Client.Do
+func newRequestError(err error) error {
+	return &ScrapeError{
+		Class: classifyRequestError(err),
+		Err:   fmt.Errorf("HTTP request failed: %w", err),
+	}
+}
+
+// newStatusError creates an error for a non-200 exporter response
+func newStatusError(statusCode int) error {
+	return &ScrapeError{
+		Class:      ErrorClassHTTPStatus,
+		StatusCode: statusCode,
+		Err:        fmt.Errorf("server returned status %d", statusCode),
+	}
+}
+
+// newReadError creates an error for a failed response body read
+func newReadError(err error) error {
+	class := ErrorClassRead
+	if isTimeout(err) {
+		class = ErrorClassTimeout
+	}
+	return &ScrapeError{
+		Class: class,
+		Err:   fmt.Errorf("failed to read response body: %w", err),
+	}
+}
+
+// ClassifyScrapeError returns the error class for a scrape error
+// Errors not produced by an exporter are classified by inspecting the underlying cause
+func ClassifyScrapeError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var scrapeErr *ScrapeError
+	if errors.As(err, &scrapeErr) {
+		return scrapeErr.Class
+	}
+
+	return classifyRequestError(err)
+}
+
+// classifyRequestError inspects a transport-level error
+// Order matters: DNS and TLS errors can also report Timeout()
+func classifyRequestError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+
+	if isTLSError(err) {
+		return ErrorClassTLS
+	}
+
+	if isTimeout(err) {
+		return ErrorClassTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassConnect
+	}
+
+	return ErrorClassUnknown
+}
+
+// isTimeout checks for context deadlines and net.Error timeouts
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isTLSError checks for handshake and certificate verification failures
+func isTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	return errors.Is(err, errCertificatePinMismatch) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &certErr) ||
+		errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
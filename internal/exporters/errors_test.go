@@ -0,0 +1,161 @@
+package exporters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassifyScrapeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		tls     bool
+		closed  bool
+		timeout time.Duration
+		want    string
+	}{
+		{
+			name:   "connection refused",
+			closed: true,
+			want:   ErrorClassConnect,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			timeout: 50 * time.Millisecond,
+			want:    ErrorClassTimeout,
+		},
+		{
+			name: "http 500",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: ErrorClassHTTPStatus,
+		},
+		{
+			name: "untrusted certificate",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("test_metric 1\n"))
+			},
+			tls:  true,
+			want: ErrorClassTLS,
+		},
+		{
+			name: "truncated body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Promise more bytes than are sent, then drop the connection
+				w.Header().Set("Content-Length", "1000")
+				w.Write([]byte("test_metric 1\n"))
+			},
+			want: ErrorClassRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, r *http.Request) {}
+			}
+
+			var server *httptest.Server
+			if tt.tls {
+				server = httptest.NewTLSServer(handler)
+			} else {
+				server = httptest.NewServer(handler)
+			}
+			endpoint := server.URL
+			if tt.closed {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 3 * time.Second
+			}
+
+			_, err := NewNodeExporter(endpoint, timeout).Scrape(context.Background())
+			if err == nil {
+				t.Fatal("Expected scrape error")
+			}
+			if got := ClassifyScrapeError(err); got != tt.want {
+				t.Errorf("ClassifyScrapeError() = %q, want %q (err: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestClassifyScrapeError_Causes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "dns failure",
+			err:  &net.DNSError{Err: "no such host", Name: "exporter.invalid", IsNotFound: true},
+			want: ErrorClassDNS,
+		},
+		{
+			name: "context deadline",
+			err:  fmt.Errorf("scrape: %w", context.DeadlineExceeded),
+			want: ErrorClassTimeout,
+		},
+		{
+			name: "dial error",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: no route to host")},
+			want: ErrorClassConnect,
+		},
+		{
+			name: "wrapped scrape error keeps class",
+			err:  fmt.Errorf("verification failed: %w", newStatusError(503)),
+			want: ErrorClassHTTPStatus,
+		},
+		{
+			name: "unrelated error",
+			err:  errors.New("something else"),
+			want: ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyScrapeError(tt.err); got != tt.want {
+				t.Errorf("ClassifyScrapeError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthTracker(t *testing.T) {
+	h := NewHealthTracker()
+
+	h.RecordFailure("node_exporter", newStatusError(500))
+	h.RecordFailure("node_exporter", newStatusError(502))
+
+	health := h.Get("node_exporter")
+	if health.ConsecutiveFailures != 2 {
+		t.Errorf("ConsecutiveFailures = %d, want 2", health.ConsecutiveFailures)
+	}
+	if health.LastErrorClass != ErrorClassHTTPStatus {
+		t.Errorf("LastErrorClass = %q, want %q", health.LastErrorClass, ErrorClassHTTPStatus)
+	}
+	if health.ErrorCounts[ErrorClassHTTPStatus] != 2 {
+		t.Errorf("ErrorCounts[http_status] = %d, want 2", health.ErrorCounts[ErrorClassHTTPStatus])
+	}
+
+	h.RecordSuccess("node_exporter")
+	if got := h.Get("node_exporter").ConsecutiveFailures; got != 0 {
+		t.Errorf("ConsecutiveFailures after success = %d, want 0", got)
+	}
+}
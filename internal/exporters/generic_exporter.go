@@ -0,0 +1,85 @@
+package exporters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// GenericExporter scrapes any exporter that serves Prometheus text format
+// The agent has no parser for it: the raw text is buffered and forwarded as is
+type GenericExporter struct {
+	name     string
+	endpoint string
+	url      string // Request URL; differs from endpoint for Unix socket endpoints
+	timeout  time.Duration
+	client   *http.Client
+	auth     requestAuth
+}
+
+var _ Exporter = (*GenericExporter)(nil)
+
+// NewGenericExporter creates a GenericExporter with the configured name
+func NewGenericExporter(name, endpoint string, timeout time.Duration, opts ...Option) *GenericExporter {
+	if timeout == 0 {
+		timeout = 3 * time.Second
+	}
+	requestURL, socketPath := resolveEndpoint(endpoint)
+	o := newClientOptions(opts)
+
+	return &GenericExporter{
+		name:     name,
+		endpoint: endpoint,
+		url:      requestURL,
+		timeout:  timeout,
+		client:   newHTTPClient(timeout, socketPath, o),
+		auth:     o.auth,
+	}
+}
+
+// Name returns the exporter name from the config
+func (e *GenericExporter) Name() string {
+	return e.name
+}
+
+// Endpoint returns the metrics endpoint URL
+func (e *GenericExporter) Endpoint() string {
+	return e.endpoint
+}
+
+// Scrape fetches the exporter's metrics
+func (e *GenericExporter) Scrape(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", e.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	e.auth.apply(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, newRequestError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, newReadError(err)
+	}
+
+	return data, nil
+}
+
+// Verify checks if the exporter is accessible
+func (e *GenericExporter) Verify() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	_, err := e.Scrape(ctx)
+	return err
+}
@@ -0,0 +1,113 @@
+package exporters
+
+import (
+	"sync"
+	"time"
+)
+
+// ExporterHealth is the scrape health of a single exporter
+type ExporterHealth struct {
+	LastSuccess         time.Time
+	LastFailure         time.Time
+	LastErrorClass      string
+	LastError           string
+	ConsecutiveFailures int
+	SuccessCount        int            // Total successful scrapes
+	ErrorCounts         map[string]int // Total failures by error class
+	LinesDropped        int            // Total lines dropped from scrapes for exceeding metrics.max_line_bytes
+}
+
+// HealthTracker records per-exporter scrape outcomes
+// Safe for concurrent use by the per-exporter scraper goroutines
+type HealthTracker struct {
+	mu     sync.Mutex
+	health map[string]*ExporterHealth // key: exporter name
+}
+
+// NewHealthTracker creates a new health tracker
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		health: make(map[string]*ExporterHealth),
+	}
+}
+
+// RecordSuccess records a successful scrape
+func (h *HealthTracker) RecordSuccess(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	eh := h.get(name)
+	eh.LastSuccess = time.Now()
+	eh.ConsecutiveFailures = 0
+	eh.SuccessCount++
+}
+
+// RecordDroppedLines records lines dropped from a successful scrape for being too long
+func (h *HealthTracker) RecordDroppedLines(name string, lines int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.get(name).LinesDropped += lines
+}
+
+// RecordFailure records a failed scrape and returns its error class
+func (h *HealthTracker) RecordFailure(name string, err error) string {
+	class := ClassifyScrapeError(err)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	eh := h.get(name)
+	eh.LastFailure = time.Now()
+	eh.LastErrorClass = class
+	eh.LastError = err.Error()
+	eh.ConsecutiveFailures++
+	eh.ErrorCounts[class]++
+
+	return class
+}
+
+// Get returns a copy of an exporter's health
+func (h *HealthTracker) Get(name string) ExporterHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	eh, ok := h.health[name]
+	if !ok {
+		return ExporterHealth{}
+	}
+	return eh.copy()
+}
+
+// All returns a copy of every exporter's health, keyed by exporter name
+func (h *HealthTracker) All() map[string]ExporterHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	all := make(map[string]ExporterHealth, len(h.health))
+	for name, eh := range h.health {
+		all[name] = eh.copy()
+	}
+	return all
+}
+
+// copy returns a deep copy of the health entry
+func (eh *ExporterHealth) copy() ExporterHealth {
+	snapshot := *eh
+	snapshot.ErrorCounts = make(map[string]int, len(eh.ErrorCounts))
+	for class, count := range eh.ErrorCounts {
+		snapshot.ErrorCounts[class] = count
+	}
+	return snapshot
+}
+
+// get returns the health entry for an exporter, creating it if needed
+// Caller must hold h.mu
+func (h *HealthTracker) get(name string) *ExporterHealth {
+	eh, ok := h.health[name]
+	if !ok {
+		eh = &ExporterHealth{ErrorCounts: make(map[string]int)}
+		h.health[name] = eh
+	}
+	return eh
+}
@@ -13,23 +13,27 @@ import (
 // NodeExporter implements the Exporter interface for Prometheus node_exporter
 type NodeExporter struct {
 	endpoint string
+	url      string // Request URL; differs from endpoint for Unix socket endpoints
 	client   *http.Client
+	auth     requestAuth
 }
 
 // NewNodeExporter creates a new node_exporter scraper
-func NewNodeExporter(endpoint string, timeout time.Duration) *NodeExporter {
+func NewNodeExporter(endpoint string, timeout time.Duration, opts ...Option) *NodeExporter {
 	if endpoint == "" {
 		endpoint = "http://localhost:9100/metrics"
 	}
 	if timeout == 0 {
 		timeout = 3 * time.Second
 	}
+	requestURL, socketPath := resolveEndpoint(endpoint)
+	o := newClientOptions(opts)
 
 	return &NodeExporter{
 		endpoint: endpoint,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		url:      requestURL,
+		client:   newHTTPClient(timeout, socketPath, o),
+		auth:     o.auth,
 	}
 }
 
@@ -43,24 +47,25 @@ func (n *NodeExporter) Name() string {
 func (n *NodeExporter) Scrape(ctx context.Context) ([]byte, error) {
 	logger.Debug("Scraping node_exporter", logger.String("endpoint", n.endpoint))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", n.endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", n.url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	n.auth.apply(req)
 
 	resp, err := n.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, newRequestError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+		return nil, newStatusError(resp.StatusCode)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, newReadError(err)
 	}
 
 	logger.Debug("Successfully scraped node_exporter",
@@ -12,14 +12,16 @@ import (
 type ProcessExporter struct {
 	name     string
 	endpoint string
+	url      string // Request URL; differs from endpoint for Unix socket endpoints
 	timeout  time.Duration
 	client   *http.Client
+	auth     requestAuth
 }
 
 var _ Exporter = (*ProcessExporter)(nil)
 
 // NewProcessExporter creates a new ProcessExporter instance
-func NewProcessExporter(endpoint string, timeout time.Duration) *ProcessExporter {
+func NewProcessExporter(endpoint string, timeout time.Duration, opts ...Option) *ProcessExporter {
 	// Use defaults if not specified
 	if endpoint == "" {
 		endpoint = "http://127.0.0.1:9256/metrics"
@@ -27,14 +29,16 @@ func NewProcessExporter(endpoint string, timeout time.Duration) *ProcessExporter
 	if timeout == 0 {
 		timeout = 3 * time.Second
 	}
+	requestURL, socketPath := resolveEndpoint(endpoint)
+	o := newClientOptions(opts)
 
 	return &ProcessExporter{
 		name:     "process_exporter",
 		endpoint: endpoint,
 		timeout:  timeout,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		url:      requestURL,
+		client:   newHTTPClient(timeout, socketPath, o),
+		auth:     o.auth,
 	}
 }
 
@@ -50,24 +54,25 @@ func (e *ProcessExporter) Endpoint() string {
 
 // Scrape fetches metrics from process_exporter
 func (e *ProcessExporter) Scrape(ctx context.Context) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", e.endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", e.url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	e.auth.apply(req)
 
 	resp, err := e.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, newRequestError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newStatusError(resp.StatusCode)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, newReadError(err)
 	}
 
 	return data, nil
@@ -15,8 +15,32 @@ const (
 	DefaultBufferPath      = "/var/lib/nodepulse/buffer"
 	DefaultConfigDir       = "/etc/nodepulse"
 	DefaultStateDir        = "/var/lib/nodepulse"
+
+	DefaultLogFilePath     = "/var/log/nodepulse/agent.log"
+
+	DefaultNodeExporterEndpoint = "http://localhost:9100/metrics"
 )
 
+// ConfigPath returns the config file path for the current instance (see config.InstancePath)
+func ConfigPath() string {
+	return config.InstancePath(DefaultConfigPath)
+}
+
+// ServerIDPath returns the server_id path for the current instance
+func ServerIDPath() string {
+	return config.InstancePath(DefaultServerIDPath)
+}
+
+// BufferPath returns the default buffer directory for the current instance
+func BufferPath() string {
+	return config.InstancePath(DefaultBufferPath)
+}
+
+// LogFilePath returns the default log file path for the current instance
+func LogFilePath() string {
+	return config.InstancePath(DefaultLogFilePath)
+}
+
 // InstallConfig holds the configuration for installation
 type InstallConfig struct {
 	Endpoint string // Required: metrics endpoint URL
@@ -29,6 +53,13 @@ type ConfigOptions struct {
 	Endpoint string
 	Timeout  string
 
+	// Auth options (AuthType "none" or empty disables auth)
+	AuthType       string
+	AuthToken      string
+	AuthTokenEnv   string
+	AuthTokenFile  string
+	AuthHeaderName string
+
 	// Agent options
 	ServerID string
 	Interval string
@@ -90,21 +121,21 @@ func checkWritable(dir string) error {
 // DetectExisting checks for existing installation
 func DetectExisting() (*ExistingInstall, error) {
 	existing := &ExistingInstall{
-		ConfigPath: DefaultConfigPath,
+		ConfigPath: ConfigPath(),
 	}
 
 	// Check for existing config
-	if _, err := os.Stat(DefaultConfigPath); err == nil {
+	if _, err := os.Stat(existing.ConfigPath); err == nil {
 		existing.HasConfig = true
 
 		// Try to read the endpoint from existing config
-		if cfg, err := config.Load(DefaultConfigPath); err == nil {
+		if cfg, err := config.Load(existing.ConfigPath); err == nil {
 			existing.Endpoint = cfg.Server.Endpoint
 		}
 	}
 
 	// Check for existing server_id
-	serverIDPath := config.GetServerIDPath()
+	serverIDPath := ServerIDPath()
 	if data, err := os.ReadFile(serverIDPath); err == nil {
 		existing.HasServerID = true
 		existing.ServerID = string(data)
@@ -116,9 +147,9 @@ func DetectExisting() (*ExistingInstall, error) {
 // CreateDirectories creates necessary directories
 func CreateDirectories() error {
 	dirs := []string{
-		DefaultConfigDir,
-		DefaultStateDir,
-		DefaultBufferPath,
+		filepath.Dir(ConfigPath()),
+		filepath.Dir(ServerIDPath()),
+		BufferPath(),
 	}
 
 	for _, dir := range dirs {
@@ -143,6 +174,37 @@ func CreateDirectories() error {
 	return nil
 }
 
+// PrepareLogDirectory creates the log directory for logFilePath and verifies it is writable
+// Surfaces permission problems at setup time instead of silently falling back to stderr at runtime
+func PrepareLogDirectory(logFilePath string) error {
+	if logFilePath == "" {
+		return fmt.Errorf("log file path is empty")
+	}
+
+	logDir := filepath.Dir(logFilePath)
+	info, err := os.Stat(logDir)
+	if err == nil && !info.IsDir() {
+		return fmt.Errorf("log directory %s exists but is not a directory", logDir)
+	}
+
+	// Only set permissions on a directory created here: an existing one may be shared
+	// (e.g. /tmp or /var/log) and keeps its mode
+	if err != nil {
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			return fmt.Errorf("failed to create log directory %s: %w", logDir, err)
+		}
+		if err := os.Chmod(logDir, 0755); err != nil {
+			return fmt.Errorf("failed to set permissions on log directory %s: %w", logDir, err)
+		}
+	}
+
+	if err := checkWritable(logDir); err != nil {
+		return fmt.Errorf("log directory %s is not writable: %w", logDir, err)
+	}
+
+	return nil
+}
+
 // HandleServerID validates custom ID or generates UUID
 func HandleServerID(customID string) (string, error) {
 	// If empty, generate UUID
@@ -215,7 +277,7 @@ func DefaultConfigOptions() ConfigOptions {
 		// Logging defaults
 		LogLevel:      "info",
 		LogOutput:     "stdout",
-		LogFilePath:   "/var/log/nodepulse/agent.log",
+		LogFilePath:   DefaultLogFilePath,
 		LogMaxSizeMB:  10,
 		LogMaxBackups: 3,
 		LogMaxAgeDays: 7,
@@ -223,18 +285,84 @@ func DefaultConfigOptions() ConfigOptions {
 	}
 }
 
+// ConfigOptionsFromConfig converts a loaded config back into the options used to write it
+// Inverse of WriteConfigFile for the settings that setup manages
+func ConfigOptionsFromConfig(cfg *config.Config) ConfigOptions {
+	return ConfigOptions{
+		// Server options
+		Endpoint: cfg.Server.Endpoint,
+		Timeout:  cfg.Server.Timeout.String(),
+
+		// Auth options
+		AuthType:       cfg.Server.Auth.Type,
+		AuthToken:      cfg.Server.Auth.Token,
+		AuthTokenEnv:   cfg.Server.Auth.TokenEnv,
+		AuthTokenFile:  cfg.Server.Auth.TokenFile,
+		AuthHeaderName: cfg.Server.Auth.HeaderName,
+
+		// Agent options
+		ServerID: cfg.Agent.ServerID,
+		Interval: cfg.Agent.Interval.String(),
+
+		// Buffer options
+		BufferPath:           cfg.Buffer.Path,
+		BufferRetentionHours: cfg.Buffer.RetentionHours,
+		BufferBatchSize:      cfg.Buffer.BatchSize,
+
+		// Logging options
+		LogLevel:      cfg.Logging.Level,
+		LogOutput:     cfg.Logging.Output,
+		LogFilePath:   cfg.Logging.File.Path,
+		LogMaxSizeMB:  cfg.Logging.File.MaxSizeMB,
+		LogMaxBackups: cfg.Logging.File.MaxBackups,
+		LogMaxAgeDays: cfg.Logging.File.MaxAgeDays,
+		LogCompress:   cfg.Logging.File.Compress,
+	}
+}
+
 // WriteConfigFile writes the configuration file
 func WriteConfigFile(opts ConfigOptions) error {
+	serverData := map[string]interface{}{
+		"endpoint": opts.Endpoint,
+		"timeout":  opts.Timeout,
+	}
+
+	// Only write auth block when auth is enabled
+	if opts.AuthType != "" && opts.AuthType != "none" {
+		authData := map[string]interface{}{
+			"type": opts.AuthType,
+		}
+		if opts.AuthToken != "" {
+			authData["token"] = opts.AuthToken
+		}
+		if opts.AuthTokenEnv != "" {
+			authData["token_env"] = opts.AuthTokenEnv
+		}
+		if opts.AuthTokenFile != "" {
+			authData["token_file"] = opts.AuthTokenFile
+		}
+		if opts.AuthHeaderName != "" {
+			authData["header_name"] = opts.AuthHeaderName
+		}
+		serverData["auth"] = authData
+	}
+
 	// Create config structure
 	configData := map[string]interface{}{
-		"server": map[string]interface{}{
-			"endpoint": opts.Endpoint,
-			"timeout":  opts.Timeout,
-		},
+		"server": serverData,
 		"agent": map[string]interface{}{
 			"server_id": opts.ServerID,
 			"interval":  opts.Interval,
 		},
+		// The agent needs at least one exporter to start; setup configures the local node_exporter
+		"exporters": []map[string]interface{}{
+			{
+				"name":     "node_exporter",
+				"enabled":  true,
+				"endpoint": DefaultNodeExporterEndpoint,
+				"timeout":  "3s",
+			},
+		},
 		"buffer": map[string]interface{}{
 			"path":            opts.BufferPath,
 			"retention_hours": opts.BufferRetentionHours,
@@ -260,7 +388,7 @@ func WriteConfigFile(opts ConfigOptions) error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(DefaultConfigPath, data, 0644); err != nil {
+	if err := os.WriteFile(ConfigPath(), data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -269,7 +397,7 @@ func WriteConfigFile(opts ConfigOptions) error {
 
 // PersistServerID saves server ID to file
 func PersistServerID(serverID string) error {
-	serverIDPath := DefaultServerIDPath
+	serverIDPath := ServerIDPath()
 
 	// Create directory if needed
 	dir := filepath.Dir(serverIDPath)
@@ -288,7 +416,7 @@ func PersistServerID(serverID string) error {
 // ValidateInstallation validates the installation
 func ValidateInstallation() error {
 	// Load config using existing config loader
-	cfg, err := config.Load(DefaultConfigPath)
+	cfg, err := config.Load(ConfigPath())
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -310,9 +438,9 @@ func ValidateInstallation() error {
 func FixPermissions() error {
 	// Fix directory permissions
 	dirs := map[string]os.FileMode{
-		DefaultConfigDir:  0755,
-		DefaultStateDir:   0755,
-		DefaultBufferPath: 0755,
+		filepath.Dir(ConfigPath()):   0755,
+		filepath.Dir(ServerIDPath()): 0755,
+		BufferPath():                 0755,
 	}
 
 	for dir, mode := range dirs {
@@ -325,8 +453,8 @@ func FixPermissions() error {
 
 	// Fix file permissions
 	files := map[string]os.FileMode{
-		DefaultConfigPath:   0644,
-		DefaultServerIDPath: 0600,
+		ConfigPath():   0644,
+		ServerIDPath(): 0600,
 	}
 
 	for file, mode := range files {
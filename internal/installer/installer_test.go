@@ -0,0 +1,117 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+func TestInstancePaths(t *testing.T) {
+	t.Cleanup(func() { config.SetInstance("") })
+
+	if ConfigPath() != DefaultConfigPath || ServerIDPath() != DefaultServerIDPath {
+		t.Errorf("Expected the default instance to use the default paths, got %s and %s", ConfigPath(), ServerIDPath())
+	}
+
+	config.SetInstance("db")
+	tests := map[string]string{
+		ConfigPath():   "/etc/nodepulse/db/nodepulse.yml",
+		ServerIDPath(): "/var/lib/nodepulse/db/server_id",
+		BufferPath():   "/var/lib/nodepulse/db/buffer",
+		LogFilePath():  "/var/log/nodepulse/db/agent.log",
+	}
+	for got, want := range tests {
+		if got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestPrepareLogDirectory(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "log", "nodepulse", "agent.log")
+
+	if err := PrepareLogDirectory(logPath); err != nil {
+		t.Fatalf("PrepareLogDirectory failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Dir(logPath))
+	if err != nil {
+		t.Fatalf("Log directory was not created: %v", err)
+	}
+	if !info.IsDir() {
+		t.Fatal("Log directory path is not a directory")
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Log directory permissions = %o, want 755", info.Mode().Perm())
+	}
+
+	// Write test file must be cleaned up
+	if _, err := os.Stat(filepath.Join(filepath.Dir(logPath), ".pulse-write-test")); !os.IsNotExist(err) {
+		t.Error("Write test file should be removed")
+	}
+}
+
+func TestPrepareLogDirectory_KeepsExistingMode(t *testing.T) {
+	// A shared directory such as /tmp must keep its sticky bit and world-write permission
+	dir := filepath.Join(t.TempDir(), "tmp")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.Chmod(dir, 0777|os.ModeSticky); err != nil {
+		t.Fatalf("Failed to set directory mode: %v", err)
+	}
+
+	if err := PrepareLogDirectory(filepath.Join(dir, "agent.log")); err != nil {
+		t.Fatalf("PrepareLogDirectory failed: %v", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode()&(os.ModePerm|os.ModeSticky) != 0777|os.ModeSticky {
+		t.Errorf("Existing directory mode = %v, want drwxrwxrwt", info.Mode())
+	}
+}
+
+func TestPrepareLogDirectory_NotADirectory(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "nodepulse")
+	if err := os.WriteFile(blocker, []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("Failed to create blocking file: %v", err)
+	}
+
+	err := PrepareLogDirectory(filepath.Join(blocker, "agent.log"))
+	if err == nil {
+		t.Fatal("Expected error when log directory path is a file")
+	}
+	if !strings.Contains(err.Error(), "is not a directory") {
+		t.Errorf("Expected clear 'not a directory' error, got: %v", err)
+	}
+}
+
+func TestPrepareLogDirectory_CannotCreate(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create blocking file: %v", err)
+	}
+
+	// Parent of the log directory is a regular file, so MkdirAll must fail
+	err := PrepareLogDirectory(filepath.Join(blocker, "log", "agent.log"))
+	if err == nil {
+		t.Fatal("Expected error when log directory cannot be created")
+	}
+	if !strings.Contains(err.Error(), "failed to create log directory") {
+		t.Errorf("Expected clear creation error, got: %v", err)
+	}
+}
+
+func TestPrepareLogDirectory_EmptyPath(t *testing.T) {
+	if err := PrepareLogDirectory(""); err == nil {
+		t.Fatal("Expected error for empty log path")
+	}
+}
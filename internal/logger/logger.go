@@ -1,9 +1,12 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -12,9 +15,18 @@ import (
 
 // Config holds the logging configuration
 type Config struct {
-	Level  string     `mapstructure:"level"`
-	Output string     `mapstructure:"output"`
-	File   FileConfig `mapstructure:"file"`
+	Level    string         `mapstructure:"level"`
+	Output   string         `mapstructure:"output"`
+	File     FileConfig     `mapstructure:"file"`
+	Sampling SamplingConfig `mapstructure:"sampling"`
+}
+
+// SamplingConfig limits repeated debug messages: per message and second, the first Initial
+// are logged, then every Thereafter-th. Info and above are never sampled
+// Initial 0 disables sampling
+type SamplingConfig struct {
+	Initial    int `mapstructure:"initial"`
+	Thereafter int `mapstructure:"thereafter"`
 }
 
 // FileConfig holds file-specific logging configuration
@@ -77,22 +89,22 @@ func Initialize(cfg Config) error {
 
 	switch cfg.Output {
 	case "stdout", "console":
-		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), level))
+		cores = append(cores, newCore(encoder, zapcore.AddSync(os.Stdout), level, cfg.Sampling))
 
 	case "file":
 		fileWriter, err := createFileWriter(cfg.File)
 		if err != nil {
 			// For file-only mode, fall back to stderr with a warning
 			fmt.Fprintf(os.Stderr, "WARNING: Failed to create log file, falling back to stderr: %v\n", err)
-			cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(os.Stderr), level))
+			cores = append(cores, newCore(encoder, zapcore.AddSync(os.Stderr), level, cfg.Sampling))
 			fileWriterFailed = true
 		} else {
-			cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(fileWriter), level))
+			cores = append(cores, newCore(encoder, zapcore.AddSync(fileWriter), level, cfg.Sampling))
 		}
 
 	case "both":
 		// Console output (always add this first)
-		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), level))
+		cores = append(cores, newCore(encoder, zapcore.AddSync(os.Stdout), level, cfg.Sampling))
 
 		// File output (attempt, but don't fail if it doesn't work)
 		fileWriter, err := createFileWriter(cfg.File)
@@ -101,7 +113,7 @@ func Initialize(cfg Config) error {
 			fmt.Fprintf(os.Stderr, "WARNING: Failed to create log file, using stdout only: %v\n", err)
 			fileWriterFailed = true
 		} else {
-			cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(fileWriter), level))
+			cores = append(cores, newCore(encoder, zapcore.AddSync(fileWriter), level, cfg.Sampling))
 		}
 
 	default:
@@ -124,14 +136,45 @@ func Initialize(cfg Config) error {
 	return nil
 }
 
+// newCore creates the core for one output, sampling debug messages if configured
+func newCore(encoder zapcore.Encoder, ws zapcore.WriteSyncer, level zapcore.Level, sampling SamplingConfig) zapcore.Core {
+	if sampling.Initial <= 0 || level > zapcore.DebugLevel {
+		return zapcore.NewCore(encoder, ws, level)
+	}
+
+	// Only debug messages go through the sampler, so warnings and errors are never dropped
+	debug := zapcore.NewCore(encoder, ws, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l == zapcore.DebugLevel
+	}))
+	rest := zapcore.NewCore(encoder, ws, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l > zapcore.DebugLevel
+	}))
+	return zapcore.NewTee(
+		zapcore.NewSamplerWithOptions(debug, time.Second, sampling.Initial, sampling.Thereafter),
+		rest,
+	)
+}
+
 // createFileWriter creates a lumberjack writer for log rotation
 func createFileWriter(cfg FileConfig) (*lumberjack.Logger, error) {
 	// Ensure directory exists
 	logDir := filepath.Dir(cfg.Path)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
+		if errors.Is(err, syscall.EROFS) {
+			return nil, fmt.Errorf("log directory %s is on a read-only filesystem (check 'dmesg' and remount read-write, or set logging.output to stdout): %w", logDir, err)
+		}
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
+	// Lumberjack opens the file lazily, so probe it now to surface a read-only filesystem at startup
+	if f, err := os.OpenFile(cfg.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+		if errors.Is(err, syscall.EROFS) {
+			return nil, fmt.Errorf("log file %s is on a read-only filesystem (check 'dmesg' and remount read-write, or set logging.output to stdout): %w", cfg.Path, err)
+		}
+	} else {
+		f.Close()
+	}
+
 	return &lumberjack.Logger{
 		Filename:   cfg.Path,
 		MaxSize:    cfg.MaxSizeMB,
@@ -151,6 +194,13 @@ func validateConfig(cfg Config) error {
 		return fmt.Errorf("output must be 'stdout', 'file', or 'both', got: %s", cfg.Output)
 	}
 
+	if cfg.Sampling.Initial < 0 {
+		return fmt.Errorf("sampling.initial cannot be negative, got: %d", cfg.Sampling.Initial)
+	}
+	if cfg.Sampling.Thereafter < 0 {
+		return fmt.Errorf("sampling.thereafter cannot be negative, got: %d", cfg.Sampling.Thereafter)
+	}
+
 	// Validate file config if file output is used
 	if cfg.Output == "file" || cfg.Output == "both" {
 		if cfg.File.Path == "" {
@@ -1,10 +1,13 @@
 package logger
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -464,3 +467,40 @@ func TestSugaredLogger(t *testing.T) {
 	Warnf("warn message: %v", true)
 	Errorf("error message: %f", 3.14)
 }
+
+func TestNewCoreSampling(t *testing.T) {
+	encoder := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	sampling := SamplingConfig{Initial: 3, Thereafter: 100}
+
+	tests := []struct {
+		name      string
+		level     zapcore.Level
+		sampling  SamplingConfig
+		wantDebug int
+	}{
+		{"sampled at debug", zapcore.DebugLevel, sampling, 3},
+		{"sampling disabled", zapcore.DebugLevel, SamplingConfig{}, 50},
+		{"no-op at info", zapcore.InfoLevel, sampling, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := zap.New(newCore(encoder, zapcore.AddSync(&buf), tt.level, tt.sampling))
+
+			for i := 0; i < 50; i++ {
+				log.Debug("Waiting random delay")
+				log.Error("Failed to send batch")
+			}
+
+			output := buf.String()
+			if got := strings.Count(output, "Waiting random delay"); got != tt.wantDebug {
+				t.Errorf("Expected %d debug lines, got %d", tt.wantDebug, got)
+			}
+			// Errors are never sampled
+			if got := strings.Count(output, "Failed to send batch"); got != 50 {
+				t.Errorf("Expected 50 error lines, got %d", got)
+			}
+		})
+	}
+}
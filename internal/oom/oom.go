@@ -0,0 +1,222 @@
+package oom
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/node-pulse/agent/internal/logger"
+	"golang.org/x/sys/unix"
+)
+
+// Sources for OOM kill detection (anything else is a log file path, e.g. /var/log/kern.log)
+const (
+	SourceKmsg = "kmsg" // The kernel ring buffer via /dev/kmsg (what dmesg reads)
+	SourceNone = "none" // Detection disabled
+)
+
+// MetricName is the synthetic metric appended to node_exporter scrapes, one line per kill
+const MetricName = "nodepulse_oom_kill"
+
+const kmsgPath = "/dev/kmsg"
+
+// killedProcessRe matches the kernel's OOM kill line, from the global OOM killer
+// ("Out of memory: Killed process 1234 (java) ...") and memory cgroups
+// ("Memory cgroup out of memory: Killed process 1234 (java) ...")
+// Kernels before 4.19 log "Out of memory: Kill process ..." first and then "Killed process ...",
+// so only the latter is matched to count each kill once
+var killedProcessRe = regexp.MustCompile(`Killed process (\d+) \((.*?)\)`)
+
+// Kill is a process killed by the OOM killer
+type Kill struct {
+	PID     int
+	Process string // Command name (comm), at most 15 characters
+}
+
+// ParseKill extracts the victim from a kernel log line, if it's an OOM kill
+func ParseKill(line string) (Kill, bool) {
+	m := killedProcessRe.FindStringSubmatch(line)
+	if m == nil {
+		return Kill{}, false
+	}
+	pid, err := strconv.Atoi(m[1])
+	if err != nil {
+		return Kill{}, false
+	}
+	return Kill{PID: pid, Process: m[2]}, true
+}
+
+// Watcher reports OOM kills logged since the previous Collect
+// Kills logged before the watcher was created are not reported, so restarting
+// the agent doesn't report the same kills again
+type Watcher struct {
+	source string
+	kmsgFd int      // /dev/kmsg file descriptor (SourceKmsg)
+	file   *os.File // Log file (path sources)
+	offset int64    // Read position in the log file
+}
+
+// NewWatcher opens the source and skips to its end
+// Returns nil (and no error) when the source is SourceNone
+func NewWatcher(source string) (*Watcher, error) {
+	switch source {
+	case SourceNone, "":
+		return nil, nil
+	case SourceKmsg:
+		// Non-blocking raw reads: the Go runtime poller would make reads wait for new messages
+		fd, err := unix.Open(kmsgPath, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+		if err != nil {
+			if errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES) {
+				return nil, fmt.Errorf("no permission to read %s (needs root or CAP_SYSLOG when kernel.dmesg_restrict=1): %w", kmsgPath, err)
+			}
+			return nil, fmt.Errorf("failed to open %s: %w", kmsgPath, err)
+		}
+		if _, err := unix.Seek(fd, 0, io.SeekEnd); err != nil {
+			unix.Close(fd)
+			return nil, fmt.Errorf("failed to seek %s: %w", kmsgPath, err)
+		}
+		return &Watcher{source: source, kmsgFd: fd}, nil
+	default:
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", source, err)
+		}
+		offset, err := file.Seek(0, io.SeekEnd)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to seek %s: %w", source, err)
+		}
+		return &Watcher{source: source, kmsgFd: -1, file: file, offset: offset}, nil
+	}
+}
+
+// Source returns the watched source (SourceKmsg or a file path)
+func (w *Watcher) Source() string {
+	return w.source
+}
+
+// Collect returns the OOM kills logged since the previous call
+func (w *Watcher) Collect() ([]Kill, error) {
+	if w.file != nil {
+		return w.collectFile()
+	}
+	return w.collectKmsg()
+}
+
+// collectKmsg reads new /dev/kmsg records until none are left
+// Each read returns exactly one record: "prio,seq,usec,flags;message\n" followed by
+// optional " KEY=value" continuation lines
+func (w *Watcher) collectKmsg() ([]Kill, error) {
+	var kills []Kill
+	buf := make([]byte, 8192)
+
+	for {
+		n, err := unix.Read(w.kmsgFd, buf)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) {
+				return kills, nil
+			}
+			// Records were overwritten before we read them, the next read resumes at the oldest one
+			if errors.Is(err, unix.EPIPE) {
+				logger.Debug("Kernel log records were overwritten before they were read", logger.String("source", kmsgPath))
+				continue
+			}
+			return kills, fmt.Errorf("failed to read %s: %w", kmsgPath, err)
+		}
+		if n == 0 {
+			return kills, nil
+		}
+
+		record := buf[:n]
+		if i := bytes.IndexByte(record, ';'); i != -1 {
+			record = record[i+1:]
+		}
+		if i := bytes.IndexByte(record, '\n'); i != -1 {
+			record = record[:i]
+		}
+		if kill, ok := ParseKill(string(record)); ok {
+			kills = append(kills, kill)
+		}
+	}
+}
+
+// collectFile reads lines appended to the log file since the previous call
+// A file smaller than the last read position was truncated or rotated, and is read from the start
+func (w *Watcher) collectFile() ([]Kill, error) {
+	info, err := w.file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", w.source, err)
+	}
+
+	// Reopen after rotation by rename, so we follow the new file at the same path
+	if current, err := os.Stat(w.source); err == nil && !os.SameFile(info, current) {
+		file, err := os.Open(w.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reopen %s: %w", w.source, err)
+		}
+		w.file.Close()
+		w.file = file
+		w.offset = 0
+		info = current
+	}
+
+	if info.Size() < w.offset {
+		w.offset = 0
+	}
+
+	if _, err := w.file.Seek(w.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek %s: %w", w.source, err)
+	}
+
+	var kills []Kill
+	reader := bufio.NewReader(w.file)
+	for {
+		line, err := reader.ReadString('\n')
+		// Leave a partially written last line for the next call
+		if err != nil {
+			if err == io.EOF {
+				return kills, nil
+			}
+			return kills, fmt.Errorf("failed to read %s: %w", w.source, err)
+		}
+		w.offset += int64(len(line))
+
+		if kill, ok := ParseKill(line); ok {
+			kills = append(kills, kill)
+		}
+	}
+}
+
+// Close releases the watched source
+func (w *Watcher) Close() error {
+	if w.file != nil {
+		return w.file.Close()
+	}
+	return unix.Close(w.kmsgFd)
+}
+
+// labelValueReplacer strips characters the node_exporter parser can't handle in label values
+// Process names are user-controlled (prctl PR_SET_NAME), so they can contain anything
+var labelValueReplacer = strings.NewReplacer(`"`, "_", `\`, "_", ",", "_", "=", "_", "\n", "_")
+
+// FormatMetrics renders kills in Prometheus text format, to be appended to a node_exporter scrape
+// Returns nil when there are no kills
+func FormatMetrics(kills []Kill) []byte {
+	if len(kills) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# HELP " + MetricName + " Process killed by the OOM killer since the previous scrape.\n")
+	buf.WriteString("# TYPE " + MetricName + " gauge\n")
+	for _, kill := range kills {
+		fmt.Fprintf(&buf, "%s{pid=\"%d\",process=\"%s\"} 1\n", MetricName, kill.PID, labelValueReplacer.Replace(kill.Process))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,148 @@
+package oom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKill(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Kill
+		ok   bool
+	}{
+		{
+			name: "global OOM killer",
+			line: "Out of memory: Killed process 1234 (java) total-vm:8388608kB, anon-rss:4194304kB, file-rss:0kB, shmem-rss:0kB, UID:1000 pgtables:8448kB oom_score_adj:0",
+			want: Kill{PID: 1234, Process: "java"},
+			ok:   true,
+		},
+		{
+			name: "memory cgroup",
+			line: "Memory cgroup out of memory: Killed process 98765 (postgres: wal) total-vm:1024kB, anon-rss:512kB",
+			want: Kill{PID: 98765, Process: "postgres: wal"},
+			ok:   true,
+		},
+		{
+			name: "kern.log with syslog prefix",
+			line: "Oct 15 03:48:36 web01 kernel: [123456.789012] Out of memory: Killed process 4321 (node) total-vm:2048kB",
+			want: Kill{PID: 4321, Process: "node"},
+			ok:   true,
+		},
+		{
+			name: "pre-4.19 kill announcement is not counted",
+			line: "Out of memory: Kill process 1234 (java) score 912 or sacrifice child",
+		},
+		{
+			name: "pre-4.19 kill confirmation",
+			line: "Killed process 1234 (java) total-vm:8388608kB, anon-rss:4194304kB, file-rss:0kB",
+			want: Kill{PID: 1234, Process: "java"},
+			ok:   true,
+		},
+		{
+			name: "unrelated line",
+			line: "EXT4-fs (sda1): mounted filesystem with ordered data mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseKill(tt.line)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParseKill() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWatcher_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kern.log")
+	appendLog := func(lines ...string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open log: %v", err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(strings.Join(lines, "")); err != nil {
+			t.Fatalf("Failed to write log: %v", err)
+		}
+	}
+
+	// Kills from before the watcher started are not reported
+	appendLog("kernel: Out of memory: Killed process 1 (old) total-vm:1kB\n")
+
+	watcher, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer watcher.Close()
+
+	if kills, err := watcher.Collect(); err != nil || len(kills) != 0 {
+		t.Fatalf("Expected no kills, got %+v (err: %v)", kills, err)
+	}
+
+	// A partially written line is picked up once it's complete
+	appendLog(
+		"kernel: Out of memory: Killed process 200 (java) total-vm:1kB\n",
+		"kernel: eth0: link up\n",
+		"kernel: Memory cgroup out of memory: Killed process 300 (py",
+	)
+	kills, err := watcher.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(kills) != 1 || kills[0] != (Kill{PID: 200, Process: "java"}) {
+		t.Fatalf("Expected java to be killed, got %+v", kills)
+	}
+
+	appendLog("thon3) total-vm:1kB\n")
+	kills, err = watcher.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(kills) != 1 || kills[0] != (Kill{PID: 300, Process: "python3"}) {
+		t.Fatalf("Expected python3 to be killed, got %+v", kills)
+	}
+
+	// After rotation the new file is read from the start
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Failed to rotate log: %v", err)
+	}
+	appendLog("kernel: Out of memory: Killed process 400 (redis-server) total-vm:1kB\n")
+	kills, err = watcher.Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(kills) != 1 || kills[0].Process != "redis-server" {
+		t.Fatalf("Expected redis-server to be killed after rotation, got %+v", kills)
+	}
+}
+
+func TestNewWatcher_None(t *testing.T) {
+	watcher, err := NewWatcher(SourceNone)
+	if watcher != nil || err != nil {
+		t.Errorf("Expected nil watcher and no error, got %v, %v", watcher, err)
+	}
+}
+
+func TestNewWatcher_MissingFile(t *testing.T) {
+	if _, err := NewWatcher(filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Error("Expected an error for a missing log file")
+	}
+}
+
+func TestFormatMetrics(t *testing.T) {
+	if FormatMetrics(nil) != nil {
+		t.Error("Expected no output without kills")
+	}
+
+	got := string(FormatMetrics([]Kill{{PID: 7, Process: `evil",name=x`}}))
+	want := `nodepulse_oom_kill{pid="7",process="evil__name_x"} 1` + "\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("Expected sanitized metric line %q, got:\n%s", want, got)
+	}
+}
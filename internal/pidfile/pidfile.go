@@ -7,14 +7,33 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+
+	"golang.org/x/sys/unix"
 )
 
 const (
 	daemonPidFile = ".nodepulse/nodepulse.pid"
 )
 
-// GetPidFilePath returns the PID file path based on user privileges
+// pathOverride replaces the default PID file path when set (see SetPath)
+var pathOverride string
+
+// SetPath overrides the PID file path used by every function in this package
+// An empty path restores the default
+func SetPath(path string) {
+	pathOverride = path
+}
+
+// GetPidFilePath returns the PID file path: the SetPath override, or DefaultPath
 func GetPidFilePath() string {
+	if pathOverride != "" {
+		return pathOverride
+	}
+	return DefaultPath()
+}
+
+// DefaultPath returns the default PID file path based on user privileges
+func DefaultPath() string {
 	if os.Geteuid() == 0 {
 		// Root: use /var/run
 		return "/var/run/nodepulse.pid"
@@ -77,6 +96,19 @@ func WritePidFile(pid int) error {
 	return nil
 }
 
+// CheckWritable creates the PID file directory if needed and checks that it's writable,
+// so a bad path is reported at startup with the path in the error
+func CheckWritable() error {
+	dir := filepath.Dir(GetPidFilePath())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create PID directory: %w", err)
+	}
+	if err := unix.Access(dir, unix.W_OK); err != nil {
+		return fmt.Errorf("PID file directory %s is not writable: %w", dir, err)
+	}
+	return nil
+}
+
 // RemovePidFile removes the PID file
 func RemovePidFile() error {
 	pidPath := GetPidFilePath()
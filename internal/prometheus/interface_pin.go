@@ -0,0 +1,63 @@
+package prometheus
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// InterfacePinner keeps the primary network interface fixed for the process lifetime
+// Without it the primary interface is chosen per snapshot, so on hosts where interfaces come and go
+// (e.g. failover bonding without a bond device) the aggregate network counters jump between interfaces
+type InterfacePinner struct {
+	mu      sync.Mutex // Batches may be parsed concurrently
+	device  string     // Primary interface of the first snapshot that had one
+	devices []string   // Interfaces seen in the previous snapshot, sorted
+}
+
+// NewInterfacePinner creates a pinner that pins the primary interface of the first snapshot
+func NewInterfacePinner() *InterfacePinner {
+	return &InterfacePinner{}
+}
+
+// Apply replaces the snapshot's aggregate network counters with those of the pinned interface
+// If the pinned interface is missing from a snapshot, that snapshot keeps its own primary interface
+func (p *InterfacePinner) Apply(snapshot *NodeExporterMetricSnapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	devices := make([]string, 0, len(snapshot.NetworkInterfaces))
+	for _, iface := range snapshot.NetworkInterfaces {
+		devices = append(devices, iface.Device)
+	}
+	sort.Strings(devices)
+
+	if p.device == "" {
+		if snapshot.NetworkPrimaryInterface != "" {
+			p.device = snapshot.NetworkPrimaryInterface
+			p.devices = devices
+			logger.Info("Pinned primary network interface", logger.String("device", p.device))
+		}
+		return
+	}
+
+	if strings.Join(devices, ",") != strings.Join(p.devices, ",") {
+		logger.Info("Network interfaces changed, keeping the pinned primary interface",
+			logger.String("pinned", p.device),
+			logger.String("previous", strings.Join(p.devices, ",")),
+			logger.String("current", strings.Join(devices, ",")))
+		p.devices = devices
+	}
+
+	for _, iface := range snapshot.NetworkInterfaces {
+		if iface.Device == p.device {
+			setPrimaryNetwork(snapshot, iface)
+			return
+		}
+	}
+	logger.Debug("Pinned primary network interface missing from snapshot",
+		logger.String("pinned", p.device),
+		logger.String("using", snapshot.NetworkPrimaryInterface))
+}
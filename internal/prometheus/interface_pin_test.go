@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// networkScrape builds node_exporter output with rx bytes per interface
+func networkScrape(rxBytes map[string]int) []byte {
+	var sb strings.Builder
+	for device, rx := range rxBytes {
+		fmt.Fprintf(&sb, "node_network_receive_bytes_total{device=%q} %d\n", device, rx)
+	}
+	return []byte(sb.String())
+}
+
+func TestInterfacePinner_KeepsFirstPrimary(t *testing.T) {
+	pinner := NewInterfacePinner()
+
+	// First scrape: no eth0/en0, so the first interface by name is chosen and pinned
+	first, err := ParseNodeExporterMetrics(networkScrape(map[string]int{"ens6": 600, "ens5": 500}))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	pinner.Apply(first)
+	if first.NetworkPrimaryInterface != "ens5" || first.NetworkReceiveBytesTotal != 500 {
+		t.Fatalf("Expected ens5 (500 bytes) as primary, got %s (%d)", first.NetworkPrimaryInterface, first.NetworkReceiveBytesTotal)
+	}
+
+	// eth0 appears later: the parser alone would switch to it, the pinner keeps ens5
+	second, err := ParseNodeExporterMetrics(networkScrape(map[string]int{"eth0": 9000, "ens5": 510, "ens6": 610}))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if second.NetworkPrimaryInterface != "eth0" {
+		t.Fatalf("Expected the parser to choose eth0, got %s", second.NetworkPrimaryInterface)
+	}
+	pinner.Apply(second)
+	if second.NetworkPrimaryInterface != "ens5" || second.NetworkReceiveBytesTotal != 510 {
+		t.Errorf("Expected pinned ens5 (510 bytes), got %s (%d)", second.NetworkPrimaryInterface, second.NetworkReceiveBytesTotal)
+	}
+
+	// Pinned interface missing: the snapshot keeps its own primary, and the pin survives
+	third, _ := ParseNodeExporterMetrics(networkScrape(map[string]int{"eth0": 9100}))
+	pinner.Apply(third)
+	if third.NetworkPrimaryInterface != "eth0" || third.NetworkReceiveBytesTotal != 9100 {
+		t.Errorf("Expected eth0 (9100 bytes) while ens5 is missing, got %s (%d)", third.NetworkPrimaryInterface, third.NetworkReceiveBytesTotal)
+	}
+
+	fourth, _ := ParseNodeExporterMetrics(networkScrape(map[string]int{"eth0": 9200, "ens5": 520}))
+	pinner.Apply(fourth)
+	if fourth.NetworkPrimaryInterface != "ens5" || fourth.NetworkReceiveBytesTotal != 520 {
+		t.Errorf("Expected pinned ens5 (520 bytes) once it's back, got %s (%d)", fourth.NetworkPrimaryInterface, fourth.NetworkReceiveBytesTotal)
+	}
+}
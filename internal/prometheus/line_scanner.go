@@ -0,0 +1,52 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// DefaultMaxLineBytes is the longest line accepted in exporter output unless configured
+// (metrics.max_line_bytes, see DropLongLines)
+const DefaultMaxLineBytes = 1 << 20
+
+// newLineScanner returns a line scanner over data that accepts lines as long as data itself
+// The input is already in memory, so there's nothing to bound here: long lines are dropped
+// once at scrape time (DropLongLines), and a too-long line would otherwise end the scan
+// and silently lose the rest of the input
+func newLineScanner(data []byte) *bufio.Scanner {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, min(64*1024, len(data)+1)), len(data)+1)
+	return scanner
+}
+
+// DropLongLines returns data without the lines longer than maxLineBytes (<= 0 = DefaultMaxLineBytes)
+// and the number of lines dropped. Other lines are kept as they are and in order
+// data is returned as is when no line is dropped
+func DropLongLines(data []byte, maxLineBytes int) ([]byte, int) {
+	if maxLineBytes <= 0 {
+		maxLineBytes = DefaultMaxLineBytes
+	}
+	if len(data) <= maxLineBytes {
+		return data, 0
+	}
+
+	result := make([]byte, 0, len(data))
+	dropped := 0
+	for rest := data; len(rest) > 0; {
+		line, next, found := bytes.Cut(rest, []byte("\n"))
+		if len(line) > maxLineBytes {
+			dropped++
+		} else {
+			result = append(result, line...)
+			if found {
+				result = append(result, '\n')
+			}
+		}
+		rest = next
+	}
+
+	if dropped == 0 {
+		return data, 0
+	}
+	return result, dropped
+}
@@ -0,0 +1,58 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDropLongLines(t *testing.T) {
+	long := `node_info{value="` + strings.Repeat("x", 2048) + `"} 1`
+
+	tests := []struct {
+		name        string
+		input       string
+		want        string
+		wantDropped int
+	}{
+		{"short scrape", "node_load1 0.5\n", "node_load1 0.5\n", 0},
+		{"long line in the middle", "node_load1 0.5\n" + long + "\nnode_load5 0.25\n", "node_load1 0.5\nnode_load5 0.25\n", 1},
+		{"long last line without newline", "node_load1 0.5\n" + long, "node_load1 0.5\n", 1},
+		{"blank lines kept", "# TYPE node_load1 gauge\n\n" + long + "\n" + long + "\nnode_load1 0.5\n", "# TYPE node_load1 gauge\n\nnode_load1 0.5\n", 2},
+		{"no long line", "node_load1 0.5\n" + strings.Repeat("node_load5 0.25\n", 200), "node_load1 0.5\n" + strings.Repeat("node_load5 0.25\n", 200), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := DropLongLines([]byte(tt.input), 1024)
+			if string(got) != tt.want || dropped != tt.wantDropped {
+				t.Errorf("DropLongLines() = %q, %d, want %q, %d", got, dropped, tt.want, tt.wantDropped)
+			}
+		})
+	}
+
+	// A non-positive limit selects the default
+	if _, dropped := DropLongLines([]byte(long+"\n"+strings.Repeat("x", DefaultMaxLineBytes+1)+"\n"), 0); dropped != 1 {
+		t.Errorf("Expected only the line over DefaultMaxLineBytes to be dropped, got %d", dropped)
+	}
+}
+
+func TestParsers_LongLines(t *testing.T) {
+	// Longer than bufio.Scanner's default 64KB, which would end the scan early
+	long := `node_info{value="` + strings.Repeat("x", 100*1024) + `"} 1`
+	input := []byte("node_load1 0.5\n" + long + "\nnode_load5 0.25\n")
+
+	snapshot, err := ParseNodeExporterMetrics(input)
+	if err != nil || snapshot.Load5Min != 0.25 {
+		t.Errorf("ParseNodeExporterMetrics: expected the lines after the long one to be parsed, got %v", err)
+	}
+	if _, err := ParseProcessExporterMetrics(input); err != nil {
+		t.Errorf("ParseProcessExporterMetrics failed: %v", err)
+	}
+	if err := ValidateText(input); err != nil {
+		t.Errorf("ValidateText failed: %v", err)
+	}
+	if data, err := AddTimestamps(input, time.Unix(1730102400, 0), TimestampOptions{}); err != nil || !strings.HasSuffix(string(data), "node_load5 0.25 1730102400000\n") {
+		t.Errorf("AddTimestamps: expected the lines after the long one to be kept, got %v", err)
+	}
+}
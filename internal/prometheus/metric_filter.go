@@ -0,0 +1,122 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MetricFilter keeps or drops metric families of a scrape by name
+// Patterns are compiled from the exporter's metric_filter (see config.CompileMetricPatterns)
+type MetricFilter struct {
+	include []*regexp.Regexp // Empty = every family not excluded
+	exclude []*regexp.Regexp
+}
+
+// NewMetricFilter creates a filter from include/exclude patterns. A family is kept if it
+// matches an include pattern (or there are none) and no exclude pattern. Returns nil if both
+// are empty
+func NewMetricFilter(include, exclude []*regexp.Regexp) *MetricFilter {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	return &MetricFilter{include: include, exclude: exclude}
+}
+
+// Keep reports whether a metric family is kept
+func (f *MetricFilter) Keep(family string) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(family) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(family) {
+			return true
+		}
+	}
+	return false
+}
+
+// familySuffixes are appended to a family name in the sample names of histograms, summaries,
+// and OpenMetrics counters
+var familySuffixes = []string{"_bucket", "_sum", "_count", "_created", "_total", "_info"}
+
+// Apply returns the scrape with the lines of dropped families removed. # HELP and # TYPE lines
+// are kept only for kept families, other lines are kept as they are and in order. Samples
+// belong to the family of the preceding # HELP/# TYPE line when named after it (e.g. the
+// _bucket samples of a histogram), otherwise to a family of their own name
+func (f *MetricFilter) Apply(data []byte) ([]byte, error) {
+	if f == nil {
+		return data, nil
+	}
+
+	var result bytes.Buffer
+	scanner := newLineScanner(data)
+	family, keepFamily := "", true
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		keep := true
+		if name, ok := metadataFamily(line); ok {
+			if name != family {
+				family, keepFamily = name, f.Keep(name)
+			}
+			keep = keepFamily
+		} else if len(line) > 0 && line[0] != '#' {
+			if name := sampleName(line); family != "" && inFamily(name, family) {
+				keep = keepFamily
+			} else {
+				keep = f.Keep(name)
+			}
+		}
+
+		if keep {
+			result.WriteString(line)
+			result.WriteString("\n")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner error: %w", err)
+	}
+	return result.Bytes(), nil
+}
+
+// metadataFamily returns the family name of a # HELP or # TYPE line
+func metadataFamily(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[0] != "#" || (fields[1] != "HELP" && fields[1] != "TYPE") {
+		return "", false
+	}
+	return fields[2], true
+}
+
+// sampleName returns the metric name of a sample line
+func sampleName(line string) string {
+	if end := strings.IndexAny(line, "{ \t"); end != -1 {
+		return line[:end]
+	}
+	return line
+}
+
+// inFamily reports whether a sample name belongs to a metric family
+func inFamily(name, family string) bool {
+	if name == family {
+		return true
+	}
+	for _, suffix := range familySuffixes {
+		if name == family+suffix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+const filterInput = `# HELP node_cpu_seconds_total Seconds the CPUs spent in each mode.
+# TYPE node_cpu_seconds_total counter
+node_cpu_seconds_total{cpu="0",mode="idle"} 100
+node_cpu_seconds_total{cpu="0",mode="user"} 20
+# HELP node_scrape_collector_duration_seconds Duration of a collector scrape.
+# TYPE node_scrape_collector_duration_seconds gauge
+node_scrape_collector_duration_seconds{collector="cpu"} 0.001
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.1"} 5
+http_request_duration_seconds_bucket{le="+Inf"} 9
+http_request_duration_seconds_sum 1.2
+http_request_duration_seconds_count 9
+node_load1 0.5
+go_goroutines 8
+`
+
+func TestMetricFilter_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    string
+	}{
+		{
+			name:    "exclude drops families with their metadata",
+			exclude: []string{"node_scrape_*", "/^go_/"},
+			want: `# HELP node_cpu_seconds_total Seconds the CPUs spent in each mode.
+# TYPE node_cpu_seconds_total counter
+node_cpu_seconds_total{cpu="0",mode="idle"} 100
+node_cpu_seconds_total{cpu="0",mode="user"} 20
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.1"} 5
+http_request_duration_seconds_bucket{le="+Inf"} 9
+http_request_duration_seconds_sum 1.2
+http_request_duration_seconds_count 9
+node_load1 0.5
+`,
+		},
+		{
+			name:    "include keeps only matching families",
+			include: []string{"node_cpu_*", "node_load?", "http_request_duration_seconds"},
+			want: `# HELP node_cpu_seconds_total Seconds the CPUs spent in each mode.
+# TYPE node_cpu_seconds_total counter
+node_cpu_seconds_total{cpu="0",mode="idle"} 100
+node_cpu_seconds_total{cpu="0",mode="user"} 20
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="0.1"} 5
+http_request_duration_seconds_bucket{le="+Inf"} 9
+http_request_duration_seconds_sum 1.2
+http_request_duration_seconds_count 9
+node_load1 0.5
+`,
+		},
+		{
+			name:    "exclude wins over include",
+			include: []string{"/^node_/"},
+			exclude: []string{"node_scrape_*", "node_cpu_seconds_total"},
+			want:    "node_load1 0.5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			include, err := config.CompileMetricPatterns(tt.include)
+			if err != nil {
+				t.Fatalf("CompileMetricPatterns failed: %v", err)
+			}
+			exclude, err := config.CompileMetricPatterns(tt.exclude)
+			if err != nil {
+				t.Fatalf("CompileMetricPatterns failed: %v", err)
+			}
+			filter := NewMetricFilter(include, exclude)
+			got, err := filter.Apply([]byte(filterInput))
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Apply() =\n%s\nwant:\n%s", got, tt.want)
+			}
+			// The filtered scrape is still valid text format
+			if err := ValidateText(got); err != nil {
+				t.Errorf("Filtered output is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewMetricFilter(t *testing.T) {
+	if filter := NewMetricFilter(nil, nil); filter != nil {
+		t.Errorf("Expected no filter without patterns, got %v", filter)
+	}
+
+	// A nil filter keeps everything
+	var filter *MetricFilter
+	if got, err := filter.Apply([]byte(filterInput)); err != nil || string(got) != filterInput {
+		t.Errorf("Expected a nil filter to return the input unchanged, got %q, %v", got, err)
+	}
+}
@@ -1,12 +1,15 @@
 package prometheus
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/node-pulse/agent/internal/oom"
 )
 
 // NodeExporterMetricSnapshot represents a parsed snapshot of node_exporter metrics
@@ -23,6 +26,10 @@ type NodeExporterMetricSnapshot struct {
 	CPUStealSeconds  float64 `json:"cpu_steal_seconds"`
 	CPUCores         int     `json:"cpu_cores"`
 
+	// Per-core CPU counters (sorted by CPU number), for spotting single-threaded bottlenecks
+	// Like the aggregate fields above, utilization is the delta between two snapshots
+	CPUPerCore []CPUCoreSnapshot `json:"cpu_per_core"`
+
 	// Memory Metrics (bytes, raw values)
 	MemoryTotalBytes     int64 `json:"memory_total_bytes"`
 	MemoryAvailableBytes int64 `json:"memory_available_bytes"`
@@ -32,6 +39,9 @@ type NodeExporterMetricSnapshot struct {
 	MemoryActiveBytes    int64 `json:"memory_active_bytes"`
 	MemoryInactiveBytes  int64 `json:"memory_inactive_bytes"`
 
+	// Set when MemoryAvailableBytes is estimated (kernels before 3.14 don't report MemAvailable)
+	MemoryAvailableEstimated bool `json:"memory_available_estimated,omitempty"`
+
 	// Swap Metrics (bytes, raw values)
 	SwapTotalBytes  int64 `json:"swap_total_bytes"`
 	SwapFreeBytes   int64 `json:"swap_free_bytes"`
@@ -42,6 +52,10 @@ type NodeExporterMetricSnapshot struct {
 	DiskFreeBytes      int64 `json:"disk_free_bytes"`
 	DiskAvailableBytes int64 `json:"disk_available_bytes"`
 
+	// Per-mountpoint usage (real filesystems only, sorted by mountpoint), e.g. separate /var or /data volumes
+	// The root filesystem is included here too; the fields above are kept for backward compatibility
+	Filesystems []FilesystemSnapshot `json:"filesystems"`
+
 	// Disk I/O (counters and totals)
 	DiskReadsCompletedTotal  int64   `json:"disk_reads_completed_total"`
 	DiskWritesCompletedTotal int64   `json:"disk_writes_completed_total"`
@@ -49,6 +63,15 @@ type NodeExporterMetricSnapshot struct {
 	DiskWrittenBytesTotal    int64   `json:"disk_written_bytes_total"`
 	DiskIOTimeSecondsTotal   float64 `json:"disk_io_time_seconds_total"`
 
+	// Disk Latency (time spent on completed ops, and average latency per op since boot)
+	DiskReadTimeSecondsTotal  float64 `json:"disk_read_time_seconds_total"`
+	DiskWriteTimeSecondsTotal float64 `json:"disk_write_time_seconds_total"`
+	DiskReadLatencySeconds    float64 `json:"disk_read_latency_seconds"`  // read_time / reads_completed
+	DiskWriteLatencySeconds   float64 `json:"disk_write_latency_seconds"` // write_time / writes_completed
+
+	// Device the disk I/O fields above come from (primary_disk, vda, sda, nvme0n1, or the first by name)
+	DiskPrimaryDevice string `json:"disk_primary_device"`
+
 	// Network Metrics (counters and totals)
 	NetworkReceiveBytesTotal    int64 `json:"network_receive_bytes_total"`
 	NetworkTransmitBytesTotal   int64 `json:"network_transmit_bytes_total"`
@@ -59,6 +82,13 @@ type NodeExporterMetricSnapshot struct {
 	NetworkReceiveDropTotal     int64 `json:"network_receive_drop_total"`
 	NetworkTransmitDropTotal    int64 `json:"network_transmit_drop_total"`
 
+	// Interface the aggregate network fields above come from (eth0, en0, or the first by name)
+	NetworkPrimaryInterface string `json:"network_primary_interface"`
+
+	// Per-interface network counters (physical interfaces only, sorted by device name)
+	// The aggregate fields above are kept for backward compatibility (primary interface)
+	NetworkInterfaces []NetworkInterfaceSnapshot `json:"network_interfaces"`
+
 	// System Load Average
 	Load1Min  float64 `json:"load_1min"`
 	Load5Min  float64 `json:"load_5min"`
@@ -71,17 +101,199 @@ type NodeExporterMetricSnapshot struct {
 
 	// System Uptime
 	UptimeSeconds int64 `json:"uptime_seconds"`
+
+	// Per-second rates of the counters above since the previous sent snapshot
+	// Computed by the sender when node_exporter.compute_rates is enabled (nil otherwise)
+	Rates *NodeRatesSnapshot `json:"rates,omitempty"`
+
+	// Pressure stall information (nil on kernels without PSI, < 4.20, or with the pressure collector disabled)
+	Pressure *PressureSnapshot `json:"pressure,omitempty"`
+
+	// System-wide file descriptors from /proc/sys/fs/file-nr (filefd collector), for catching fd leaks
+	FileFDAllocated int64 `json:"file_fd_allocated"`
+	FileFDMaximum   int64 `json:"file_fd_maximum"`
+
+	// TCP connections by state (e.g. ESTABLISHED, TIME_WAIT), IPv4 and IPv6 combined
+	// Nil unless the exporter's tcpstat collector is enabled (--collector.tcpstat, off by default)
+	TCPConnectionStates map[string]int64 `json:"tcp_connection_states,omitempty"`
+
+	// OOM kills since boot (vmstat collector, Linux 4.13+)
+	OOMKillsTotal int64 `json:"oom_kills_total"`
+
+	// Processes killed by the OOM killer since the previous scrape, read from the kernel log by the agent
+	// Empty when OOM detection is disabled or not permitted (see metrics.oom_source)
+	OOMKillCount int               `json:"oom_kill_count"`
+	OOMKills     []OOMKillSnapshot `json:"oom_kills,omitempty"`
+
+	// Kernel entropy (0 when the exporter's entropy collector is disabled)
+	// Since Linux 5.18 both are fixed at 256, as the RNG no longer blocks once seeded
+	EntropyAvailableBits int64 `json:"entropy_available_bits"`
+	EntropyPoolSizeBits  int64 `json:"entropy_pool_size_bits"`
+
+	// CPU and NUMA Topology (0 / empty when the exporter doesn't expose it)
+	// Sockets and physical cores come from the thermal_throttle collector (package/core labels),
+	// NUMA nodes from the meminfo_numa collector (disabled by default in node_exporter)
+	CPUSockets       int                `json:"cpu_sockets"`
+	CPUPhysicalCores int                `json:"cpu_physical_cores"`
+	NUMANodes        []NUMANodeSnapshot `json:"numa_nodes"`
+
+	// Temperature sensors from the hwmon collector (sorted by chip and sensor)
+	// Empty on hosts without /sys/class/hwmon, which includes most VMs and containers
+	Thermal []ThermalSnapshot `json:"thermal"`
+}
+
+// OOMKillSnapshot represents a process killed by the OOM killer
+type OOMKillSnapshot struct {
+	PID     int    `json:"pid"`
+	Process string `json:"process"`
+}
+
+// ThermalSnapshot represents a single hwmon temperature sensor
+type ThermalSnapshot struct {
+	Chip    string  `json:"chip"`   // e.g. platform_coretemp_0, nvme_nvme0
+	Sensor  string  `json:"sensor"` // e.g. temp1
+	Label   string  `json:"label"`  // e.g. "Core 0", "Composite" (empty if the driver has no label)
+	Celsius float64 `json:"celsius"`
+}
+
+// FilesystemSnapshot represents the usage of a single mounted filesystem
+type FilesystemSnapshot struct {
+	Mountpoint     string `json:"mountpoint"`
+	Device         string `json:"device"`
+	FSType         string `json:"fstype"`
+	SizeBytes      int64  `json:"size_bytes"`
+	FreeBytes      int64  `json:"free_bytes"`
+	AvailableBytes int64  `json:"available_bytes"` // Free space for unprivileged users (excludes reserved blocks)
+}
+
+// CPUCoreSnapshot represents the time counters of a single logical CPU
+type CPUCoreSnapshot struct {
+	CPU           string  `json:"cpu"`
+	IdleSeconds   float64 `json:"idle_seconds"`
+	IowaitSeconds float64 `json:"iowait_seconds"`
+	SystemSeconds float64 `json:"system_seconds"`
+	UserSeconds   float64 `json:"user_seconds"`
+	StealSeconds  float64 `json:"steal_seconds"`
+}
+
+// PressureSnapshot represents Linux pressure stall information (/proc/pressure/*)
+// node_exporter exposes cumulative stall time only; the avg10/avg60/avg300 percentages
+// are the rate of these counters over the window
+// "waiting" is PSI "some" (at least one task stalled), "stalled" is PSI "full" (all tasks stalled)
+type PressureSnapshot struct {
+	CPUWaitingSecondsTotal    float64 `json:"cpu_waiting_seconds_total"`
+	MemoryWaitingSecondsTotal float64 `json:"memory_waiting_seconds_total"`
+	MemoryStalledSecondsTotal float64 `json:"memory_stalled_seconds_total"`
+	IOWaitingSecondsTotal     float64 `json:"io_waiting_seconds_total"`
+	IOStalledSecondsTotal     float64 `json:"io_stalled_seconds_total"`
+}
+
+// NodeRatesSnapshot holds per-second rates of node_exporter counters between consecutive snapshots
+// All rates are 0 for the first snapshot, and a rate is 0 when its counter reset (e.g. a reboot)
+// CPU rates are in seconds per second, i.e. the number of cores busy in that mode
+type NodeRatesSnapshot struct {
+	IntervalSeconds float64 `json:"interval_seconds"` // Time between the two scrapes (0 for the first snapshot)
+
+	CPUIdle   float64 `json:"cpu_idle"`
+	CPUIowait float64 `json:"cpu_iowait"`
+	CPUSystem float64 `json:"cpu_system"`
+	CPUUser   float64 `json:"cpu_user"`
+	CPUSteal  float64 `json:"cpu_steal"`
+
+	DiskReadsPerSecond         float64 `json:"disk_reads_per_second"`
+	DiskWritesPerSecond        float64 `json:"disk_writes_per_second"`
+	DiskReadBytesPerSecond     float64 `json:"disk_read_bytes_per_second"`
+	DiskWrittenBytesPerSecond  float64 `json:"disk_written_bytes_per_second"`
+	DiskIOTimeSecondsPerSecond float64 `json:"disk_io_time_seconds_per_second"` // Fraction of time the disk was busy
+
+	NetworkReceiveBytesPerSecond    float64 `json:"network_receive_bytes_per_second"`
+	NetworkTransmitBytesPerSecond   float64 `json:"network_transmit_bytes_per_second"`
+	NetworkReceivePacketsPerSecond  float64 `json:"network_receive_packets_per_second"`
+	NetworkTransmitPacketsPerSecond float64 `json:"network_transmit_packets_per_second"`
+	NetworkReceiveErrsPerSecond     float64 `json:"network_receive_errs_per_second"`
+	NetworkTransmitErrsPerSecond    float64 `json:"network_transmit_errs_per_second"`
+	NetworkReceiveDropPerSecond     float64 `json:"network_receive_drop_per_second"`
+	NetworkTransmitDropPerSecond    float64 `json:"network_transmit_drop_per_second"`
+}
+
+// NUMANodeSnapshot represents the memory of a single NUMA node
+type NUMANodeSnapshot struct {
+	Node             string `json:"node"`
+	MemoryTotalBytes int64  `json:"memory_total_bytes"`
+	MemoryFreeBytes  int64  `json:"memory_free_bytes"`
+	MemoryUsedBytes  int64  `json:"memory_used_bytes"`
+}
+
+// NetworkInterfaceSnapshot represents the counters of a single network interface
+type NetworkInterfaceSnapshot struct {
+	Device               string `json:"device"`
+	ReceiveBytesTotal    int64  `json:"receive_bytes_total"`
+	TransmitBytesTotal   int64  `json:"transmit_bytes_total"`
+	ReceivePacketsTotal  int64  `json:"receive_packets_total"`
+	TransmitPacketsTotal int64  `json:"transmit_packets_total"`
+	ReceiveErrsTotal     int64  `json:"receive_errs_total"`
+	TransmitErrsTotal    int64  `json:"transmit_errs_total"`
+	ReceiveDropTotal     int64  `json:"receive_drop_total"`
+	TransmitDropTotal    int64  `json:"transmit_drop_total"`
+}
+
+// ParseOptions configures ParseNodeExporterMetricsWithOptions
+// The zero value keeps the built-in device filters and primary device selection
+type ParseOptions struct {
+	PrimaryDisk    string // Disk reported as primary, tracked even if the filters drop it; empty = vda > sda > nvme0n1 > first by name
+	PrimaryNetwork string // Interface reported as primary, tracked even if the filters drop it; empty = eth0 > en0 > first by name
+
+	// Device filters. An include regex replaces the built-in filter (e.g. to admit xvda or bond0),
+	// an exclude regex drops matching devices on top of it. nil = built-in filter only
+	DiskInclude    *regexp.Regexp
+	DiskExclude    *regexp.Regexp
+	NetworkInclude *regexp.Regexp
+	NetworkExclude *regexp.Regexp
+}
+
+// acceptDisk reports whether a disk device is tracked
+// The configured primary disk always is, even if the filters would drop it (e.g. xvda, dm-0, md0)
+func (o ParseOptions) acceptDisk(device string) bool {
+	if o.PrimaryDisk != "" && device == o.PrimaryDisk {
+		return true
+	}
+	return acceptDevice(device, o.DiskInclude, o.DiskExclude, isPhysicalDisk)
+}
+
+// acceptNetwork reports whether a network interface is tracked
+// The configured primary interface always is, even if the filters would drop it
+func (o ParseOptions) acceptNetwork(device string) bool {
+	if o.PrimaryNetwork != "" && device == o.PrimaryNetwork {
+		return true
+	}
+	return acceptDevice(device, o.NetworkInclude, o.NetworkExclude, isPhysicalNetwork)
+}
+
+func acceptDevice(device string, include, exclude *regexp.Regexp, builtin func(string) bool) bool {
+	if exclude != nil && exclude.MatchString(device) {
+		return false
+	}
+	if include != nil {
+		return include.MatchString(device)
+	}
+	return builtin(device)
 }
 
 // ParseNodeExporterMetrics parses Prometheus node_exporter text format and extracts essential metrics
 // Returns a NodeExporterMetricSnapshot with raw counter values (no percentages calculated)
 // This parser is specifically designed for node_exporter metrics only
 func ParseNodeExporterMetrics(data []byte) (*NodeExporterMetricSnapshot, error) {
+	return ParseNodeExporterMetricsWithOptions(data, ParseOptions{})
+}
+
+// ParseNodeExporterMetricsWithOptions is ParseNodeExporterMetrics with configurable primary devices
+// A configured device missing from the scrape falls back to the default priority list
+func ParseNodeExporterMetricsWithOptions(data []byte, opts ParseOptions) (*NodeExporterMetricSnapshot, error) {
 	snapshot := &NodeExporterMetricSnapshot{
 		Timestamp: time.Now().UTC(),
 	}
 
-	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner := newLineScanner(data)
 
 	// Track CPU metrics per core for aggregation
 	cpuIdlePerCore := make(map[string]float64)
@@ -96,6 +308,15 @@ func ParseNodeExporterMetrics(data []byte) (*NodeExporterMetricSnapshot, error)
 	// Track disk metrics per device for primary disk selection
 	diskDevices := make(map[string]*diskMetrics)
 
+	// Track filesystem usage per mountpoint
+	filesystems := make(map[string]*filesystemMetrics)
+
+	// Track CPU packages/cores and NUMA nodes for topology
+	topology := newTopologyMetrics()
+
+	// Track hwmon temperatures and labels per chip/sensor
+	thermal := make(map[thermalKey]*thermalMetrics)
+
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -106,7 +327,7 @@ func ParseNodeExporterMetrics(data []byte) (*NodeExporterMetricSnapshot, error)
 
 		// Parse metric line: metric_name{labels} value [timestamp]
 		if err := parseLine(line, snapshot, cpuIdlePerCore, cpuUserPerCore, cpuSystemPerCore,
-			cpuIowaitPerCore, cpuStealPerCore, networkDevices, diskDevices); err != nil {
+			cpuIowaitPerCore, cpuStealPerCore, networkDevices, diskDevices, filesystems, topology, thermal, opts); err != nil {
 			// Log but don't fail on individual parse errors
 			continue
 		}
@@ -123,12 +344,28 @@ func ParseNodeExporterMetrics(data []byte) (*NodeExporterMetricSnapshot, error)
 	snapshot.CPUIowaitSeconds = sumMap(cpuIowaitPerCore)
 	snapshot.CPUStealSeconds = sumMap(cpuStealPerCore)
 	snapshot.CPUCores = len(cpuIdlePerCore)
+	snapshot.CPUPerCore = buildCPUCores(cpuIdlePerCore, cpuUserPerCore, cpuSystemPerCore, cpuIowaitPerCore, cpuStealPerCore)
 
 	// Select primary network interface (usually eth0, or first non-loopback)
-	selectPrimaryNetwork(snapshot, networkDevices)
+	selectPrimaryNetwork(snapshot, networkDevices, opts.PrimaryNetwork)
+
+	// Keep per-interface counters for multi-homed servers
+	snapshot.NetworkInterfaces = buildNetworkInterfaces(networkDevices)
 
 	// Select primary disk (vda, sda, or first available)
-	selectPrimaryDisk(snapshot, diskDevices)
+	selectPrimaryDisk(snapshot, diskDevices, opts.PrimaryDisk)
+
+	// Keep per-mountpoint usage for servers with separate volumes
+	snapshot.Filesystems = buildFilesystems(filesystems)
+
+	// Kernels older than 3.14 don't report MemAvailable
+	estimateMemoryAvailable(snapshot)
+
+	// CPU sockets, physical cores, and per-NUMA-node memory
+	buildTopology(snapshot, topology)
+
+	// Temperature sensors (labels are matched up with readings by chip and sensor)
+	snapshot.Thermal = buildThermal(thermal)
 
 	// Calculate uptime from boot time
 	if bootTime := snapshot.UptimeSeconds; bootTime > 0 {
@@ -150,27 +387,68 @@ type networkMetrics struct {
 }
 
 type diskMetrics struct {
-	readsCompleted  int64
-	writesCompleted int64
-	readBytes       int64
-	writtenBytes    int64
-	ioTimeSeconds   float64
+	readsCompleted   int64
+	writesCompleted  int64
+	readBytes        int64
+	writtenBytes     int64
+	ioTimeSeconds    float64
+	readTimeSeconds  float64
+	writeTimeSeconds float64
+}
+
+type filesystemMetrics struct {
+	device         string
+	fstype         string
+	sizeBytes      int64
+	freeBytes      int64
+	availableBytes int64
+}
+
+type numaMetrics struct {
+	memTotal int64
+	memFree  int64
+	memUsed  int64
+}
+
+type topologyMetrics struct {
+	packages map[string]bool // Physical package (socket) IDs
+	cores    map[string]bool // "package/core" IDs (core IDs are only unique within a package)
+	numa     map[string]*numaMetrics
+}
+
+type thermalKey struct {
+	chip   string
+	sensor string
+}
+
+type thermalMetrics struct {
+	label      string
+	celsius    float64
+	hasReading bool // Labels can exist for sensors without a temp*_input
+}
+
+func newTopologyMetrics() *topologyMetrics {
+	return &topologyMetrics{
+		packages: make(map[string]bool),
+		cores:    make(map[string]bool),
+		numa:     make(map[string]*numaMetrics),
+	}
 }
 
 func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 	cpuIdle, cpuUser, cpuSystem, cpuIowait, cpuSteal map[string]float64,
 	networkDevices map[string]*networkMetrics,
-	diskDevices map[string]*diskMetrics) error {
+	diskDevices map[string]*diskMetrics,
+	filesystems map[string]*filesystemMetrics,
+	topology *topologyMetrics,
+	thermal map[thermalKey]*thermalMetrics,
+	opts ParseOptions) error {
 
-	// Split metric name and rest
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid line format")
+	metricPart, valuePart, err := splitSample(line)
+	if err != nil {
+		return err
 	}
 
-	metricPart := parts[0]
-	valuePart := parts[1]
-
 	// Extract metric name and labels
 	var metricName string
 	var labels map[string]string
@@ -188,6 +466,11 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 	if err != nil {
 		return err
 	}
+	// NaN/±Inf (e.g. unset gauges) are treated as absent: they'd turn into garbage when cast
+	// to int64, and JSON can't encode them
+	if !isFinite(value) {
+		return nil
+	}
 
 	// Parse specific metrics
 	switch metricName {
@@ -208,6 +491,21 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 			cpuSteal[cpu] = value
 		}
 
+	// CPU topology (thermal_throttle collector)
+	case "node_cpu_package_throttles_total":
+		topology.packages[labels["package"]] = true
+	case "node_cpu_core_throttles_total":
+		topology.packages[labels["package"]] = true
+		topology.cores[labels["package"]+"/"+labels["core"]] = true
+
+	// NUMA memory (meminfo_numa collector)
+	case "node_memory_numa_MemTotal":
+		topology.numaNode(labels["node"]).memTotal = int64(value)
+	case "node_memory_numa_MemFree":
+		topology.numaNode(labels["node"]).memFree = int64(value)
+	case "node_memory_numa_MemUsed":
+		topology.numaNode(labels["node"]).memUsed = int64(value)
+
 	// Memory metrics
 	case "node_memory_MemTotal_bytes":
 		snapshot.MemoryTotalBytes = int64(value)
@@ -237,19 +535,28 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		if labels["mountpoint"] == "/" && !isVirtualFilesystem(labels["fstype"]) {
 			snapshot.DiskTotalBytes = int64(value)
 		}
+		if fs := filesystem(filesystems, labels); fs != nil {
+			fs.sizeBytes = int64(value)
+		}
 	case "node_filesystem_free_bytes":
 		if labels["mountpoint"] == "/" && !isVirtualFilesystem(labels["fstype"]) {
 			snapshot.DiskFreeBytes = int64(value)
 		}
+		if fs := filesystem(filesystems, labels); fs != nil {
+			fs.freeBytes = int64(value)
+		}
 	case "node_filesystem_avail_bytes":
 		if labels["mountpoint"] == "/" && !isVirtualFilesystem(labels["fstype"]) {
 			snapshot.DiskAvailableBytes = int64(value)
 		}
+		if fs := filesystem(filesystems, labels); fs != nil {
+			fs.availableBytes = int64(value)
+		}
 
 	// Disk I/O metrics
 	case "node_disk_reads_completed_total":
 		device := labels["device"]
-		if isPhysicalDisk(device) {
+		if opts.acceptDisk(device) {
 			if diskDevices[device] == nil {
 				diskDevices[device] = &diskMetrics{}
 			}
@@ -257,7 +564,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_disk_writes_completed_total":
 		device := labels["device"]
-		if isPhysicalDisk(device) {
+		if opts.acceptDisk(device) {
 			if diskDevices[device] == nil {
 				diskDevices[device] = &diskMetrics{}
 			}
@@ -265,7 +572,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_disk_read_bytes_total":
 		device := labels["device"]
-		if isPhysicalDisk(device) {
+		if opts.acceptDisk(device) {
 			if diskDevices[device] == nil {
 				diskDevices[device] = &diskMetrics{}
 			}
@@ -273,7 +580,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_disk_written_bytes_total":
 		device := labels["device"]
-		if isPhysicalDisk(device) {
+		if opts.acceptDisk(device) {
 			if diskDevices[device] == nil {
 				diskDevices[device] = &diskMetrics{}
 			}
@@ -281,17 +588,33 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_disk_io_time_seconds_total":
 		device := labels["device"]
-		if isPhysicalDisk(device) {
+		if opts.acceptDisk(device) {
 			if diskDevices[device] == nil {
 				diskDevices[device] = &diskMetrics{}
 			}
 			diskDevices[device].ioTimeSeconds = value
 		}
+	case "node_disk_read_time_seconds_total":
+		device := labels["device"]
+		if opts.acceptDisk(device) {
+			if diskDevices[device] == nil {
+				diskDevices[device] = &diskMetrics{}
+			}
+			diskDevices[device].readTimeSeconds = value
+		}
+	case "node_disk_write_time_seconds_total":
+		device := labels["device"]
+		if opts.acceptDisk(device) {
+			if diskDevices[device] == nil {
+				diskDevices[device] = &diskMetrics{}
+			}
+			diskDevices[device].writeTimeSeconds = value
+		}
 
 	// Network metrics
 	case "node_network_receive_bytes_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -299,7 +622,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_transmit_bytes_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -307,7 +630,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_receive_packets_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -315,7 +638,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_transmit_packets_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -323,7 +646,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_receive_errs_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -331,7 +654,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_transmit_errs_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -339,7 +662,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_receive_drop_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -347,7 +670,7 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 		}
 	case "node_network_transmit_drop_total":
 		device := labels["device"]
-		if isPhysicalNetwork(device) {
+		if opts.acceptNetwork(device) {
 			if networkDevices[device] == nil {
 				networkDevices[device] = &networkMetrics{}
 			}
@@ -370,6 +693,59 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 	case "node_forks_total":
 		snapshot.ProcessesTotal = int(value)
 
+	// Pressure stall information
+	case "node_pressure_cpu_waiting_seconds_total":
+		pressure(snapshot).CPUWaitingSecondsTotal = value
+	case "node_pressure_memory_waiting_seconds_total":
+		pressure(snapshot).MemoryWaitingSecondsTotal = value
+	case "node_pressure_memory_stalled_seconds_total":
+		pressure(snapshot).MemoryStalledSecondsTotal = value
+	case "node_pressure_io_waiting_seconds_total":
+		pressure(snapshot).IOWaitingSecondsTotal = value
+	case "node_pressure_io_stalled_seconds_total":
+		pressure(snapshot).IOStalledSecondsTotal = value
+
+	// File descriptors and TCP connections
+	case "node_filefd_allocated":
+		snapshot.FileFDAllocated = int64(value)
+	case "node_filefd_maximum":
+		snapshot.FileFDMaximum = saturatingInt64(value)
+	case "node_tcp_connection_states":
+		if state := labels["state"]; state != "" {
+			if snapshot.TCPConnectionStates == nil {
+				snapshot.TCPConnectionStates = make(map[string]int64)
+			}
+			// node_exporter labels are lowercase ("time_wait"), report the kernel's names
+			snapshot.TCPConnectionStates[strings.ToUpper(state)] += int64(value)
+		}
+
+	// OOM kills
+	case "node_vmstat_oom_kill":
+		snapshot.OOMKillsTotal = int64(value)
+	case oom.MetricName:
+		pid, _ := strconv.Atoi(labels["pid"])
+		snapshot.OOMKills = append(snapshot.OOMKills, OOMKillSnapshot{PID: pid, Process: labels["process"]})
+		snapshot.OOMKillCount = len(snapshot.OOMKills)
+
+	// Entropy
+	case "node_entropy_available_bits":
+		snapshot.EntropyAvailableBits = int64(value)
+	case "node_entropy_pool_size_bits":
+		snapshot.EntropyPoolSizeBits = int64(value)
+
+	// Temperature sensors (hwmon)
+	case "node_hwmon_temp_celsius":
+		if chip, sensor := labels["chip"], labels["sensor"]; chip != "" && sensor != "" {
+			m := thermalSensor(thermal, chip, sensor)
+			m.celsius = value
+			m.hasReading = true
+		}
+	case "node_hwmon_sensor_label":
+		// Info metric: the label is in the "label" label, the value is always 1
+		if chip, sensor := labels["chip"], labels["sensor"]; chip != "" && sensor != "" {
+			thermalSensor(thermal, chip, sensor).label = labels["label"]
+		}
+
 	// Uptime (boot time - will be converted to uptime later)
 	case "node_boot_time_seconds":
 		snapshot.UptimeSeconds = int64(value)
@@ -378,24 +754,107 @@ func parseLine(line string, snapshot *NodeExporterMetricSnapshot,
 	return nil
 }
 
+// splitSample splits a sample line into the metric part (name and labels) and the value
+// Label values may contain spaces (e.g. hwmon labels like "Core 0"), so split after the closing brace
+func splitSample(line string) (metricPart, valuePart string, err error) {
+	if end := strings.LastIndex(line, "}"); end != -1 {
+		rest := strings.Fields(line[end+1:])
+		if len(rest) < 1 {
+			return "", "", fmt.Errorf("invalid line format")
+		}
+		return line[:end+1], rest[0], nil
+	}
+
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid line format")
+	}
+	return parts[0], parts[1], nil
+}
+
+// parseLabels parses the label set between the braces of a sample line, e.g. a="1",b="x,y"
+// Values are quoted strings that may contain commas, '=' and the escapes \\, \" and \n
+// (exposition format). Parsing stops at the first malformed pair, keeping the labels before it
 func parseLabels(labelsStr string) map[string]string {
 	labels := make(map[string]string)
-	pairs := strings.Split(labelsStr, ",")
-	for _, pair := range pairs {
-		kv := strings.Split(pair, "=")
-		if len(kv) == 2 {
-			key := strings.TrimSpace(kv[0])
-			value := strings.Trim(strings.TrimSpace(kv[1]), "\"")
-			labels[key] = value
+	rest := labelsStr
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			return labels
+		}
+
+		eq := strings.IndexByte(rest, '=')
+		if eq == -1 {
+			return labels
+		}
+		key := strings.TrimSpace(rest[:eq])
+		rest = strings.TrimLeft(rest[eq+1:], " \t")
+
+		value, n, ok := parseLabelValue(rest)
+		if !ok || key == "" {
+			return labels
 		}
+		labels[key] = value
+		rest = rest[n:]
 	}
-	return labels
+}
+
+// parseLabelValue parses a quoted label value at the start of s, unescaping it
+// Returns the value and the number of bytes consumed, including the quotes
+func parseLabelValue(s string) (string, int, bool) {
+	if s == "" || s[0] != '"' {
+		return "", 0, false
+	}
+
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return value.String(), i + 1, true
+		case '\\':
+			if i+1 == len(s) {
+				return "", 0, false
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case '\\', '"':
+				value.WriteByte(s[i])
+			default:
+				// Unknown escape: keep it as written
+				value.WriteByte('\\')
+				value.WriteByte(s[i])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", 0, false // Unterminated quote
 }
 
 func parseValue(s string) (float64, error) {
 	return strconv.ParseFloat(s, 64)
 }
 
+// isFinite reports whether a parsed sample value is neither NaN nor ±Inf
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// saturatingInt64 converts a float to int64, clamping values beyond the int64 range
+// fs.file-max defaults to LLONG_MAX on 64-bit kernels, which float64 rounds up past it
+func saturatingInt64(value float64) int64 {
+	if value >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	if value <= math.MinInt64 {
+		return math.MinInt64
+	}
+	return int64(value)
+}
+
 func sumMap(m map[string]float64) float64 {
 	sum := 0.0
 	for _, v := range m {
@@ -431,22 +890,26 @@ func isPhysicalNetwork(device string) bool {
 	return true
 }
 
-func selectPrimaryNetwork(snapshot *NodeExporterMetricSnapshot, devices map[string]*networkMetrics) {
-	// Priority: eth0 > en0 > first available
-	var primary *networkMetrics
-	if devices["eth0"] != nil {
-		primary = devices["eth0"]
+func selectPrimaryNetwork(snapshot *NodeExporterMetricSnapshot, devices map[string]*networkMetrics, preferred string) {
+	// Priority: configured interface > eth0 > en0 > first available
+	var name string
+	if preferred != "" && devices[preferred] != nil {
+		name = preferred
+	} else if devices["eth0"] != nil {
+		name = "eth0"
 	} else if devices["en0"] != nil {
-		primary = devices["en0"]
+		name = "en0"
 	} else {
-		// Get first available
-		for _, metrics := range devices {
-			primary = metrics
-			break
+		// First by name, so the choice doesn't change between scrapes with the same interfaces
+		for device := range devices {
+			if name == "" || device < name {
+				name = device
+			}
 		}
 	}
 
-	if primary != nil {
+	if primary := devices[name]; primary != nil {
+		snapshot.NetworkPrimaryInterface = name
 		snapshot.NetworkReceiveBytesTotal = primary.rxBytes
 		snapshot.NetworkTransmitBytesTotal = primary.txBytes
 		snapshot.NetworkReceivePacketsTotal = primary.rxPackets
@@ -458,28 +921,265 @@ func selectPrimaryNetwork(snapshot *NodeExporterMetricSnapshot, devices map[stri
 	}
 }
 
-func selectPrimaryDisk(snapshot *NodeExporterMetricSnapshot, devices map[string]*diskMetrics) {
-	// Priority: vda > sda > nvme0n1 > first available
-	var primary *diskMetrics
-	if devices["vda"] != nil {
-		primary = devices["vda"]
+// setPrimaryNetwork sets the aggregate network fields from a single interface
+func setPrimaryNetwork(snapshot *NodeExporterMetricSnapshot, iface NetworkInterfaceSnapshot) {
+	snapshot.NetworkPrimaryInterface = iface.Device
+	snapshot.NetworkReceiveBytesTotal = iface.ReceiveBytesTotal
+	snapshot.NetworkTransmitBytesTotal = iface.TransmitBytesTotal
+	snapshot.NetworkReceivePacketsTotal = iface.ReceivePacketsTotal
+	snapshot.NetworkTransmitPacketsTotal = iface.TransmitPacketsTotal
+	snapshot.NetworkReceiveErrsTotal = iface.ReceiveErrsTotal
+	snapshot.NetworkTransmitErrsTotal = iface.TransmitErrsTotal
+	snapshot.NetworkReceiveDropTotal = iface.ReceiveDropTotal
+	snapshot.NetworkTransmitDropTotal = iface.TransmitDropTotal
+}
+
+// buildCPUCores converts per-core counters to snapshots sorted by CPU number
+func buildCPUCores(idle, user, system, iowait, steal map[string]float64) []CPUCoreSnapshot {
+	cpus := make([]string, 0, len(idle))
+	for cpu := range idle {
+		cpus = append(cpus, cpu)
+	}
+	// Numeric order (cpu2 before cpu10), falling back to string order for non-numeric labels
+	sort.Slice(cpus, func(i, j int) bool {
+		a, errA := strconv.Atoi(cpus[i])
+		b, errB := strconv.Atoi(cpus[j])
+		if errA != nil || errB != nil {
+			return cpus[i] < cpus[j]
+		}
+		return a < b
+	})
+
+	cores := make([]CPUCoreSnapshot, 0, len(cpus))
+	for _, cpu := range cpus {
+		cores = append(cores, CPUCoreSnapshot{
+			CPU:           cpu,
+			IdleSeconds:   idle[cpu],
+			IowaitSeconds: iowait[cpu],
+			SystemSeconds: system[cpu],
+			UserSeconds:   user[cpu],
+			StealSeconds:  steal[cpu],
+		})
+	}
+	return cores
+}
+
+// filesystem returns the metrics for a line's mountpoint, creating them if needed
+// Returns nil for virtual filesystems (tmpfs, overlay, ...), which don't fill up a disk
+func filesystem(filesystems map[string]*filesystemMetrics, labels map[string]string) *filesystemMetrics {
+	mountpoint := labels["mountpoint"]
+	if mountpoint == "" || isVirtualFilesystem(labels["fstype"]) {
+		return nil
+	}
+	if filesystems[mountpoint] == nil {
+		filesystems[mountpoint] = &filesystemMetrics{device: labels["device"], fstype: labels["fstype"]}
+	}
+	return filesystems[mountpoint]
+}
+
+// buildFilesystems converts per-mountpoint usage to snapshots sorted by mountpoint
+func buildFilesystems(filesystems map[string]*filesystemMetrics) []FilesystemSnapshot {
+	mountpoints := make([]string, 0, len(filesystems))
+	for mountpoint := range filesystems {
+		mountpoints = append(mountpoints, mountpoint)
+	}
+	sort.Strings(mountpoints)
+
+	snapshots := make([]FilesystemSnapshot, 0, len(mountpoints))
+	for _, mountpoint := range mountpoints {
+		fs := filesystems[mountpoint]
+		snapshots = append(snapshots, FilesystemSnapshot{
+			Mountpoint:     mountpoint,
+			Device:         fs.device,
+			FSType:         fs.fstype,
+			SizeBytes:      fs.sizeBytes,
+			FreeBytes:      fs.freeBytes,
+			AvailableBytes: fs.availableBytes,
+		})
+	}
+	return snapshots
+}
+
+// buildNetworkInterfaces converts per-device counters to snapshots sorted by device name
+func buildNetworkInterfaces(devices map[string]*networkMetrics) []NetworkInterfaceSnapshot {
+	names := make([]string, 0, len(devices))
+	for name := range devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	interfaces := make([]NetworkInterfaceSnapshot, 0, len(names))
+	for _, name := range names {
+		m := devices[name]
+		interfaces = append(interfaces, NetworkInterfaceSnapshot{
+			Device:               name,
+			ReceiveBytesTotal:    m.rxBytes,
+			TransmitBytesTotal:   m.txBytes,
+			ReceivePacketsTotal:  m.rxPackets,
+			TransmitPacketsTotal: m.txPackets,
+			ReceiveErrsTotal:     m.rxErrs,
+			TransmitErrsTotal:    m.txErrs,
+			ReceiveDropTotal:     m.rxDrop,
+			TransmitDropTotal:    m.txDrop,
+		})
+	}
+	return interfaces
+}
+
+// estimateMemoryAvailable fills in MemoryAvailableBytes when the kernel doesn't report MemAvailable
+// Uses MemFree + Buffers + Cached, the usual pre-3.14 approximation (slightly optimistic,
+// since not all page cache is reclaimable)
+func estimateMemoryAvailable(snapshot *NodeExporterMetricSnapshot) {
+	if snapshot.MemoryAvailableBytes != 0 || snapshot.MemoryTotalBytes == 0 {
+		return
+	}
+
+	estimate := snapshot.MemoryFreeBytes + snapshot.MemoryBuffersBytes + snapshot.MemoryCachedBytes
+	if estimate > snapshot.MemoryTotalBytes {
+		estimate = snapshot.MemoryTotalBytes
+	}
+	snapshot.MemoryAvailableBytes = estimate
+	snapshot.MemoryAvailableEstimated = true
+}
+
+// numaNode returns the metrics for a NUMA node, creating them if needed
+func (t *topologyMetrics) numaNode(node string) *numaMetrics {
+	if t.numa[node] == nil {
+		t.numa[node] = &numaMetrics{}
+	}
+	return t.numa[node]
+}
+
+// buildTopology sets socket/core counts and per-NUMA-node memory sorted by node ID
+func buildTopology(snapshot *NodeExporterMetricSnapshot, topology *topologyMetrics) {
+	snapshot.CPUSockets = len(topology.packages)
+	snapshot.CPUPhysicalCores = len(topology.cores)
+
+	nodes := make([]string, 0, len(topology.numa))
+	for node := range topology.numa {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		a, errA := strconv.Atoi(nodes[i])
+		b, errB := strconv.Atoi(nodes[j])
+		if errA != nil || errB != nil {
+			return nodes[i] < nodes[j]
+		}
+		return a < b
+	})
+
+	snapshot.NUMANodes = make([]NUMANodeSnapshot, 0, len(nodes))
+	for _, node := range nodes {
+		m := topology.numa[node]
+		snapshot.NUMANodes = append(snapshot.NUMANodes, NUMANodeSnapshot{
+			Node:             node,
+			MemoryTotalBytes: m.memTotal,
+			MemoryFreeBytes:  m.memFree,
+			MemoryUsedBytes:  m.memUsed,
+		})
+	}
+}
+
+// thermalSensor returns the metrics for a hwmon sensor, creating them if needed
+func thermalSensor(thermal map[thermalKey]*thermalMetrics, chip, sensor string) *thermalMetrics {
+	key := thermalKey{chip: chip, sensor: sensor}
+	if thermal[key] == nil {
+		thermal[key] = &thermalMetrics{}
+	}
+	return thermal[key]
+}
+
+// buildThermal converts hwmon sensors to snapshots sorted by chip and sensor
+// Sensors with a label but no temperature reading are dropped
+func buildThermal(thermal map[thermalKey]*thermalMetrics) []ThermalSnapshot {
+	keys := make([]thermalKey, 0, len(thermal))
+	for key, m := range thermal {
+		if m.hasReading {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].chip != keys[j].chip {
+			return keys[i].chip < keys[j].chip
+		}
+		// Numeric order (temp2 before temp10)
+		a, errA := strconv.Atoi(strings.TrimPrefix(keys[i].sensor, "temp"))
+		b, errB := strconv.Atoi(strings.TrimPrefix(keys[j].sensor, "temp"))
+		if errA != nil || errB != nil {
+			return keys[i].sensor < keys[j].sensor
+		}
+		return a < b
+	})
+
+	sensors := make([]ThermalSnapshot, 0, len(keys))
+	for _, key := range keys {
+		m := thermal[key]
+		sensors = append(sensors, ThermalSnapshot{
+			Chip:    key.chip,
+			Sensor:  key.sensor,
+			Label:   m.label,
+			Celsius: m.celsius,
+		})
+	}
+	return sensors
+}
+
+func selectPrimaryDisk(snapshot *NodeExporterMetricSnapshot, devices map[string]*diskMetrics, preferred string) {
+	// Priority: configured disk > vda > sda > nvme0n1 > first available
+	var name string
+	if preferred != "" && devices[preferred] != nil {
+		name = preferred
+	} else if devices["vda"] != nil {
+		name = "vda"
 	} else if devices["sda"] != nil {
-		primary = devices["sda"]
+		name = "sda"
 	} else if devices["nvme0n1"] != nil {
-		primary = devices["nvme0n1"]
+		name = "nvme0n1"
 	} else {
-		// Get first available
-		for _, metrics := range devices {
-			primary = metrics
-			break
+		// First by name, so the choice doesn't change between scrapes with the same disks
+		for device := range devices {
+			if name == "" || device < name {
+				name = device
+			}
 		}
 	}
 
-	if primary != nil {
+	if primary := devices[name]; primary != nil {
+		snapshot.DiskPrimaryDevice = name
 		snapshot.DiskReadsCompletedTotal = primary.readsCompleted
 		snapshot.DiskWritesCompletedTotal = primary.writesCompleted
 		snapshot.DiskReadBytesTotal = primary.readBytes
 		snapshot.DiskWrittenBytesTotal = primary.writtenBytes
 		snapshot.DiskIOTimeSecondsTotal = primary.ioTimeSeconds
+		snapshot.DiskReadTimeSecondsTotal = primary.readTimeSeconds
+		snapshot.DiskWriteTimeSecondsTotal = primary.writeTimeSeconds
+
+		// Average latency per op (avoid division by zero on idle disks)
+		if primary.readsCompleted > 0 {
+			snapshot.DiskReadLatencySeconds = primary.readTimeSeconds / float64(primary.readsCompleted)
+		}
+		if primary.writesCompleted > 0 {
+			snapshot.DiskWriteLatencySeconds = primary.writeTimeSeconds / float64(primary.writesCompleted)
+		}
 	}
 }
+
+// pressure returns the snapshot's pressure metrics, allocating them on first use
+// so the field stays nil when the exporter reports no PSI metrics
+func pressure(snapshot *NodeExporterMetricSnapshot) *PressureSnapshot {
+	if snapshot.Pressure == nil {
+		snapshot.Pressure = &PressureSnapshot{}
+	}
+	return snapshot.Pressure
+}
+
+// LowEntropyBits is the available entropy below which crypto operations may stall
+// Kernels since 5.18 always report 256, so only older kernels can drop below it
+const LowEntropyBits = 200
+
+// EntropyLow reports whether the snapshot's available entropy is below LowEntropyBits
+// (false when the entropy collector is disabled)
+func (s *NodeExporterMetricSnapshot) EntropyLow() bool {
+	// Pool size is always positive when the entropy collector is enabled
+	return s.EntropyPoolSizeBits > 0 && s.EntropyAvailableBits < LowEntropyBits
+}
@@ -2,7 +2,11 @@ package prometheus
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -251,3 +255,691 @@ func TestParseNodeExporterMetrics_InvalidInput(t *testing.T) {
 		t.Fatal("Snapshot should not be nil even for invalid input")
 	}
 }
+
+func TestParseNodeExporterMetrics_DiskLatency(t *testing.T) {
+	// Multiple devices: vda is primary, sdb is a secondary data disk, loop0 is ignored
+	input := `# HELP node_disk_reads_completed_total The total number of reads completed successfully.
+# TYPE node_disk_reads_completed_total counter
+node_disk_reads_completed_total{device="loop0"} 50
+node_disk_reads_completed_total{device="sdb"} 2000
+node_disk_reads_completed_total{device="vda"} 1000
+# HELP node_disk_writes_completed_total The total number of writes completed successfully.
+# TYPE node_disk_writes_completed_total counter
+node_disk_writes_completed_total{device="loop0"} 0
+node_disk_writes_completed_total{device="sdb"} 4000
+node_disk_writes_completed_total{device="vda"} 500
+# HELP node_disk_read_time_seconds_total The total number of seconds spent by all reads.
+# TYPE node_disk_read_time_seconds_total counter
+node_disk_read_time_seconds_total{device="loop0"} 1
+node_disk_read_time_seconds_total{device="sdb"} 10
+node_disk_read_time_seconds_total{device="vda"} 2.5
+# HELP node_disk_write_time_seconds_total This is the total number of seconds spent by all writes.
+# TYPE node_disk_write_time_seconds_total counter
+node_disk_write_time_seconds_total{device="loop0"} 0
+node_disk_write_time_seconds_total{device="sdb"} 40
+node_disk_write_time_seconds_total{device="vda"} 5
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.DiskReadTimeSecondsTotal != 2.5 {
+		t.Errorf("DiskReadTimeSecondsTotal = %v, want 2.5 (from vda)", snapshot.DiskReadTimeSecondsTotal)
+	}
+	if snapshot.DiskWriteTimeSecondsTotal != 5 {
+		t.Errorf("DiskWriteTimeSecondsTotal = %v, want 5 (from vda)", snapshot.DiskWriteTimeSecondsTotal)
+	}
+
+	// 2.5s / 1000 reads = 2.5ms per read
+	if snapshot.DiskReadLatencySeconds != 0.0025 {
+		t.Errorf("DiskReadLatencySeconds = %v, want 0.0025", snapshot.DiskReadLatencySeconds)
+	}
+	// 5s / 500 writes = 10ms per write
+	if snapshot.DiskWriteLatencySeconds != 0.01 {
+		t.Errorf("DiskWriteLatencySeconds = %v, want 0.01", snapshot.DiskWriteLatencySeconds)
+	}
+}
+
+func TestParseNodeExporterMetrics_DiskLatencyIdleDisk(t *testing.T) {
+	input := `node_disk_reads_completed_total{device="vda"} 0
+node_disk_writes_completed_total{device="vda"} 0
+node_disk_read_time_seconds_total{device="vda"} 0
+node_disk_write_time_seconds_total{device="vda"} 0
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.DiskReadLatencySeconds != 0 || snapshot.DiskWriteLatencySeconds != 0 {
+		t.Errorf("Expected zero latency for idle disk, got read=%v write=%v",
+			snapshot.DiskReadLatencySeconds, snapshot.DiskWriteLatencySeconds)
+	}
+}
+
+func TestParseNodeExporterMetrics_NetworkInterfaces(t *testing.T) {
+	input := `node_network_receive_bytes_total{device="lo"} 999999
+node_network_receive_bytes_total{device="docker0"} 5000
+node_network_receive_bytes_total{device="veth1a2b3c"} 4000
+node_network_receive_bytes_total{device="eth1"} 2000
+node_network_receive_bytes_total{device="eth0"} 1000
+node_network_transmit_bytes_total{device="eth1"} 2200
+node_network_transmit_bytes_total{device="eth0"} 1100
+node_network_receive_packets_total{device="eth0"} 10
+node_network_receive_packets_total{device="eth1"} 20
+node_network_transmit_errs_total{device="eth1"} 3
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	// Loopback, docker, and veth interfaces are filtered out
+	if len(snapshot.NetworkInterfaces) != 2 {
+		t.Fatalf("Expected 2 interfaces, got %d: %+v", len(snapshot.NetworkInterfaces), snapshot.NetworkInterfaces)
+	}
+
+	eth0 := snapshot.NetworkInterfaces[0]
+	eth1 := snapshot.NetworkInterfaces[1]
+	if eth0.Device != "eth0" || eth1.Device != "eth1" {
+		t.Fatalf("Expected interfaces sorted by device name, got %s, %s", eth0.Device, eth1.Device)
+	}
+
+	if eth0.ReceiveBytesTotal != 1000 || eth0.TransmitBytesTotal != 1100 || eth0.ReceivePacketsTotal != 10 {
+		t.Errorf("Unexpected eth0 counters: %+v", eth0)
+	}
+	if eth1.ReceiveBytesTotal != 2000 || eth1.TransmitBytesTotal != 2200 || eth1.TransmitErrsTotal != 3 {
+		t.Errorf("Unexpected eth1 counters: %+v", eth1)
+	}
+
+	// Aggregate fields still report the primary interface
+	if snapshot.NetworkReceiveBytesTotal != 1000 {
+		t.Errorf("NetworkReceiveBytesTotal = %d, want 1000 (eth0)", snapshot.NetworkReceiveBytesTotal)
+	}
+
+	jsonData, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to marshal to JSON: %v", err)
+	}
+	if !strings.Contains(string(jsonData), `"network_interfaces":[{"device":"eth0"`) {
+		t.Errorf("Expected network_interfaces array in JSON, got: %s", jsonData)
+	}
+}
+
+func TestParseNodeExporterMetricsWithOptions_PrimaryDevices(t *testing.T) {
+	input := `node_network_receive_bytes_total{device="eth0"} 1000
+node_network_receive_bytes_total{device="ens5"} 2000
+node_disk_reads_completed_total{device="sda"} 10
+node_disk_reads_completed_total{device="nvme1n1"} 20
+node_disk_reads_completed_total{device="dm-0"} 30
+node_network_receive_bytes_total{device="docker0"} 3000
+`
+
+	tests := []struct {
+		name        string
+		opts        ParseOptions
+		wantNetwork string
+		wantRxBytes int64
+		wantDisk    string
+		wantReads   int64
+	}{
+		{"defaults", ParseOptions{}, "eth0", 1000, "sda", 10},
+		{"configured devices", ParseOptions{PrimaryDisk: "nvme1n1", PrimaryNetwork: "ens5"}, "ens5", 2000, "nvme1n1", 20},
+		{"configured devices missing", ParseOptions{PrimaryDisk: "vdb", PrimaryNetwork: "bond0"}, "eth0", 1000, "sda", 10},
+		// Outside the built-in filters (disk prefixes, virtual interfaces)
+		{"configured devices outside the filters", ParseOptions{PrimaryDisk: "dm-0", PrimaryNetwork: "docker0"}, "docker0", 3000, "dm-0", 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := ParseNodeExporterMetricsWithOptions([]byte(input), tt.opts)
+			if err != nil {
+				t.Fatalf("ParseNodeExporterMetricsWithOptions failed: %v", err)
+			}
+			if snapshot.NetworkPrimaryInterface != tt.wantNetwork || snapshot.NetworkReceiveBytesTotal != tt.wantRxBytes {
+				t.Errorf("Primary interface = %s (%d bytes), want %s (%d bytes)",
+					snapshot.NetworkPrimaryInterface, snapshot.NetworkReceiveBytesTotal, tt.wantNetwork, tt.wantRxBytes)
+			}
+			if snapshot.DiskPrimaryDevice != tt.wantDisk || snapshot.DiskReadsCompletedTotal != tt.wantReads {
+				t.Errorf("Primary disk = %s (%d reads), want %s (%d reads)",
+					snapshot.DiskPrimaryDevice, snapshot.DiskReadsCompletedTotal, tt.wantDisk, tt.wantReads)
+			}
+		})
+	}
+
+	// Other devices outside the filters are still dropped
+	snapshot, _ := ParseNodeExporterMetricsWithOptions([]byte(input), ParseOptions{PrimaryDisk: "dm-0"})
+	for _, iface := range snapshot.NetworkInterfaces {
+		if iface.Device == "docker0" {
+			t.Error("Expected docker0 to be filtered when it isn't the configured primary")
+		}
+	}
+}
+
+func TestParseNodeExporterMetricsWithOptions_DeviceFilters(t *testing.T) {
+	input := `node_network_receive_bytes_total{device="eth0"} 1000
+node_network_receive_bytes_total{device="eth1"} 2000
+node_network_receive_bytes_total{device="docker0"} 3000
+node_disk_reads_completed_total{device="xvda"} 10
+node_disk_reads_completed_total{device="sdb"} 20
+`
+
+	snapshot, err := ParseNodeExporterMetricsWithOptions([]byte(input), ParseOptions{
+		DiskInclude:    regexp.MustCompile(`^xvd`),
+		NetworkExclude: regexp.MustCompile(`^eth0$`),
+	})
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetricsWithOptions failed: %v", err)
+	}
+
+	// The exclude regex applies on top of the built-in filter (docker0 stays filtered out)
+	if len(snapshot.NetworkInterfaces) != 1 || snapshot.NetworkInterfaces[0].Device != "eth1" {
+		t.Errorf("Expected only eth1, got %+v", snapshot.NetworkInterfaces)
+	}
+	if snapshot.NetworkPrimaryInterface != "eth1" {
+		t.Errorf("NetworkPrimaryInterface = %s, want eth1", snapshot.NetworkPrimaryInterface)
+	}
+
+	// The include regex replaces the built-in filter, which ignores xvd devices
+	if snapshot.DiskReadsCompletedTotal != 10 {
+		t.Errorf("DiskReadsCompletedTotal = %d, want 10 (xvda)", snapshot.DiskReadsCompletedTotal)
+	}
+}
+
+func TestParseNodeExporterMetrics_Topology(t *testing.T) {
+	// Two sockets, two physical cores each (hyperthreaded: 8 logical CPUs), two NUMA nodes
+	var sb strings.Builder
+	for cpu := 0; cpu < 8; cpu++ {
+		fmt.Fprintf(&sb, "node_cpu_seconds_total{cpu=\"%d\",mode=\"idle\"} 100\n", cpu)
+	}
+	sb.WriteString(`node_cpu_package_throttles_total{package="0"} 0
+node_cpu_package_throttles_total{package="1"} 0
+node_cpu_core_throttles_total{core="0",package="0"} 0
+node_cpu_core_throttles_total{core="1",package="0"} 0
+node_cpu_core_throttles_total{core="0",package="1"} 0
+node_cpu_core_throttles_total{core="1",package="1"} 0
+node_memory_numa_MemTotal{node="1"} 8.589934592e+09
+node_memory_numa_MemFree{node="1"} 2.147483648e+09
+node_memory_numa_MemUsed{node="1"} 6.442450944e+09
+node_memory_numa_MemTotal{node="0"} 8.589934592e+09
+node_memory_numa_MemFree{node="0"} 4.294967296e+09
+node_memory_numa_MemUsed{node="0"} 4.294967296e+09
+`)
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(sb.String()))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.CPUCores != 8 {
+		t.Errorf("CPUCores = %d, want 8 (logical CPUs)", snapshot.CPUCores)
+	}
+	if snapshot.CPUSockets != 2 {
+		t.Errorf("CPUSockets = %d, want 2", snapshot.CPUSockets)
+	}
+	if snapshot.CPUPhysicalCores != 4 {
+		t.Errorf("CPUPhysicalCores = %d, want 4", snapshot.CPUPhysicalCores)
+	}
+
+	if len(snapshot.NUMANodes) != 2 {
+		t.Fatalf("Expected 2 NUMA nodes, got %d: %+v", len(snapshot.NUMANodes), snapshot.NUMANodes)
+	}
+	node0, node1 := snapshot.NUMANodes[0], snapshot.NUMANodes[1]
+	if node0.Node != "0" || node1.Node != "1" {
+		t.Fatalf("Expected NUMA nodes sorted by ID, got %s, %s", node0.Node, node1.Node)
+	}
+	if node0.MemoryTotalBytes != 8589934592 || node0.MemoryFreeBytes != 4294967296 || node0.MemoryUsedBytes != 4294967296 {
+		t.Errorf("Unexpected node0 memory: %+v", node0)
+	}
+	if node1.MemoryFreeBytes != 2147483648 || node1.MemoryUsedBytes != 6442450944 {
+		t.Errorf("Unexpected node1 memory: %+v", node1)
+	}
+}
+
+func TestParseNodeExporterMetrics_TopologySingleNode(t *testing.T) {
+	input := `node_cpu_seconds_total{cpu="0",mode="idle"} 100
+node_cpu_seconds_total{cpu="1",mode="idle"} 100
+node_cpu_package_throttles_total{package="0"} 0
+node_cpu_core_throttles_total{core="0",package="0"} 0
+node_cpu_core_throttles_total{core="1",package="0"} 0
+node_memory_numa_MemTotal{node="0"} 4.294967296e+09
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.CPUSockets != 1 || snapshot.CPUPhysicalCores != 2 {
+		t.Errorf("Expected 1 socket with 2 cores, got %d sockets, %d cores", snapshot.CPUSockets, snapshot.CPUPhysicalCores)
+	}
+	if len(snapshot.NUMANodes) != 1 || snapshot.NUMANodes[0].MemoryTotalBytes != 4294967296 {
+		t.Errorf("Expected a single NUMA node with 4GiB, got %+v", snapshot.NUMANodes)
+	}
+
+	// Without topology collectors (e.g. VMs), fields stay empty
+	snapshot, err = ParseNodeExporterMetrics([]byte(`node_cpu_seconds_total{cpu="0",mode="idle"} 100` + "\n"))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if snapshot.CPUSockets != 0 || snapshot.CPUPhysicalCores != 0 || len(snapshot.NUMANodes) != 0 {
+		t.Errorf("Expected empty topology, got sockets=%d cores=%d numa=%+v",
+			snapshot.CPUSockets, snapshot.CPUPhysicalCores, snapshot.NUMANodes)
+	}
+}
+
+func TestParseNodeExporterMetrics_MissingMemAvailable(t *testing.T) {
+	// Kernel < 3.14: meminfo has no MemAvailable
+	input := `node_memory_MemTotal_bytes 4.294967296e+09
+node_memory_MemFree_bytes 1.073741824e+09
+node_memory_Buffers_bytes 2.68435456e+08
+node_memory_Cached_bytes 5.36870912e+08
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	// 1GiB free + 256MiB buffers + 512MiB cached
+	wantAvailable := int64(1073741824 + 268435456 + 536870912)
+	if snapshot.MemoryAvailableBytes != wantAvailable {
+		t.Errorf("MemoryAvailableBytes = %d, want %d (estimated)", snapshot.MemoryAvailableBytes, wantAvailable)
+	}
+	if !snapshot.MemoryAvailableEstimated {
+		t.Error("Expected MemoryAvailableEstimated to be set")
+	}
+
+	used := snapshot.MemoryTotalBytes - snapshot.MemoryAvailableBytes
+	if used != 4294967296-wantAvailable {
+		t.Errorf("Used memory = %d, want %d", used, 4294967296-wantAvailable)
+	}
+}
+
+func TestParseNodeExporterMetrics_MemAvailableReported(t *testing.T) {
+	input := `node_memory_MemTotal_bytes 4.294967296e+09
+node_memory_MemAvailable_bytes 2.147483648e+09
+node_memory_MemFree_bytes 1.073741824e+09
+node_memory_Cached_bytes 5.36870912e+08
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.MemoryAvailableBytes != 2147483648 {
+		t.Errorf("MemoryAvailableBytes = %d, want 2147483648 (reported value, not estimate)", snapshot.MemoryAvailableBytes)
+	}
+	if snapshot.MemoryAvailableEstimated {
+		t.Error("Expected MemoryAvailableEstimated to be unset for a reported value")
+	}
+}
+
+func TestParseNodeExporterMetrics_Entropy(t *testing.T) {
+	input := `# HELP node_entropy_available_bits Bits of available entropy.
+# TYPE node_entropy_available_bits gauge
+node_entropy_available_bits 3527
+# HELP node_entropy_pool_size_bits Bits of entropy pool.
+# TYPE node_entropy_pool_size_bits gauge
+node_entropy_pool_size_bits 4096
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.EntropyAvailableBits != 3527 {
+		t.Errorf("EntropyAvailableBits = %d, want 3527", snapshot.EntropyAvailableBits)
+	}
+	if snapshot.EntropyPoolSizeBits != 4096 {
+		t.Errorf("EntropyPoolSizeBits = %d, want 4096", snapshot.EntropyPoolSizeBits)
+	}
+}
+
+func TestEntropyLow(t *testing.T) {
+	tests := []struct {
+		name      string
+		available int64
+		poolSize  int64
+		wantLow   bool
+	}{
+		{"collector disabled", 0, 0, false},
+		{"legacy kernel, plenty", 3527, 4096, false},
+		{"legacy kernel, critically low", 42, 4096, true},
+		{"modern kernel, fixed value", 256, 256, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &NodeExporterMetricSnapshot{EntropyAvailableBits: tt.available, EntropyPoolSizeBits: tt.poolSize}
+			if got := snapshot.EntropyLow(); got != tt.wantLow {
+				t.Errorf("EntropyLow() = %v, want %v", got, tt.wantLow)
+			}
+		})
+	}
+}
+
+func TestParseNodeExporterMetrics_Pressure(t *testing.T) {
+	// node_exporter pressure collector output (from /proc/pressure/{cpu,memory,io})
+	input := `# HELP node_pressure_cpu_waiting_seconds_total Total time in seconds that processes have waited for CPU time
+# TYPE node_pressure_cpu_waiting_seconds_total counter
+node_pressure_cpu_waiting_seconds_total 1234.567891
+# HELP node_pressure_io_stalled_seconds_total Total time in seconds no process could make progress due to IO congestion
+# TYPE node_pressure_io_stalled_seconds_total counter
+node_pressure_io_stalled_seconds_total 45.678
+# HELP node_pressure_io_waiting_seconds_total Total time in seconds that processes have waited due to IO congestion
+# TYPE node_pressure_io_waiting_seconds_total counter
+node_pressure_io_waiting_seconds_total 56.789
+# HELP node_pressure_memory_stalled_seconds_total Total time in seconds no process could make progress due to memory congestion
+# TYPE node_pressure_memory_stalled_seconds_total counter
+node_pressure_memory_stalled_seconds_total 0.123
+# HELP node_pressure_memory_waiting_seconds_total Total time in seconds that processes have waited for memory
+# TYPE node_pressure_memory_waiting_seconds_total counter
+node_pressure_memory_waiting_seconds_total 0.456
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if snapshot.Pressure == nil {
+		t.Fatal("Expected pressure metrics")
+	}
+
+	want := PressureSnapshot{
+		CPUWaitingSecondsTotal:    1234.567891,
+		MemoryWaitingSecondsTotal: 0.456,
+		MemoryStalledSecondsTotal: 0.123,
+		IOWaitingSecondsTotal:     56.789,
+		IOStalledSecondsTotal:     45.678,
+	}
+	if *snapshot.Pressure != want {
+		t.Errorf("Pressure = %+v, want %+v", *snapshot.Pressure, want)
+	}
+}
+
+func TestParseNodeExporterMetrics_NoPressure(t *testing.T) {
+	// Kernels before 4.20 (or without CONFIG_PSI) have no /proc/pressure
+	snapshot, err := ParseNodeExporterMetrics([]byte("node_load1 0.5\n"))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if snapshot.Pressure != nil {
+		t.Errorf("Expected nil pressure, got %+v", *snapshot.Pressure)
+	}
+}
+
+func TestParseNodeExporterMetrics_CPUPerCore(t *testing.T) {
+	input := `node_cpu_seconds_total{cpu="0",mode="idle"} 1000
+node_cpu_seconds_total{cpu="0",mode="user"} 200
+node_cpu_seconds_total{cpu="0",mode="system"} 50
+node_cpu_seconds_total{cpu="0",mode="iowait"} 5
+node_cpu_seconds_total{cpu="0",mode="steal"} 1
+node_cpu_seconds_total{cpu="10",mode="idle"} 1100
+node_cpu_seconds_total{cpu="10",mode="user"} 10
+node_cpu_seconds_total{cpu="2",mode="idle"} 500
+node_cpu_seconds_total{cpu="2",mode="user"} 700
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	want := []CPUCoreSnapshot{
+		{CPU: "0", IdleSeconds: 1000, UserSeconds: 200, SystemSeconds: 50, IowaitSeconds: 5, StealSeconds: 1},
+		{CPU: "2", IdleSeconds: 500, UserSeconds: 700},
+		{CPU: "10", IdleSeconds: 1100, UserSeconds: 10},
+	}
+	if len(snapshot.CPUPerCore) != len(want) {
+		t.Fatalf("Expected %d cores, got %+v", len(want), snapshot.CPUPerCore)
+	}
+	for i, core := range snapshot.CPUPerCore {
+		if core != want[i] {
+			t.Errorf("CPUPerCore[%d] = %+v, want %+v", i, core, want[i])
+		}
+	}
+
+	// Aggregates are unchanged
+	if snapshot.CPUCores != 3 || snapshot.CPUUserSeconds != 910 {
+		t.Errorf("Expected 3 cores and 910 user seconds, got %d and %v", snapshot.CPUCores, snapshot.CPUUserSeconds)
+	}
+}
+
+func TestParseNodeExporterMetrics_Thermal(t *testing.T) {
+	input := `node_hwmon_chip_names{chip="platform_coretemp_0",chip_name="coretemp"} 1
+node_hwmon_sensor_label{chip="platform_coretemp_0",label="Core 0",sensor="temp2"} 1
+node_hwmon_sensor_label{chip="platform_coretemp_0",label="Package id 0",sensor="temp1"} 1
+node_hwmon_sensor_label{chip="platform_coretemp_0",label="Core 8",sensor="temp10"} 1
+node_hwmon_temp_celsius{chip="platform_coretemp_0",sensor="temp1"} 55
+node_hwmon_temp_celsius{chip="platform_coretemp_0",sensor="temp10"} 49
+node_hwmon_temp_celsius{chip="platform_coretemp_0",sensor="temp2"} 52.5
+node_hwmon_temp_celsius{chip="nvme_nvme0",sensor="temp1"} 38.85
+node_hwmon_sensor_label{chip="acpitz",label="unused",sensor="temp9"} 1
+node_load1 0.5
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	want := []ThermalSnapshot{
+		{Chip: "nvme_nvme0", Sensor: "temp1", Celsius: 38.85},
+		{Chip: "platform_coretemp_0", Sensor: "temp1", Label: "Package id 0", Celsius: 55},
+		{Chip: "platform_coretemp_0", Sensor: "temp2", Label: "Core 0", Celsius: 52.5},
+		{Chip: "platform_coretemp_0", Sensor: "temp10", Label: "Core 8", Celsius: 49},
+	}
+	if len(snapshot.Thermal) != len(want) {
+		t.Fatalf("Expected %d sensors, got %+v", len(want), snapshot.Thermal)
+	}
+	for i, sensor := range snapshot.Thermal {
+		if sensor != want[i] {
+			t.Errorf("Thermal[%d] = %+v, want %+v", i, sensor, want[i])
+		}
+	}
+
+	// Other metrics still parse alongside labels containing spaces
+	if snapshot.Load1Min != 0.5 {
+		t.Errorf("Expected load1 0.5, got %v", snapshot.Load1Min)
+	}
+}
+
+func TestParseNodeExporterMetrics_NoHwmon(t *testing.T) {
+	snapshot, err := ParseNodeExporterMetrics([]byte("node_load1 0.5\n"))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if len(snapshot.Thermal) != 0 {
+		t.Errorf("Expected no thermal sensors, got %+v", snapshot.Thermal)
+	}
+}
+
+func TestParseNodeExporterMetrics_FileDescriptorsAndTCP(t *testing.T) {
+	input := `node_filefd_allocated 2848
+node_filefd_maximum 9.223372036854776e+18
+node_tcp_connection_states{state="established"} 37
+node_tcp_connection_states{state="time_wait"} 112
+node_tcp_connection_states{state="listen"} 9
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.FileFDAllocated != 2848 {
+		t.Errorf("Expected 2848 allocated fds, got %d", snapshot.FileFDAllocated)
+	}
+	// LLONG_MAX (the 64-bit default) doesn't round-trip through float64, it must not overflow
+	if snapshot.FileFDMaximum != math.MaxInt64 {
+		t.Errorf("Expected fd maximum %d, got %d", int64(math.MaxInt64), snapshot.FileFDMaximum)
+	}
+
+	want := map[string]int64{"ESTABLISHED": 37, "TIME_WAIT": 112, "LISTEN": 9}
+	if len(snapshot.TCPConnectionStates) != len(want) {
+		t.Fatalf("Expected %d TCP states, got %v", len(want), snapshot.TCPConnectionStates)
+	}
+	for state, n := range want {
+		if got := snapshot.TCPConnectionStates[state]; got != n {
+			t.Errorf("TCP state %s: expected %d, got %d", state, n, got)
+		}
+	}
+
+	// Without the tcpstat collector the map stays nil (omitted from JSON)
+	snapshot, err = ParseNodeExporterMetrics([]byte("node_filefd_allocated 10\n"))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if snapshot.TCPConnectionStates != nil {
+		t.Errorf("Expected nil TCP states, got %v", snapshot.TCPConnectionStates)
+	}
+}
+
+func TestParseNodeExporterMetrics_OOMKills(t *testing.T) {
+	input := `node_vmstat_oom_kill 5
+# HELP nodepulse_oom_kill Process killed by the OOM killer since the previous scrape.
+# TYPE nodepulse_oom_kill gauge
+nodepulse_oom_kill{pid="1234",process="java"} 1 1730102400000
+nodepulse_oom_kill{pid="98765",process="postgres: wal"} 1 1730102400000
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	if snapshot.OOMKillsTotal != 5 {
+		t.Errorf("Expected 5 OOM kills since boot, got %d", snapshot.OOMKillsTotal)
+	}
+	want := []OOMKillSnapshot{{PID: 1234, Process: "java"}, {PID: 98765, Process: "postgres: wal"}}
+	if snapshot.OOMKillCount != len(want) || len(snapshot.OOMKills) != len(want) {
+		t.Fatalf("Expected %d OOM kills, got %d: %+v", len(want), snapshot.OOMKillCount, snapshot.OOMKills)
+	}
+	for i, kill := range snapshot.OOMKills {
+		if kill != want[i] {
+			t.Errorf("OOMKills[%d] = %+v, want %+v", i, kill, want[i])
+		}
+	}
+}
+
+func TestParseNodeExporterMetrics_Filesystems(t *testing.T) {
+	input := `node_filesystem_size_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 5e+10
+node_filesystem_free_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 2e+10
+node_filesystem_avail_bytes{device="/dev/sda1",fstype="ext4",mountpoint="/"} 1.8e+10
+node_filesystem_size_bytes{device="/dev/sdb1",fstype="xfs",mountpoint="/data"} 1e+12
+node_filesystem_free_bytes{device="/dev/sdb1",fstype="xfs",mountpoint="/data"} 1e+11
+node_filesystem_avail_bytes{device="/dev/sdb1",fstype="xfs",mountpoint="/data"} 1e+11
+node_filesystem_size_bytes{device="/dev/sda2",fstype="ext4",mountpoint="/var"} 2e+10
+node_filesystem_avail_bytes{device="/dev/sda2",fstype="ext4",mountpoint="/var"} 1e+09
+node_filesystem_size_bytes{device="tmpfs",fstype="tmpfs",mountpoint="/run"} 1e+08
+node_filesystem_avail_bytes{device="tmpfs",fstype="tmpfs",mountpoint="/run"} 1e+08
+`
+
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	want := []FilesystemSnapshot{
+		{Mountpoint: "/", Device: "/dev/sda1", FSType: "ext4", SizeBytes: 5e10, FreeBytes: 2e10, AvailableBytes: 1.8e10},
+		{Mountpoint: "/data", Device: "/dev/sdb1", FSType: "xfs", SizeBytes: 1e12, FreeBytes: 1e11, AvailableBytes: 1e11},
+		{Mountpoint: "/var", Device: "/dev/sda2", FSType: "ext4", SizeBytes: 2e10, AvailableBytes: 1e9},
+	}
+	if len(snapshot.Filesystems) != len(want) {
+		t.Fatalf("Expected %d filesystems, got %+v", len(want), snapshot.Filesystems)
+	}
+	for i, fs := range snapshot.Filesystems {
+		if fs != want[i] {
+			t.Errorf("Filesystems[%d] = %+v, want %+v", i, fs, want[i])
+		}
+	}
+
+	// Root-only fields are unchanged
+	if snapshot.DiskTotalBytes != 5e10 || snapshot.DiskAvailableBytes != 1.8e10 {
+		t.Errorf("Expected root disk 5e10/1.8e10, got %d/%d", snapshot.DiskTotalBytes, snapshot.DiskAvailableBytes)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels string
+		want   map[string]string
+	}{
+		{"simple", `cpu="0",mode="idle"`, map[string]string{"cpu": "0", "mode": "idle"}},
+		{"empty", ``, map[string]string{}},
+		{"trailing comma", `device="eth0",`, map[string]string{"device": "eth0"}},
+		{"spaces around pairs", ` cpu = "0" , mode="idle"`, map[string]string{"cpu": "0", "mode": "idle"}},
+		{"comma in value", `mountpoint="/mnt/a,b",fstype="ext4"`, map[string]string{"mountpoint": "/mnt/a,b", "fstype": "ext4"}},
+		{"escaped quotes", `path="he said \"hi\"",x="1"`, map[string]string{"path": `he said "hi"`, "x": "1"}},
+		{"equals in value", `query="a=b,c=d"`, map[string]string{"query": "a=b,c=d"}},
+		{"escaped backslash and newline", `path="C:\\dir",msg="a\nb"`, map[string]string{"path": `C:\dir`, "msg": "a\nb"}},
+		{"empty value", `label="",sensor="temp1"`, map[string]string{"label": "", "sensor": "temp1"}},
+		{"unterminated quote keeps earlier labels", `cpu="0",mode="idle`, map[string]string{"cpu": "0"}},
+		{"unquoted value keeps earlier labels", `cpu="0",mode=idle`, map[string]string{"cpu": "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLabels(tt.labels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseLabels(%q) = %v, want %v", tt.labels, got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("parseLabels(%q)[%q] = %q, want %q", tt.labels, k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseNodeExporterMetrics_LabelWithComma(t *testing.T) {
+	input := `node_filesystem_size_bytes{device="/dev/sdb1",fstype="ext4",mountpoint="/mnt/a,b"} 1000
+node_filesystem_avail_bytes{device="/dev/sdb1",fstype="ext4",mountpoint="/mnt/a,b"} 400
+`
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+	if len(snapshot.Filesystems) != 1 || snapshot.Filesystems[0].Mountpoint != "/mnt/a,b" {
+		t.Errorf("Expected one filesystem mounted at /mnt/a,b, got %+v", snapshot.Filesystems)
+	}
+}
+
+func TestParseNodeExporterMetrics_NonFiniteValues(t *testing.T) {
+	input := `node_memory_MemTotal_bytes 8e+09
+node_memory_MemTotal_bytes NaN
+node_memory_MemAvailable_bytes +Inf
+node_load1 NaN
+node_load5 -Inf
+node_load15 1.5e-01
+`
+	snapshot, err := ParseNodeExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseNodeExporterMetrics failed: %v", err)
+	}
+
+	// A non-finite sample leaves the field at its previous value
+	if snapshot.MemoryTotalBytes != 8e9 {
+		t.Errorf("Expected MemoryTotalBytes to keep 8e9, got %d", snapshot.MemoryTotalBytes)
+	}
+	if snapshot.MemoryAvailableBytes != 0 {
+		t.Errorf("Expected +Inf MemAvailable to be treated as absent, got %d", snapshot.MemoryAvailableBytes)
+	}
+	if snapshot.Load1Min != 0 || snapshot.Load5Min != 0 || snapshot.Load15Min != 0.15 {
+		t.Errorf("Expected load 0/0/0.15, got %v/%v/%v", snapshot.Load1Min, snapshot.Load5Min, snapshot.Load15Min)
+	}
+
+	// The snapshot must still encode (JSON has no NaN or Inf)
+	if _, err := json.Marshal(snapshot); err != nil {
+		t.Errorf("Failed to marshal snapshot: %v", err)
+	}
+}
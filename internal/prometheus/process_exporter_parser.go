@@ -1,9 +1,8 @@
 package prometheus
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -35,7 +34,7 @@ type processData struct {
 // - namedprocess_namegroup_memory_bytes{groupname="nginx",memtype="resident"} 104857600
 func ParseProcessExporterMetrics(data []byte) ([]ProcessExporterMetricSnapshot, error) {
 	timestamp := time.Now().UTC()
-	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner := newLineScanner(data)
 
 	// Track metrics per process group (groupname)
 	processMetrics := make(map[string]*processData)
@@ -77,15 +76,31 @@ func ParseProcessExporterMetrics(data []byte) ([]ProcessExporterMetricSnapshot,
 	return snapshots, nil
 }
 
-func parseProcessLine(line string, processMetrics map[string]*processData) error {
-	// Split metric name and value
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid line format")
+// LimitProcessSnapshots keeps only the heaviest process groups by resident memory
+// Used to cap payload size on hosts with many process groups
+// limit <= 0 means no limit; ties are broken by name for stable output
+func LimitProcessSnapshots(snapshots []ProcessExporterMetricSnapshot, limit int) []ProcessExporterMetricSnapshot {
+	if limit <= 0 || len(snapshots) <= limit {
+		return snapshots
 	}
 
-	metricPart := parts[0]
-	valuePart := parts[1]
+	sorted := make([]ProcessExporterMetricSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].MemoryBytes != sorted[j].MemoryBytes {
+			return sorted[i].MemoryBytes > sorted[j].MemoryBytes
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted[:limit]
+}
+
+func parseProcessLine(line string, processMetrics map[string]*processData) error {
+	metricPart, valuePart, err := splitSample(line)
+	if err != nil {
+		return err
+	}
 
 	// Extract metric name and labels
 	var metricName string
@@ -104,6 +119,9 @@ func parseProcessLine(line string, processMetrics map[string]*processData) error
 	if err != nil {
 		return err
 	}
+	if !isFinite(value) {
+		return nil // Treated as absent, like in the node_exporter parser
+	}
 
 	// Extract groupname (process name)
 	groupname, ok := labels["groupname"]
@@ -139,5 +157,5 @@ func parseProcessLine(line string, processMetrics map[string]*processData) error
 	return nil
 }
 
-// Note: parseLabels() and parseValue() are already defined in node_exporter_parser.go
+// Note: parseLabels(), parseValue(), and isFinite() are already defined in node_exporter_parser.go
 // They are package-level functions shared across all parsers in the prometheus package
@@ -137,3 +137,85 @@ namedprocess_namegroup_cpu_seconds_total{groupname="dead",mode="user"} 200
 		t.Fatalf("Expected 0 snapshots (filtered), got %d", len(snapshots))
 	}
 }
+
+func TestLimitProcessSnapshots(t *testing.T) {
+	snapshots := []ProcessExporterMetricSnapshot{
+		{Name: "sshd", MemoryBytes: 5 * 1024 * 1024},
+		{Name: "postgres", MemoryBytes: 512 * 1024 * 1024},
+		{Name: "cron", MemoryBytes: 1 * 1024 * 1024},
+		{Name: "nginx", MemoryBytes: 100 * 1024 * 1024},
+		{Name: "java", MemoryBytes: 2048 * 1024 * 1024},
+	}
+
+	limited := LimitProcessSnapshots(snapshots, 3)
+	if len(limited) != 3 {
+		t.Fatalf("Expected 3 snapshots, got %d", len(limited))
+	}
+
+	// Heaviest processes by RSS, in descending order
+	want := []string{"java", "postgres", "nginx"}
+	for i, name := range want {
+		if limited[i].Name != name {
+			t.Errorf("limited[%d] = %s, want %s", i, limited[i].Name, name)
+		}
+	}
+
+	// Input slice must not be reordered
+	if snapshots[0].Name != "sshd" {
+		t.Error("LimitProcessSnapshots should not modify the input slice")
+	}
+}
+
+func TestLimitProcessSnapshots_NoLimit(t *testing.T) {
+	snapshots := []ProcessExporterMetricSnapshot{
+		{Name: "nginx", MemoryBytes: 100},
+		{Name: "postgres", MemoryBytes: 200},
+	}
+
+	for _, limit := range []int{0, -1, 2, 10} {
+		if got := LimitProcessSnapshots(snapshots, limit); len(got) != 2 {
+			t.Errorf("limit %d: expected 2 snapshots, got %d", limit, len(got))
+		}
+	}
+}
+
+func TestParseProcessExporterMetrics_NonFiniteValues(t *testing.T) {
+	input := `namedprocess_namegroup_num_procs{groupname="nginx"} 2
+namedprocess_namegroup_cpu_seconds_total{groupname="nginx",mode="user"} 10
+namedprocess_namegroup_cpu_seconds_total{groupname="nginx",mode="system"} NaN
+namedprocess_namegroup_memory_bytes{groupname="nginx",memtype="resident"} +Inf
+`
+	snapshots, err := ParseProcessExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseProcessExporterMetrics failed: %v", err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("Expected 1 snapshot, got %d", len(snapshots))
+	}
+	if snapshots[0].CPUSecondsTotal != 10 || snapshots[0].MemoryBytes != 0 {
+		t.Errorf("Expected NaN/+Inf samples to be ignored, got %+v", snapshots[0])
+	}
+}
+
+func TestParseProcessExporterMetrics_GroupnameWithSpaces(t *testing.T) {
+	input := `namedprocess_namegroup_num_procs{groupname="Web Content"} 4
+namedprocess_namegroup_memory_bytes{groupname="Web Content",memtype="resident"} 1024
+namedprocess_namegroup_num_procs{groupname="he said \"hi\""} 1
+namedprocess_namegroup_cpu_seconds_total{groupname="he said \"hi\"",mode="user"} 2.5 1700000000000
+`
+	snapshots, err := ParseProcessExporterMetrics([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseProcessExporterMetrics failed: %v", err)
+	}
+
+	byName := make(map[string]ProcessExporterMetricSnapshot)
+	for _, s := range snapshots {
+		byName[s.Name] = s
+	}
+	if web := byName["Web Content"]; web.NumProcs != 4 || web.MemoryBytes != 1024 {
+		t.Errorf("Expected the Web Content group with 4 processes and 1024 bytes, got %+v", snapshots)
+	}
+	if quoted := byName[`he said "hi"`]; quoted.NumProcs != 1 || quoted.CPUSecondsTotal != 2.5 {
+		t.Errorf("Expected the group with escaped quotes, got %+v", snapshots)
+	}
+}
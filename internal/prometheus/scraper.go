@@ -1,7 +1,6 @@
 package prometheus
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
@@ -72,14 +71,36 @@ func (s *Scraper) Verify() error {
 	return nil
 }
 
+// Timestamp units for AddTimestamps
+const (
+	TimestampMillis  = "ms" // Prometheus text format convention
+	TimestampSeconds = "s"
+)
+
+// TimestampOptions controls the inline timestamps added by AddTimestamps
+type TimestampOptions struct {
+	Disabled bool   // Leave metric lines untouched, for ingest parsers that reject inline timestamps
+	Unit     string // TimestampMillis (default) or TimestampSeconds
+}
+
 // AddTimestamps adds explicit timestamps to Prometheus text format metrics
 // This ensures all metrics are reported with aligned collection times
 // Example: node_cpu_seconds_total{cpu="0",mode="idle"} 123.45 → node_cpu_seconds_total{cpu="0",mode="idle"} 123.45 1730102400000
-func AddTimestamps(data []byte, collectionTime time.Time) []byte {
-	timestampMs := collectionTime.UnixMilli()
+// Only sample lines are rewritten: comments (# HELP, # TYPE) and every other line are kept
+// verbatim and in order, so metadata stays ahead of its metric family for the ingest server
+// Lines of any length are kept (long lines are dropped before, see DropLongLines)
+func AddTimestamps(data []byte, collectionTime time.Time, opts TimestampOptions) ([]byte, error) {
+	if opts.Disabled {
+		return data, nil
+	}
+
+	timestamp := collectionTime.UnixMilli()
+	if opts.Unit == TimestampSeconds {
+		timestamp = collectionTime.Unix()
+	}
 
 	var result bytes.Buffer
-	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner := newLineScanner(data)
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -94,7 +115,11 @@ func AddTimestamps(data []byte, collectionTime time.Time) []byte {
 		// Parse metric line: metric_name{labels} value [timestamp]
 		// If timestamp already exists, skip adding
 		if strings.Contains(line, " ") {
+			// Label values may contain spaces, so only count fields after the closing brace
 			parts := strings.Fields(line)
+			if end := strings.LastIndex(line, "}"); end != -1 {
+				parts = append([]string{line[:end+1]}, strings.Fields(line[end+1:])...)
+			}
 			// If line has 3 parts (name, value, timestamp), timestamp already exists
 			if len(parts) >= 3 {
 				result.WriteString(line)
@@ -104,7 +129,7 @@ func AddTimestamps(data []byte, collectionTime time.Time) []byte {
 
 			// Add timestamp (line has name and value, but no timestamp)
 			result.WriteString(line)
-			result.WriteString(fmt.Sprintf(" %d\n", timestampMs))
+			result.WriteString(fmt.Sprintf(" %d\n", timestamp))
 		} else {
 			// Invalid line format, keep as-is
 			result.WriteString(line)
@@ -112,5 +137,8 @@ func AddTimestamps(data []byte, collectionTime time.Time) []byte {
 		}
 	}
 
-	return result.Bytes()
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner error: %w", err)
+	}
+	return result.Bytes(), nil
 }
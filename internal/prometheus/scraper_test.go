@@ -118,3 +118,113 @@ func TestScraper_VerifyFails(t *testing.T) {
 		t.Errorf("Expected verification error, got: %v", err)
 	}
 }
+
+func TestAddTimestamps(t *testing.T) {
+	input := `# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 0.5
+node_hwmon_sensor_label{chip="platform_coretemp_0",label="Core 0",sensor="temp2"} 1
+node_boot_time_seconds 1.7e+09 1730000000000
+`
+	collectionTime := time.Unix(1730102400, 0)
+
+	tests := []struct {
+		name string
+		opts TimestampOptions
+		want string
+	}{
+		{
+			name: "milliseconds by default",
+			opts: TimestampOptions{},
+			want: `# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 0.5 1730102400000
+node_hwmon_sensor_label{chip="platform_coretemp_0",label="Core 0",sensor="temp2"} 1 1730102400000
+node_boot_time_seconds 1.7e+09 1730000000000
+`,
+		},
+		{
+			name: "seconds",
+			opts: TimestampOptions{Unit: TimestampSeconds},
+			want: `# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 0.5 1730102400
+node_hwmon_sensor_label{chip="platform_coretemp_0",label="Core 0",sensor="temp2"} 1 1730102400
+node_boot_time_seconds 1.7e+09 1730000000000
+`,
+		},
+		{
+			name: "disabled",
+			opts: TimestampOptions{Disabled: true, Unit: TimestampSeconds},
+			want: input,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := AddTimestamps([]byte(input), collectionTime, tt.opts)
+			if err != nil {
+				t.Fatalf("AddTimestamps failed: %v", err)
+			}
+			if got := string(data); got != tt.want {
+				t.Errorf("AddTimestamps() =\n%s\nwant:\n%s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTimestamps_PreservesMetadata(t *testing.T) {
+	input := `# HELP app_requests_total Requests served, by path.
+# TYPE app_requests_total counter
+app_requests_total{path="/"} 42
+app_requests_total{path="/# not a comment"} 7
+
+# HELP app_queue_depth Jobs waiting.
+# TYPE app_queue_depth gauge
+app_queue_depth 3
+# HELP app_latency_seconds Request latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{le="0.1"} 5
+app_latency_seconds_bucket{le="+Inf"} 9
+app_latency_seconds_sum 1.2
+app_latency_seconds_count 9
+# EOF
+`
+	data, err := AddTimestamps([]byte(input), time.Unix(1730102400, 0), TimestampOptions{})
+	if err != nil {
+		t.Fatalf("AddTimestamps failed: %v", err)
+	}
+	got := string(data)
+
+	gotLines := strings.Split(got, "\n")
+	wantLines := strings.Split(input, "\n")
+	if len(gotLines) != len(wantLines) {
+		t.Fatalf("AddTimestamps() changed the line count from %d to %d:\n%s", len(wantLines), len(gotLines), got)
+	}
+	for i, want := range wantLines {
+		if want == "" || want[0] == '#' {
+			// Comments and blank lines are kept verbatim, in the same position
+			if gotLines[i] != want {
+				t.Errorf("Line %d = %q, want %q unchanged", i+1, gotLines[i], want)
+			}
+		} else if gotLines[i] != want+" 1730102400000" {
+			t.Errorf("Line %d = %q, want %q with a timestamp", i+1, gotLines[i], want)
+		}
+	}
+}
+
+func TestAddTimestamps_LongLine(t *testing.T) {
+	// A sample line over bufio.Scanner's default 64KB limit must not truncate the scrape
+	long := `app_info{value="` + strings.Repeat("x", 100*1024) + `"} 1`
+	input := "# TYPE app_info gauge\n" + long + "\n# TYPE app_up gauge\napp_up 1\n"
+
+	data, err := AddTimestamps([]byte(input), time.Unix(1730102400, 0), TimestampOptions{Unit: TimestampSeconds})
+	if err != nil {
+		t.Fatalf("AddTimestamps failed: %v", err)
+	}
+	got := string(data)
+	want := "# TYPE app_info gauge\n" + long + " 1730102400\n# TYPE app_up gauge\napp_up 1 1730102400\n"
+	if got != want {
+		t.Errorf("AddTimestamps() returned %d bytes, want %d (ends with %q)", len(got), len(want), got[max(0, len(got)-40):])
+	}
+}
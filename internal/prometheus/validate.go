@@ -0,0 +1,60 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidateText checks that data is well-formed Prometheus text format
+// Every non-comment line must be "name[{labels}] value [timestamp]" with a numeric value
+// and timestamp. The parsers skip malformed lines silently, so this is for integrity checks
+func ValidateText(data []byte) error {
+	scanner := newLineScanner(data)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		// Blank (or whitespace-only) lines are allowed anywhere
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		// Label values may contain spaces, so split after the closing brace
+		var name string
+		var rest []string
+		if start := strings.Index(line, "{"); start != -1 {
+			end := strings.LastIndex(line, "}")
+			if end < start {
+				return fmt.Errorf("line %d: unterminated label set", lineNum)
+			}
+			name = line[:start]
+			rest = strings.Fields(line[end+1:])
+		} else {
+			fields := strings.Fields(line)
+			name = fields[0]
+			rest = fields[1:]
+		}
+
+		if name == "" {
+			return fmt.Errorf("line %d: missing metric name", lineNum)
+		}
+		if len(rest) < 1 || len(rest) > 2 {
+			return fmt.Errorf("line %d: expected a value and optional timestamp, got %d fields", lineNum, len(rest))
+		}
+		if _, err := parseValue(rest[0]); err != nil {
+			return fmt.Errorf("line %d: invalid value %q", lineNum, rest[0])
+		}
+		if len(rest) == 2 {
+			if _, err := strconv.ParseInt(rest[1], 10, 64); err != nil {
+				return fmt.Errorf("line %d: invalid timestamp %q", lineNum, rest[1])
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scanner error: %w", err)
+	}
+	return nil
+}
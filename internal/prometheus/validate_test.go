@@ -0,0 +1,38 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateText(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid", input: "# HELP node_load1 1m load average.\n# TYPE node_load1 gauge\nnode_load1 0.5\n"},
+		{name: "labels and timestamp", input: `node_filesystem_avail_bytes{mountpoint="/a b"} 1e+09 1700000000000` + "\n"},
+		{name: "whitespace-only line", input: "node_load1 0.5\n   \n\t\nnode_load5 0.4\n"},
+		{name: "empty", input: ""},
+		{name: "missing value", input: "node_load1\n", wantErr: "line 1: expected a value"},
+		{name: "invalid value", input: "node_load1 0.5\nnode_load5 abc\n", wantErr: `line 2: invalid value "abc"`},
+		{name: "invalid timestamp", input: "node_load1 0.5 soon\n", wantErr: `invalid timestamp "soon"`},
+		{name: "unterminated labels", input: "node_load1{cpu=\"0\" 0.5\n", wantErr: "unterminated label set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateText([]byte(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -1,63 +1,117 @@
 package report
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/node-pulse/agent/internal/config"
 	"github.com/node-pulse/agent/internal/logger"
 )
 
+// ErrReadOnlyFilesystem is returned when the buffer directory is on a read-only filesystem
+// This usually means the kernel remounted / read-only after disk errors
+var ErrReadOnlyFilesystem = errors.New("buffer filesystem is read-only")
+
+// deployIDHeader starts the optional first line of a buffer file, recording agent.deploy_id at
+// scrape time so backlog sent after a redeploy keeps the deploy it was scraped under
+// A Prometheus comment, so the file is still valid text format
+const deployIDHeader = "# nodepulse deploy_id="
+
+// writeFile writes buffer files to disk (overridable in tests)
+var writeFile = os.WriteFile
+
 // Buffer handles buffering failed reports to disk
 type Buffer struct {
-	config *config.Config
-	mu     sync.Mutex
+	config   *config.Config
+	mu       sync.Mutex
+	readOnly bool // Set when a write fails with EROFS, cleared on next successful write
 }
 
 // NewBuffer creates a new buffer
 func NewBuffer(cfg *config.Config) (*Buffer, error) {
 	// Ensure buffer directory exists
 	if err := cfg.EnsureBufferDir(); err != nil {
+		if isReadOnlyError(err) {
+			logReadOnly(cfg.Buffer.Path, err)
+		}
 		return nil, err
 	}
 
-	return &Buffer{
+	b := &Buffer{
 		config: cfg,
-	}, nil
+	}
+
+	// Probe the buffer directory so a read-only filesystem is reported at startup
+	// instead of on the first scrape. The agent keeps running and retries on each write.
+	probeFile := filepath.Join(cfg.Buffer.Path, ".write-test")
+	if err := writeFile(probeFile, []byte("test"), 0644); err != nil {
+		if isReadOnlyError(err) {
+			b.readOnly = true
+			logReadOnly(cfg.Buffer.Path, err)
+		}
+	} else {
+		os.Remove(probeFile)
+	}
+
+	return b, nil
 }
 
 // SavePrometheus saves Prometheus text format data to buffer
 // Directory structure: buffer/<exporter>/YYYYMMDD-HHMMSS-<server_id>.prom
 func (b *Buffer) SavePrometheus(data []byte, serverID string, exporterName string) error {
+	return b.SavePrometheusAt(data, serverID, exporterName, time.Now())
+}
+
+// SavePrometheusAt saves Prometheus text format data to buffer with the given scrape time
+// The timestamp determines the filename, and therefore the drain order
+func (b *Buffer) SavePrometheusAt(data []byte, serverID string, exporterName string, scrapedAt time.Time) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	// Sanitize exporter name (remove special chars)
-	safeExporterName := sanitizeExporterName(exporterName)
+	safeExporterName := config.BufferDirName(exporterName)
 
 	// Create exporter subdirectory if it doesn't exist
 	exporterDir := filepath.Join(b.config.Buffer.Path, safeExporterName)
 	if err := os.MkdirAll(exporterDir, 0755); err != nil {
+		if isReadOnlyError(err) {
+			return b.markReadOnly(err)
+		}
 		return fmt.Errorf("failed to create exporter directory: %w", err)
 	}
 
 	// Generate filename without exporter name (it's in the directory)
-	now := time.Now()
 	filename := fmt.Sprintf("%s-%s.prom",
-		now.Format("20060102-150405"),
+		scrapedAt.Format("20060102-150405"),
 		serverID)
 	filePath := filepath.Join(exporterDir, filename)
 
+	if deployID := b.config.Agent.DeployID; deployID != "" {
+		data = append([]byte(deployIDHeader+strconv.Quote(deployID)+"\n"), data...)
+	}
+
 	// Write Prometheus text format to file
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := writeFile(filePath, data, 0644); err != nil {
+		if isReadOnlyError(err) {
+			return b.markReadOnly(err)
+		}
 		return fmt.Errorf("failed to write buffer file: %w", err)
 	}
 
+	if b.readOnly {
+		b.readOnly = false
+		logger.Info("Buffer filesystem is writable again", logger.String("path", b.config.Buffer.Path))
+	}
+
 	logger.Debug("Saved Prometheus data to buffer",
 		logger.String("exporter", exporterName),
 		logger.String("file", filepath.Join(safeExporterName, filename)),
@@ -77,8 +131,10 @@ func (b *Buffer) GetBufferFiles() ([]string, error) {
 // PrometheusEntry represents a buffered Prometheus scrape
 type PrometheusEntry struct {
 	ServerID     string
-	ExporterName string // Extracted from directory name
-	Data         []byte
+	ExporterName string    // Extracted from directory name
+	ScrapedAt    time.Time // Extracted from filename (zero if it doesn't parse)
+	DeployID     string    // agent.deploy_id when the scrape was buffered (from the file header)
+	Data         []byte    // Prometheus text, without the header
 }
 
 // LoadPrometheusFile loads Prometheus text format from a buffer file
@@ -106,13 +162,36 @@ func (b *Buffer) LoadPrometheusFile(filePath string) (*PrometheusEntry, error) {
 
 	serverID := parts[2]
 
+	// Filenames are written in local time (see SavePrometheus)
+	scrapedAt, _ := time.ParseInLocation("20060102-150405", parts[0]+"-"+parts[1], time.Local)
+
+	deployID, data := splitDeployID(data)
+
 	return &PrometheusEntry{
 		ServerID:     serverID,
 		ExporterName: exporterName,
+		ScrapedAt:    scrapedAt,
+		DeployID:     deployID,
 		Data:         data,
 	}, nil
 }
 
+// splitDeployID removes the deploy ID header from buffered data, returning the deploy ID
+// (empty for files without one, e.g. written without agent.deploy_id or by older agents)
+func splitDeployID(data []byte) (string, []byte) {
+	if !bytes.HasPrefix(data, []byte(deployIDHeader)) {
+		return "", data
+	}
+
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	deployID, err := strconv.Unquote(string(line[len(deployIDHeader):]))
+	if err != nil {
+		// Not written by the agent; leave the comment for the parsers to skip
+		return "", data
+	}
+	return deployID, rest
+}
+
 // DeleteFile deletes a specific buffer file
 func (b *Buffer) DeleteFile(filePath string) error {
 	b.mu.Lock()
@@ -141,6 +220,9 @@ func (b *Buffer) getBufferFiles() ([]string, error) {
 		if !entry.IsDir() {
 			continue // Skip non-directory files
 		}
+		if entry.Name() == legacyDir || entry.Name() == quarantineDir {
+			continue // Unsendable files from older agents or failed checks (pruned by Cleanup)
+		}
 
 		exporterDir := filepath.Join(b.config.Buffer.Path, entry.Name())
 		pattern := filepath.Join(exporterDir, "*.prom")
@@ -171,7 +253,9 @@ func (b *Buffer) Cleanup() error {
 		return err
 	}
 
-	cutoffTime := time.Now().Add(-time.Duration(b.config.Buffer.RetentionHours) * time.Hour)
+	cutoffTime := b.retentionCutoff()
+	b.pruneOldFiles(legacyDir, cutoffTime)
+	b.pruneOldFiles(quarantineDir, cutoffTime)
 
 	for _, filePath := range files {
 		// Extract timestamp from filename
@@ -212,16 +296,42 @@ func (b *Buffer) Cleanup() error {
 	return nil
 }
 
-// sanitizeExporterName removes special characters from exporter names
-func sanitizeExporterName(name string) string {
-	replacer := strings.NewReplacer(
-		"/", "_",
-		"\\", "_",
-		":", "_",
-		" ", "_",
-		".", "_",
-	)
-	return replacer.Replace(name)
+// retentionCutoff returns the time before which buffer files are deleted
+func (b *Buffer) retentionCutoff() time.Time {
+	return time.Now().Add(-time.Duration(b.config.Buffer.RetentionHours) * time.Hour)
+}
+
+// IsReadOnly reports whether the last buffer write failed because the filesystem is read-only
+func (b *Buffer) IsReadOnly() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.readOnly
+}
+
+// markReadOnly records a read-only filesystem failure and returns a wrapped error
+// Only the first failure is logged at error level to avoid flooding the log every scrape
+// Caller must hold b.mu
+func (b *Buffer) markReadOnly(err error) error {
+	if !b.readOnly {
+		b.readOnly = true
+		logReadOnly(b.config.Buffer.Path, err)
+	}
+	return fmt.Errorf("%w: %v", ErrReadOnlyFilesystem, err)
+}
+
+// logReadOnly logs an actionable message for a read-only buffer filesystem
+func logReadOnly(path string, err error) {
+	logger.Error("Buffer directory is on a read-only filesystem - metrics cannot be buffered. "+
+		"The root filesystem may have been remounted read-only after disk errors: check 'dmesg' and remount read-write",
+		logger.String("path", path),
+		logger.Bool("filesystem_readonly", true),
+		logger.Err(err))
+}
+
+// isReadOnlyError checks if an error was caused by a read-only filesystem (EROFS)
+func isReadOnlyError(err error) bool {
+	return errors.Is(err, syscall.EROFS)
 }
 
 // Close closes the buffer (currently no-op)
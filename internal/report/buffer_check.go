@@ -0,0 +1,96 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+// quarantineDir holds corrupt buffer files moved aside by 'nodepulse buffer check --quarantine'
+// Like legacy/, the drain loop never reads it and cleanup prunes it by age
+const quarantineDir = config.BufferQuarantineDir
+
+// FileCheck is the integrity check result of a single buffer file
+type FileCheck struct {
+	Path     string
+	Exporter string
+	Err      error // nil if the file is intact
+}
+
+// CheckFiles checks every buffered file, parsing it with its exporter's parser
+// Results are in drain order
+func (b *Buffer) CheckFiles() ([]FileCheck, error) {
+	files, err := b.GetBufferFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	generic := genericExporters(b.config)
+	checks := make([]FileCheck, 0, len(files))
+	for _, path := range files {
+		check := FileCheck{Path: path, Exporter: filepath.Base(filepath.Dir(path))}
+		entry, err := b.LoadPrometheusFile(path)
+		if err != nil {
+			check.Err = err
+		} else {
+			check.Err = checkBufferEntry(entry, generic)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkBufferEntry returns why a buffered scrape can't be sent as-is, or nil if it's intact
+// Scrapes always end with a newline, so a missing one means the write was cut short
+// (e.g. power loss); NUL bytes are what unwritten blocks read back as after a crash
+// Type generic exporters have no parser, so their scrapes only need to be valid text format
+func checkBufferEntry(entry *PrometheusEntry, generic map[string]bool) error {
+	data := entry.Data
+	switch {
+	case len(data) == 0:
+		return errors.New("empty file")
+	case bytes.IndexByte(data, 0) != -1:
+		return errors.New("contains NUL bytes (partially written)")
+	case data[len(data)-1] != '\n':
+		return errors.New("truncated (no trailing newline)")
+	}
+
+	if err := prometheus.ValidateText(data); err != nil {
+		return err
+	}
+
+	switch entry.ExporterName {
+	case "node_exporter":
+		_, err := prometheus.ParseNodeExporterMetrics(data)
+		return err
+	case "process_exporter":
+		_, err := prometheus.ParseProcessExporterMetrics(data)
+		return err
+	default:
+		if generic[entry.ExporterName] {
+			return nil
+		}
+		return fmt.Errorf("no parser for exporter %s (the drain loop skips it)", entry.ExporterName)
+	}
+}
+
+// QuarantineFile moves a buffer file to quarantine/<exporter>/ and returns its new path
+func (b *Buffer) QuarantineFile(path string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	exporter := filepath.Base(filepath.Dir(path))
+	dest := filepath.Join(b.config.Buffer.Path, quarantineDir, exporter, filepath.Base(path))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("failed to move file to quarantine: %w", err)
+	}
+	return dest, nil
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckFiles(t *testing.T) {
+	cfg := newTestConfig(t, "http://127.0.0.1:1")
+	buffer, err := NewBuffer(cfg)
+	if err != nil {
+		t.Fatalf("NewBuffer failed: %v", err)
+	}
+
+	fixtures := []struct {
+		exporter string
+		name     string
+		data     string
+		corrupt  string // Expected error substring, empty if the file is intact
+	}{
+		{"node_exporter", "20251015-120000-srv.prom", "# TYPE node_load1 gauge\nnode_load1 0.5 1760529600000\n", ""},
+		{"node_exporter", "20251015-120015-srv.prom", `node_hwmon_sensor_label{chip="coretemp",label="Core 0",sensor="temp1"} 1` + "\n", ""},
+		{"process_exporter", "20251015-120000-srv.prom", `namedprocess_namegroup_num_procs{groupname="nginx"} 4` + "\n", ""},
+		{"node_exporter", "20251015-120030-srv.prom", "node_load1 0.5\nnode_load5 0.", "truncated"},
+		{"node_exporter", "20251015-120045-srv.prom", "", "empty"},
+		{"node_exporter", "20251015-120100-srv.prom", "node_load1 0.5\n\x00\x00\x00\x00", "NUL"},
+		{"node_exporter", "20251015-120115-srv.prom", "node_load1 0.5\nnode_memory_MemTotal_bytes{\n", "line 2"},
+		{"node_exporter", "20251015-120130-srv.prom", "node_load1 abc\n", "invalid value"},
+		{"redis_exporter", "20251015-120000-srv.prom", "redis_up 1\n", "no parser"},
+	}
+
+	want := make(map[string]string)
+	for _, f := range fixtures {
+		dir := filepath.Join(cfg.Buffer.Path, f.exporter)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, []byte(f.data), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		want[path] = f.corrupt
+	}
+
+	checks, err := buffer.CheckFiles()
+	if err != nil {
+		t.Fatalf("CheckFiles failed: %v", err)
+	}
+	if len(checks) != len(fixtures) {
+		t.Fatalf("Expected %d checks, got %d", len(fixtures), len(checks))
+	}
+
+	var corrupt []string
+	for _, check := range checks {
+		expected := want[check.Path]
+		switch {
+		case expected == "" && check.Err != nil:
+			t.Errorf("%s: expected OK, got %v", check.Path, check.Err)
+		case expected != "" && check.Err == nil:
+			t.Errorf("%s: expected corrupt (%s), got OK", check.Path, expected)
+		case expected != "" && !strings.Contains(check.Err.Error(), expected):
+			t.Errorf("%s: expected error containing %q, got %v", check.Path, expected, check.Err)
+		}
+		if check.Err != nil {
+			corrupt = append(corrupt, check.Path)
+		}
+	}
+
+	// Quarantined files leave the drain queue but are kept for inspection
+	for _, path := range corrupt {
+		dest, err := buffer.QuarantineFile(path)
+		if err != nil {
+			t.Fatalf("QuarantineFile failed: %v", err)
+		}
+		if _, err := os.Stat(dest); err != nil {
+			t.Errorf("Expected quarantined file at %s: %v", dest, err)
+		}
+		if !strings.Contains(dest, filepath.Join(quarantineDir, filepath.Base(filepath.Dir(path)))) {
+			t.Errorf("Expected %s under quarantine/<exporter>/", dest)
+		}
+	}
+
+	remaining, err := buffer.GetBufferFiles()
+	if err != nil {
+		t.Fatalf("GetBufferFiles failed: %v", err)
+	}
+	if len(remaining) != 3 {
+		t.Errorf("Expected the 3 intact files to remain queued, got %v", remaining)
+	}
+}
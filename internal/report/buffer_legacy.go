@@ -0,0 +1,121 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// legacyDir holds buffer files from older agents that can't be sent anymore
+// (e.g. v0.0.x .jsonl reports). They are kept until the retention period so nothing
+// is silently discarded, but the drain loop never reads them
+const legacyDir = config.BufferLegacyDir
+
+// legacyExporter is where flat .prom files are moved: agents before per-exporter
+// subdirectories only scraped node_exporter
+const legacyExporter = "node_exporter"
+
+// MigrateLegacyFiles moves files left flat in the buffer root by older agents
+// Flat .prom files with a valid name go to the node_exporter subdirectory so they are
+// sent normally; anything else goes to legacy/, which cleanup prunes by age
+// Safe to call on every startup: once migrated, the buffer root holds no files
+func (b *Buffer) MigrateLegacyFiles() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries, err := os.ReadDir(b.config.Buffer.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	migrated, archived := 0, 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !isLegacyBufferFile(name) {
+			continue
+		}
+
+		src := filepath.Join(b.config.Buffer.Path, name)
+		destDir := legacyDir
+		if strings.HasSuffix(name, ".prom") && validBufferFilename(name) {
+			destDir = legacyExporter
+		}
+
+		dest := filepath.Join(b.config.Buffer.Path, destDir, name)
+		if _, err := os.Stat(dest); err == nil {
+			// A current file already has this name; keep the legacy one aside
+			destDir = legacyDir
+			dest = filepath.Join(b.config.Buffer.Path, legacyDir, name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dest); err != nil {
+			logger.Warn("Failed to migrate legacy buffer file",
+				logger.String("file", src),
+				logger.Err(err))
+			continue
+		}
+
+		if destDir == legacyDir {
+			archived++
+		} else {
+			migrated++
+		}
+	}
+
+	if migrated > 0 || archived > 0 {
+		logger.Info("Migrated legacy buffer files from the buffer root",
+			logger.Int("queued_for_sending", migrated),
+			logger.Int("moved_to_legacy", archived))
+	}
+
+	b.pruneOldFiles(legacyDir, b.retentionCutoff())
+	return nil
+}
+
+// isLegacyBufferFile reports whether a file in the buffer root was written by an older agent
+func isLegacyBufferFile(name string) bool {
+	return strings.HasSuffix(name, ".prom") || strings.HasSuffix(name, ".jsonl")
+}
+
+// validBufferFilename reports whether a .prom filename has the YYYYMMDD-HHMMSS-<server_id> format
+func validBufferFilename(name string) bool {
+	parts := strings.SplitN(strings.TrimSuffix(name, ".prom"), "-", 3)
+	if len(parts) < 3 || parts[2] == "" {
+		return false
+	}
+	_, err := time.Parse("20060102-150405", parts[0]+"-"+parts[1])
+	return err == nil
+}
+
+// pruneOldFiles removes files under a buffer subdirectory (legacy/ or quarantine/)
+// last modified before cutoff
+// Their names may not follow the current format, so age comes from the modification time
+func (b *Buffer) pruneOldFiles(subdir string, cutoff time.Time) {
+	root := filepath.Join(b.config.Buffer.Path, subdir)
+	filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			logger.Warn("Failed to remove old buffer file", logger.String("file", path), logger.Err(err))
+		} else {
+			logger.Debug("Removed old buffer file", logger.String("file", path))
+		}
+		return nil
+	})
+}
@@ -3,16 +3,21 @@ package report
 import (
 	"os"
 	"path/filepath"
+	"syscall"
 	"time"
+
+	"github.com/node-pulse/agent/internal/config"
 )
 
 // BufferStatus represents the current state of the buffer
 type BufferStatus struct {
-	FileCount    int
-	ReportCount  int
-	OldestFile   time.Time
-	TotalSizeKB  int64
-	HasBuffered  bool
+	FileCount          int
+	ReportCount        int
+	OldestFile         time.Time
+	TotalSizeKB        int64
+	TotalSizeBytes     int64
+	HasBuffered        bool
+	FilesystemReadOnly bool // Buffer directory is on a read-only filesystem
 }
 
 // GetBufferStatus returns the current buffer status
@@ -26,16 +31,16 @@ func (b *Buffer) GetBufferStatus() BufferStatus {
 
 	files, err := b.getBufferFiles()
 	if err != nil || len(files) == 0 {
-		return BufferStatus{}
+		return BufferStatus{FilesystemReadOnly: b.readOnly}
 	}
 
 	status := BufferStatus{
-		FileCount:   len(files),
-		HasBuffered: true,
+		FileCount:          len(files),
+		HasBuffered:        true,
+		FilesystemReadOnly: b.readOnly,
 	}
 
 	var totalSize int64
-	var oldestTime time.Time
 
 	// In v2.0, each file is a single Prometheus scrape
 	// File format: YYYYMMDD-HHMMSS-<server_id>.prom
@@ -46,23 +51,63 @@ func (b *Buffer) GetBufferStatus() BufferStatus {
 		if info, err := os.Stat(filePath); err == nil {
 			totalSize += info.Size()
 		}
-
-		// Get file timestamp from filename
-		// Format: YYYYMMDD-HHMMSS-<server_id>.prom
-		filename := filepath.Base(filePath)
-		if len(filename) >= 15 {
-			// Extract YYYYMMDD-HHMMSS part
-			timeStr := filename[:15]
-			if fileTime, err := time.Parse("20060102-150405", timeStr); err == nil {
-				if oldestTime.IsZero() || fileTime.Before(oldestTime) {
-					oldestTime = fileTime
-				}
-			}
-		}
 	}
 
-	status.OldestFile = oldestTime
+	status.OldestFile = oldestScrapeTime(files)
 	status.TotalSizeKB = totalSize / 1024
+	status.TotalSizeBytes = totalSize
 
 	return status
 }
+
+// fileScrapeTime returns the scrape time encoded in a buffer file name
+// Format: YYYYMMDD-HHMMSS-<server_id>.prom
+func fileScrapeTime(filePath string) (time.Time, bool) {
+	filename := filepath.Base(filePath)
+	if len(filename) < 15 {
+		return time.Time{}, false
+	}
+	// Filenames are written in local time (see SavePrometheus)
+	fileTime, err := time.ParseInLocation("20060102-150405", filename[:15], time.Local)
+	return fileTime, err == nil
+}
+
+// oldestScrapeTime returns the earliest scrape time among buffer files (zero if none parse)
+func oldestScrapeTime(filePaths []string) time.Time {
+	var oldest time.Time
+	for _, filePath := range filePaths {
+		if fileTime, ok := fileScrapeTime(filePath); ok && (oldest.IsZero() || fileTime.Before(oldest)) {
+			oldest = fileTime
+		}
+	}
+	return oldest
+}
+
+// Status is the buffer and delivery state shown by 'nodepulse status'
+type Status struct {
+	Buffer   BufferStatus
+	Delivery DeliveryStats
+	Health   DeliveryHealth
+}
+
+// ReadStatus reads the buffer and delivery state from disk, for commands that run alongside
+// the agent. Unlike NewSender it creates no directories, writes nothing, and doesn't need the
+// ingest auth token
+func ReadStatus(cfg *config.Config) (Status, error) {
+	buffer := &Buffer{config: cfg, readOnly: isReadOnlyDir(cfg.Buffer.Path)}
+	status := Status{Buffer: buffer.GetBufferStatus()}
+	status.Health = deliveryHealth(status.Buffer.OldestFile, cfg.Buffer.StaleThreshold, time.Now())
+
+	delivery, err := loadDeliveryStats(filepath.Join(cfg.Buffer.Path, deliveryStateFile))
+	if err != nil {
+		return status, err
+	}
+	status.Delivery = delivery
+	return status, nil
+}
+
+// isReadOnlyDir reports whether dir is on a read-only filesystem, without writing to it
+func isReadOnlyDir(dir string) bool {
+	const wOK = 0x2 // access(2) W_OK
+	return isReadOnlyError(syscall.Access(dir, wOK))
+}
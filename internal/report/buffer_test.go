@@ -0,0 +1,197 @@
+package report
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+func newTestBuffer(t *testing.T) *Buffer {
+	t.Helper()
+
+	cfg := &config.Config{
+		Buffer: config.BufferConfig{
+			Path:           t.TempDir(),
+			RetentionHours: 48,
+			BatchSize:      5,
+		},
+	}
+
+	buffer, err := NewBuffer(cfg)
+	if err != nil {
+		t.Fatalf("NewBuffer failed: %v", err)
+	}
+	return buffer
+}
+
+func TestSavePrometheus_ReadOnlyFilesystem(t *testing.T) {
+	buffer := newTestBuffer(t)
+
+	// Simulate the kernel remounting the filesystem read-only
+	origWriteFile := writeFile
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		return &os.PathError{Op: "open", Path: name, Err: syscall.EROFS}
+	}
+	defer func() { writeFile = origWriteFile }()
+
+	err := buffer.SavePrometheus([]byte("test_metric 1\n"), "test-server", "node_exporter")
+	if err == nil {
+		t.Fatal("Expected error when filesystem is read-only")
+	}
+	if !errors.Is(err, ErrReadOnlyFilesystem) {
+		t.Errorf("Expected ErrReadOnlyFilesystem, got: %v", err)
+	}
+	if !buffer.IsReadOnly() {
+		t.Error("Expected buffer to be marked read-only")
+	}
+	if !buffer.GetBufferStatus().FilesystemReadOnly {
+		t.Error("Expected FilesystemReadOnly in buffer status")
+	}
+
+	// Filesystem becomes writable again - flag should clear
+	writeFile = origWriteFile
+	if err := buffer.SavePrometheus([]byte("test_metric 1\n"), "test-server", "node_exporter"); err != nil {
+		t.Fatalf("SavePrometheus failed after filesystem recovered: %v", err)
+	}
+	if buffer.IsReadOnly() {
+		t.Error("Expected read-only flag to clear after successful write")
+	}
+}
+
+func TestSavePrometheus_OtherWriteError(t *testing.T) {
+	buffer := newTestBuffer(t)
+
+	origWriteFile := writeFile
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		return &os.PathError{Op: "open", Path: name, Err: syscall.ENOSPC}
+	}
+	defer func() { writeFile = origWriteFile }()
+
+	err := buffer.SavePrometheus([]byte("test_metric 1\n"), "test-server", "node_exporter")
+	if err == nil {
+		t.Fatal("Expected error on write failure")
+	}
+	if errors.Is(err, ErrReadOnlyFilesystem) {
+		t.Error("ENOSPC should not be reported as a read-only filesystem")
+	}
+	if buffer.IsReadOnly() {
+		t.Error("Buffer should not be marked read-only for ENOSPC")
+	}
+}
+
+func TestMigrateLegacyFiles(t *testing.T) {
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, "none")
+	root := sender.config.Buffer.Path
+
+	// Files written flat in the buffer root by older agents
+	writeLegacy := func(name, content string, modTime time.Time) {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write legacy file: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+	now := time.Now()
+	flatProm := now.Add(-time.Minute).Format("20060102-150405") + "-test-server.prom"
+	writeLegacy(flatProm, "node_load1 0.75\n", now)
+	writeLegacy("20240101-000000-test-server.jsonl", `{"cpu":1}`+"\n", now)
+	writeLegacy("old-report.jsonl", `{"cpu":1}`+"\n", now.Add(-72*time.Hour))
+
+	if err := sender.buffer.MigrateLegacyFiles(); err != nil {
+		t.Fatalf("MigrateLegacyFiles failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "node_exporter", flatProm)); err != nil {
+		t.Errorf("Expected flat .prom file in node_exporter/: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, legacyDir, "20240101-000000-test-server.jsonl")); err != nil {
+		t.Errorf("Expected .jsonl file in legacy/: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, legacyDir, "old-report.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("Expected legacy file older than retention to be pruned, got: %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(root, "*.*")); len(matches) != 0 {
+		t.Errorf("Expected buffer root to be empty of reports, found %v", matches)
+	}
+
+	// Only the migrated .prom file is queued, and it is sent like any other scrape
+	files, err := sender.buffer.GetBufferFiles()
+	if err != nil {
+		t.Fatalf("GetBufferFiles failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 buffer file after migration, got %v", files)
+	}
+	if err := sender.processBatch(files); err != nil {
+		t.Fatalf("processBatch failed: %v", err)
+	}
+	if !strings.Contains(string(gotBody), `"load_1min":0.75`) {
+		t.Errorf("Expected migrated scrape to be sent, got: %s", gotBody)
+	}
+	if files, _ := sender.buffer.GetBufferFiles(); len(files) != 0 {
+		t.Errorf("Expected buffer to be empty after sending, got %v", files)
+	}
+
+	// Running again is a no-op
+	if err := sender.buffer.MigrateLegacyFiles(); err != nil {
+		t.Fatalf("Second MigrateLegacyFiles failed: %v", err)
+	}
+}
+
+func TestReadStatus(t *testing.T) {
+	cfg := newTestConfig(t, "http://localhost")
+	cfg.Buffer.Path = filepath.Join(t.TempDir(), "buffer")
+	cfg.Buffer.StaleThreshold = time.Hour
+	// Bearer auth without its token env var, which NewSender would reject
+	cfg.Server.Auth = config.AuthConfig{Type: "bearer", TokenEnv: "NODEPULSE_TEST_UNSET_TOKEN"}
+
+	status, err := ReadStatus(cfg)
+	if err != nil {
+		t.Fatalf("ReadStatus failed: %v", err)
+	}
+	if status.Buffer.HasBuffered || !status.Delivery.LastDelivery.IsZero() {
+		t.Errorf("Expected an empty status, got %+v", status)
+	}
+	if _, err := os.Stat(cfg.Buffer.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected ReadStatus not to create the buffer directory, got %v", err)
+	}
+
+	// Buffered scrapes and delivery stats written by the agent
+	buffer := &Buffer{config: cfg}
+	if err := buffer.SavePrometheusAt([]byte("node_load1 0.5\n"), "test-server", "node_exporter", time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("SavePrometheusAt failed: %v", err)
+	}
+	delivered := time.Now().Add(-time.Minute).Truncate(time.Second)
+	if err := saveDeliveryStats(filepath.Join(cfg.Buffer.Path, deliveryStateFile), DeliveryStats{LastDelivery: delivered, BatchesSent: 7}); err != nil {
+		t.Fatalf("saveDeliveryStats failed: %v", err)
+	}
+
+	status, err = ReadStatus(cfg)
+	if err != nil {
+		t.Fatalf("ReadStatus failed: %v", err)
+	}
+	if status.Buffer.FileCount != 1 || !status.Health.Degraded {
+		t.Errorf("Expected 1 stale buffered file, got %+v, %+v", status.Buffer, status.Health)
+	}
+	if !status.Delivery.LastDelivery.Equal(delivered) || status.Delivery.BatchesSent != 7 {
+		t.Errorf("Expected the persisted delivery stats, got %+v", status.Delivery)
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// ClockSkewThreshold is how far the agent clock may drift from the ingest server before a warning
+// Inline timestamps use the agent clock, so a larger skew shifts the server-side time series
+const ClockSkewThreshold = 5 * time.Second
+
+// measureClockSkew estimates how far the local clock is ahead of the server (negative = behind)
+// from a response Date header. The header has one-second resolution and is truncated, so the
+// server time is taken as the middle of that second, compared with the middle of the time
+// between the request body being written and the response arriving (upload time excluded)
+func measureClockSkew(dateHeader string, wroteAt, receivedAt time.Time) (time.Duration, bool) {
+	if dateHeader == "" {
+		return 0, false
+	}
+	serverTime, err := http.ParseTime(dateHeader)
+	if err != nil {
+		return 0, false
+	}
+
+	localTime := wroteAt.Add(receivedAt.Sub(wroteAt) / 2)
+	serverTime = serverTime.Add(500 * time.Millisecond)
+	return localTime.Sub(serverTime).Round(100 * time.Millisecond), true
+}
+
+// ClockSkew returns the clock skew measured on the last response from the ingest server
+// (positive = agent clock ahead). ok is false if no response has carried a Date header yet
+func (s *Sender) ClockSkew() (skew time.Duration, ok bool) {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+
+	if s.delivery.ClockSkew == nil {
+		return 0, false
+	}
+	return *s.delivery.ClockSkew, true
+}
+
+// recordClockSkew stores a skew measurement (persisted with the next delivery)
+// Logs a warning when the skew exceeds ClockSkewThreshold, and again once it's back in range
+func (s *Sender) recordClockSkew(skew time.Duration) {
+	s.deliveryMu.Lock()
+	s.delivery.ClockSkew = &skew
+	exceeded := skew > ClockSkewThreshold || skew < -ClockSkewThreshold
+	changed := exceeded != s.skewWarned
+	s.skewWarned = exceeded
+	s.deliveryMu.Unlock()
+
+	if !changed {
+		return
+	}
+	if exceeded {
+		logger.Warn("Agent clock differs from the ingest server, metric timestamps will be off by the same amount. "+
+			"Check that NTP is running (e.g. 'timedatectl status')",
+			logger.Duration("clock_skew", skew),
+			logger.Duration("threshold", ClockSkewThreshold))
+	} else {
+		logger.Info("Agent clock is in sync with the ingest server again", logger.Duration("clock_skew", skew))
+	}
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMeasureClockSkew(t *testing.T) {
+	wroteAt := time.Date(2025, 10, 15, 12, 0, 10, 0, time.UTC)
+	receivedAt := wroteAt.Add(time.Second)
+
+	tests := []struct {
+		name     string
+		date     string
+		wantSkew time.Duration
+		wantOK   bool
+	}{
+		// Request midpoint 12:00:10.5, server second 12:00:10 (midpoint 12:00:10.5)
+		{"in sync", "Wed, 15 Oct 2025 12:00:10 GMT", 0, true},
+		{"agent ahead", "Wed, 15 Oct 2025 12:00:03 GMT", 7 * time.Second, true},
+		{"agent behind", "Wed, 15 Oct 2025 12:01:10 GMT", -time.Minute, true},
+		{"no header", "", 0, false},
+		{"invalid header", "yesterday", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew, ok := measureClockSkew(tt.date, wroteAt, receivedAt)
+			if ok != tt.wantOK || skew != tt.wantSkew {
+				t.Errorf("measureClockSkew() = %s, %v, want %s, %v", skew, ok, tt.wantSkew, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSender_ClockSkew(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Server clock 30s ahead of the agent
+		w.Header().Set("Date", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	if _, ok := sender.ClockSkew(); ok {
+		t.Fatal("Expected no clock skew before the first request")
+	}
+
+	if err := sender.buffer.SavePrometheus([]byte("node_load1 1\n"), "test-server", "node_exporter"); err != nil {
+		t.Fatalf("SavePrometheus failed: %v", err)
+	}
+	files, _ := sender.buffer.GetBufferFiles()
+	if err := sender.processBatch(files); err != nil {
+		t.Fatalf("processBatch failed: %v", err)
+	}
+
+	skew, ok := sender.ClockSkew()
+	if !ok || skew > -29*time.Second || skew < -31*time.Second {
+		t.Errorf("ClockSkew() = %s, %v, want about -30s", skew, ok)
+	}
+
+	// Persisted with the delivery stats for 'nodepulse status'
+	reloaded, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer reloaded.Close()
+	if got, ok := reloaded.ClockSkew(); !ok || got != skew {
+		t.Errorf("Expected persisted clock skew %s, got %s, %v", skew, got, ok)
+	}
+}
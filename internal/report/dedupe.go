@@ -0,0 +1,100 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+// deduper suppresses consecutive identical snapshots per exporter
+// A snapshot is only suppressed while it was scraped less than maxSuppress after the last
+// sent one, so the backend still receives a periodic heartbeat for unchanged metrics, also
+// when a backlog is drained at once
+type deduper struct {
+	maxSuppress time.Duration
+	sent        map[string]dedupeEntry // Last successfully sent snapshot per exporter
+	staged      map[string]dedupeEntry // Snapshots in the current batch (not yet sent)
+}
+
+type dedupeEntry struct {
+	fingerprint string
+	scrapedAt   time.Time
+}
+
+// newDeduper creates a deduper with the given max suppress duration
+func newDeduper(maxSuppress time.Duration) *deduper {
+	return &deduper{
+		maxSuppress: maxSuppress,
+		sent:        make(map[string]dedupeEntry),
+		staged:      make(map[string]dedupeEntry),
+	}
+}
+
+// check returns true if the snapshot should be suppressed
+// Non-suppressed snapshots are staged and become the new baseline after commit()
+func (d *deduper) check(exporterName string, fingerprint string, scrapedAt time.Time) bool {
+	if fingerprint == "" {
+		return false
+	}
+
+	last, ok := d.staged[exporterName]
+	if !ok {
+		last, ok = d.sent[exporterName]
+	}
+
+	if ok && last.fingerprint == fingerprint && scrapedAt.Sub(last.scrapedAt) < d.maxSuppress {
+		return true
+	}
+
+	d.staged[exporterName] = dedupeEntry{fingerprint: fingerprint, scrapedAt: scrapedAt}
+	return false
+}
+
+// commit promotes staged snapshots after a successful send
+func (d *deduper) commit() {
+	for name, entry := range d.staged {
+		d.sent[name] = entry
+	}
+	d.staged = make(map[string]dedupeEntry)
+}
+
+// rollback discards staged snapshots after a failed send
+func (d *deduper) rollback() {
+	d.staged = make(map[string]dedupeEntry)
+}
+
+// nodeSnapshotFingerprint hashes a node_exporter snapshot ignoring fields that change on every parse
+// Timestamp and UptimeSeconds are derived from the wall clock, not from the scraped data
+func nodeSnapshotFingerprint(snapshot prometheus.NodeExporterMetricSnapshot) string {
+	snapshot.Timestamp = time.Time{}
+	snapshot.UptimeSeconds = 0
+	return fingerprint(snapshot)
+}
+
+// processSnapshotsFingerprint hashes process_exporter snapshots ignoring timestamps
+// Snapshots are sorted by name since map iteration order is random
+func processSnapshotsFingerprint(snapshots []prometheus.ProcessExporterMetricSnapshot) string {
+	normalized := make([]prometheus.ProcessExporterMetricSnapshot, len(snapshots))
+	copy(normalized, snapshots)
+	for i := range normalized {
+		normalized[i].Timestamp = time.Time{}
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return normalized[i].Name < normalized[j].Name
+	})
+	return fingerprint(normalized)
+}
+
+// fingerprint returns a SHA-256 hash of the JSON encoding of v
+func fingerprint(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+func TestDeduper_SuppressesUntilMaxSuppress(t *testing.T) {
+	d := newDeduper(5 * time.Minute)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	snapshot := prometheus.NodeExporterMetricSnapshot{
+		Timestamp:        start,
+		MemoryTotalBytes: 8 * 1024 * 1024 * 1024,
+		DiskTotalBytes:   100 * 1024 * 1024 * 1024,
+	}
+
+	// First snapshot is always sent
+	if d.check("node_exporter", nodeSnapshotFingerprint(snapshot), start) {
+		t.Fatal("First snapshot should not be suppressed")
+	}
+	d.commit()
+
+	// Identical snapshot (different timestamp) is suppressed within max-suppress window
+	for _, offset := range []time.Duration{15 * time.Second, 1 * time.Minute, 4*time.Minute + 59*time.Second} {
+		snapshot.Timestamp = start.Add(offset)
+		if !d.check("node_exporter", nodeSnapshotFingerprint(snapshot), start.Add(offset)) {
+			t.Errorf("Identical snapshot at +%s should be suppressed", offset)
+		}
+	}
+
+	// Once max-suppress has elapsed, the snapshot is sent as a heartbeat
+	heartbeat := start.Add(5 * time.Minute)
+	if d.check("node_exporter", nodeSnapshotFingerprint(snapshot), heartbeat) {
+		t.Fatal("Snapshot should be sent after max-suppress elapsed")
+	}
+	d.commit()
+
+	// Suppression window restarts from the heartbeat
+	if !d.check("node_exporter", nodeSnapshotFingerprint(snapshot), heartbeat.Add(15*time.Second)) {
+		t.Error("Snapshot should be suppressed again after heartbeat")
+	}
+}
+
+func TestDeduper_ChangedSnapshotIsSent(t *testing.T) {
+	d := newDeduper(5 * time.Minute)
+	now := time.Now()
+
+	snapshot := prometheus.NodeExporterMetricSnapshot{DiskFreeBytes: 1000}
+	d.check("node_exporter", nodeSnapshotFingerprint(snapshot), now)
+	d.commit()
+
+	snapshot.DiskFreeBytes = 999
+	if d.check("node_exporter", nodeSnapshotFingerprint(snapshot), now.Add(15*time.Second)) {
+		t.Error("Changed snapshot should not be suppressed")
+	}
+}
+
+func TestDeduper_PerExporter(t *testing.T) {
+	d := newDeduper(5 * time.Minute)
+	now := time.Now()
+
+	fp := nodeSnapshotFingerprint(prometheus.NodeExporterMetricSnapshot{})
+	d.check("node_exporter", fp, now)
+	d.commit()
+
+	if d.check("other_exporter", fp, now) {
+		t.Error("Snapshots should be tracked per exporter")
+	}
+}
+
+func TestDeduper_RollbackOnFailedSend(t *testing.T) {
+	d := newDeduper(5 * time.Minute)
+	now := time.Now()
+
+	fp := nodeSnapshotFingerprint(prometheus.NodeExporterMetricSnapshot{Load1Min: 0.5})
+	if d.check("node_exporter", fp, now) {
+		t.Fatal("First snapshot should not be suppressed")
+	}
+	d.rollback()
+
+	// The retried file must not be suppressed against a snapshot that was never delivered
+	if d.check("node_exporter", fp, now.Add(15*time.Second)) {
+		t.Error("Snapshot should not be suppressed after a failed send")
+	}
+}
+
+func TestProcessSnapshotsFingerprint_OrderIndependent(t *testing.T) {
+	a := []prometheus.ProcessExporterMetricSnapshot{
+		{Timestamp: time.Now(), Name: "nginx", NumProcs: 4},
+		{Timestamp: time.Now(), Name: "postgres", NumProcs: 10},
+	}
+	b := []prometheus.ProcessExporterMetricSnapshot{
+		{Timestamp: time.Now().Add(time.Minute), Name: "postgres", NumProcs: 10},
+		{Timestamp: time.Now().Add(time.Minute), Name: "nginx", NumProcs: 4},
+	}
+
+	if processSnapshotsFingerprint(a) != processSnapshotsFingerprint(b) {
+		t.Error("Fingerprint should ignore order and timestamps")
+	}
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// deliveryStateFile holds the last delivery stats, in the buffer directory root
+// (buffered scrapes live in per-exporter subdirectories, so it never looks like a report)
+const deliveryStateFile = "last_delivery.json"
+
+// DeliveryStats describes successful deliveries to the ingest endpoint
+// Persisted so 'nodepulse status' (a separate process) can show them
+type DeliveryStats struct {
+	LastDelivery time.Time `json:"last_delivery"` // Zero if nothing has been delivered yet
+	BatchesSent  int64     `json:"batches_sent"`  // Batches delivered since the state file was created
+
+	// Agent clock minus ingest server clock, from the last response's Date header (nil if never measured)
+	ClockSkew *time.Duration `json:"clock_skew_ns,omitempty"`
+}
+
+// LastDeliveryStats returns when a batch was last delivered and how many batches have been sent
+func (s *Sender) LastDeliveryStats() DeliveryStats {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	return s.delivery
+}
+
+// recordDelivery counts a successfully delivered batch and persists the stats
+// Persisting is best effort: a failure only affects what 'status' shows
+func (s *Sender) recordDelivery(at time.Time) {
+	s.deliveryMu.Lock()
+	s.delivery.LastDelivery = at
+	s.delivery.BatchesSent++
+	stats := s.delivery
+	s.deliveryMu.Unlock()
+
+	if err := saveDeliveryStats(s.deliveryStatePath(), stats); err != nil {
+		logger.Debug("Failed to persist delivery stats", logger.Err(err))
+	}
+}
+
+// deliveryStatePath returns the path of the delivery state file
+func (s *Sender) deliveryStatePath() string {
+	return filepath.Join(s.config.Buffer.Path, deliveryStateFile)
+}
+
+// loadDeliveryStats reads persisted delivery stats
+// A missing file means nothing has been delivered yet
+func loadDeliveryStats(path string) (DeliveryStats, error) {
+	var stats DeliveryStats
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, fmt.Errorf("failed to read delivery state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return DeliveryStats{}, fmt.Errorf("failed to parse delivery state: %w", err)
+	}
+	return stats, nil
+}
+
+// saveDeliveryStats writes delivery stats atomically (temp file + rename)
+func saveDeliveryStats(path string, stats DeliveryStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery state: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write delivery state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write delivery state: %w", err)
+	}
+	return nil
+}
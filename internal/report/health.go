@@ -0,0 +1,68 @@
+package report
+
+import (
+	"time"
+
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// DeliveryHealth describes whether buffered scrapes are delivered in time
+type DeliveryHealth struct {
+	Degraded  bool          // The oldest buffered scrape is older than buffer.stale_threshold
+	OldestAge time.Duration // Age of the oldest buffered scrape (0 if the buffer is empty)
+	Threshold time.Duration // buffer.stale_threshold (0 = stale detection disabled)
+}
+
+// DeliveryHealth reports whether delivery is degraded, judged by the age of the oldest
+// buffered scrape. Computed from the buffer itself, so 'nodepulse status' sees it too
+func (s *Sender) DeliveryHealth() DeliveryHealth {
+	return deliveryHealth(s.buffer.GetBufferStatus().OldestFile, s.config.Buffer.StaleThreshold, time.Now())
+}
+
+// deliveryHealth judges the oldest buffered scrape time against the stale threshold
+func deliveryHealth(oldest time.Time, threshold time.Duration, now time.Time) DeliveryHealth {
+	health := DeliveryHealth{Threshold: threshold}
+	if oldest.IsZero() {
+		return health
+	}
+	health.OldestAge = now.Sub(oldest)
+	health.Degraded = threshold > 0 && health.OldestAge > threshold
+	return health
+}
+
+// checkStaleBuffer logs when the oldest buffered scrape exceeds buffer.stale_threshold
+// The warning repeats each time the age doubles (1h, 2h, 4h, ... with the default threshold),
+// escalating to an error from four times the threshold, so a chronically unreachable endpoint
+// is noticed without a log line per drain cycle. Only called by the drain goroutine
+func (s *Sender) checkStaleBuffer(files []string, now time.Time) {
+	health := deliveryHealth(oldestScrapeTime(files), s.config.Buffer.StaleThreshold, now)
+
+	if !health.Degraded {
+		if s.staleLevel > 0 {
+			logger.Info("Buffer backlog is no longer stale, delivery recovered",
+				logger.Duration("oldest_age", health.OldestAge.Round(time.Second)))
+			s.staleLevel = 0
+		}
+		return
+	}
+
+	// Level n means the oldest scrape is at least threshold * 2^(n-1) old
+	level := 1
+	for limit := 2 * health.Threshold; health.OldestAge >= limit; limit *= 2 {
+		level++
+	}
+	if level <= s.staleLevel {
+		return
+	}
+	s.staleLevel = level
+
+	log := logger.Warn
+	if level >= 3 {
+		log = logger.Error
+	}
+	log("Delivery degraded: buffered scrapes are not being delivered - check the ingest endpoint and network",
+		logger.Duration("oldest_age", health.OldestAge.Round(time.Second)),
+		logger.Duration("stale_threshold", health.Threshold),
+		logger.Int("buffered_files", len(files)),
+		logger.Int("consecutive_failures", s.consecutiveFailures))
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDeliveryHealth(t *testing.T) {
+	now := time.Date(2025, 10, 15, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		oldest    time.Time
+		threshold time.Duration
+		want      DeliveryHealth
+	}{
+		{"empty buffer", time.Time{}, time.Hour, DeliveryHealth{Threshold: time.Hour}},
+		{"recent backlog", now.Add(-10 * time.Minute), time.Hour, DeliveryHealth{OldestAge: 10 * time.Minute, Threshold: time.Hour}},
+		{"stale backlog", now.Add(-3 * time.Hour), time.Hour, DeliveryHealth{Degraded: true, OldestAge: 3 * time.Hour, Threshold: time.Hour}},
+		{"disabled", now.Add(-3 * time.Hour), 0, DeliveryHealth{OldestAge: 3 * time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryHealth(tt.oldest, tt.threshold, now); got != tt.want {
+				t.Errorf("deliveryHealth() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckStaleBuffer(t *testing.T) {
+	cfg := newTestConfig(t, "http://127.0.0.1:0")
+	cfg.Buffer.StaleThreshold = time.Hour
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	oldest := time.Date(2025, 10, 15, 12, 0, 0, 0, time.Local)
+	files := []string{fmt.Sprintf("node_exporter/%s-test-server.prom", oldest.Format("20060102-150405"))}
+
+	// The level only rises when the age crosses threshold * 2^n, so each step is logged once
+	steps := []struct {
+		age  time.Duration
+		want int
+	}{
+		{30 * time.Minute, 0},
+		{61 * time.Minute, 1},
+		{90 * time.Minute, 1},
+		{2 * time.Hour, 2},
+		{5 * time.Hour, 3},
+	}
+	for _, step := range steps {
+		sender.checkStaleBuffer(files, oldest.Add(step.age))
+		if sender.staleLevel != step.want {
+			t.Errorf("After %s: staleLevel = %d, want %d", step.age, sender.staleLevel, step.want)
+		}
+	}
+
+	// Delivering the backlog resets the state
+	sender.checkStaleBuffer(nil, oldest.Add(6*time.Hour))
+	if sender.staleLevel != 0 {
+		t.Errorf("Expected staleLevel to reset once the buffer drains, got %d", sender.staleLevel)
+	}
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// agentVersion is reported in heartbeats and the User-Agent (see SetAgentVersion)
+var agentVersion = "dev"
+
+// SetAgentVersion sets the agent version reported to the ingest server
+func SetAgentVersion(version string) {
+	if version != "" {
+		agentVersion = version
+	}
+}
+
+// Heartbeat is the body of a heartbeat request: a liveness signal independent of metrics delivery
+type Heartbeat struct {
+	ServerID  string    `json:"server_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   string    `json:"version"`
+}
+
+// HeartbeatURL returns the heartbeat endpoint: server.heartbeat_endpoint, or "/heartbeat"
+// appended to the path of server.endpoint (e.g. https://ingest.example.com/metrics/heartbeat)
+func HeartbeatURL(cfg config.ServerConfig) (string, error) {
+	if cfg.HeartbeatEndpoint != "" {
+		return cfg.HeartbeatEndpoint, nil
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/heartbeat"
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// StartHeartbeat posts a heartbeat every interval (and once right away) until the sender is
+// closed, on its own goroutine so it keeps going while deliveries are backed off or buffered
+func (s *Sender) StartHeartbeat(serverID string, interval time.Duration) error {
+	endpoint, err := HeartbeatURL(s.config.Server)
+	if err != nil {
+		return fmt.Errorf("failed to configure heartbeat: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		failing := false
+		for {
+			err := s.sendHeartbeat(endpoint, serverID, time.Now())
+			switch {
+			case err != nil && !failing:
+				logger.Warn("Heartbeat failed", logger.String("endpoint", endpoint), logger.Err(err))
+			case err != nil:
+				logger.Debug("Heartbeat failed", logger.Err(err))
+			case failing:
+				logger.Info("Heartbeat delivered again", logger.String("endpoint", endpoint))
+			}
+			failing = err != nil
+
+			select {
+			case <-s.drainCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	logger.Info("Started heartbeat",
+		logger.String("endpoint", endpoint),
+		logger.Duration("interval", interval))
+	return nil
+}
+
+// sendHeartbeat posts a single heartbeat
+func (s *Sender) sendHeartbeat(endpoint, serverID string, now time.Time) error {
+	body, err := json.Marshal(Heartbeat{ServerID: serverID, Timestamp: now.UTC(), Version: agentVersion})
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.drainCtx, requestTimeout(s.config.Server.Timeout, len(body)))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	s.setHeaders(req, serverID)
+
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+func TestHeartbeatURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		server config.ServerConfig
+		want   string
+	}{
+		{
+			name:   "derived from endpoint path",
+			server: config.ServerConfig{Endpoint: "https://ingest.example.com/metrics"},
+			want:   "https://ingest.example.com/metrics/heartbeat",
+		},
+		{
+			name:   "trailing slash and query",
+			server: config.ServerConfig{Endpoint: "https://ingest.example.com/metrics/?tenant=a"},
+			want:   "https://ingest.example.com/metrics/heartbeat?tenant=a",
+		},
+		{
+			name: "explicit endpoint",
+			server: config.ServerConfig{
+				Endpoint:          "https://ingest.example.com/metrics",
+				HeartbeatEndpoint: "https://status.example.com/ping",
+			},
+			want: "https://status.example.com/ping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HeartbeatURL(tt.server)
+			if err != nil {
+				t.Fatalf("HeartbeatURL failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSendHeartbeat(t *testing.T) {
+	var got Heartbeat
+	var gotAuth, gotPath string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL+"/metrics")
+	cfg.Server.Auth = config.AuthConfig{Type: "bearer", Token: "inline-token"}
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	endpoint, err := HeartbeatURL(cfg.Server)
+	if err != nil {
+		t.Fatalf("HeartbeatURL failed: %v", err)
+	}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := sender.sendHeartbeat(endpoint, "test-server", now); err != nil {
+		t.Fatalf("sendHeartbeat failed: %v", err)
+	}
+
+	if gotPath != "/metrics/heartbeat" {
+		t.Errorf("Expected path /metrics/heartbeat, got %q", gotPath)
+	}
+	if gotAuth != "Bearer inline-token" {
+		t.Errorf("Expected bearer auth header, got %q", gotAuth)
+	}
+	if got.ServerID != "test-server" || !got.Timestamp.Equal(now) || got.Version != agentVersion {
+		t.Errorf("Unexpected heartbeat: %+v", got)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := sender.sendHeartbeat(endpoint, "test-server", now); err == nil {
+		t.Error("Expected error when server rejects the heartbeat")
+	}
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Influx line protocol measurements
+// Nested values (pressure, rates, per-core, per-filesystem, per-interface, per-NUMA-node, per-sensor,
+// per-TCP-state, per-OOM-kill, per-process) get their own measurement, keyed by a tag where
+// there are several, since line protocol has no nested fields
+const (
+	influxNodeMeasurement       = "node_exporter"
+	influxCPUMeasurement        = "node_cpu"
+	influxFilesystemMeasurement = "node_filesystem"
+	influxInterfaceMeasurement  = "node_network_interface"
+	influxNUMAMeasurement       = "node_numa"
+	influxOOMKillMeasurement    = "node_oom_kill"
+	influxPressureMeasurement   = "node_pressure"
+	influxRatesMeasurement      = "node_rates"
+	influxTCPMeasurement        = "node_tcp"
+	influxThermalMeasurement    = "node_thermal"
+	influxProcessMeasurement    = "process_exporter"
+)
+
+// influxTCPState is the field set of a node_tcp line (the state itself is a tag)
+type influxTCPState struct {
+	Connections int64 `json:"connections"`
+}
+
+// influxTagEscaper escapes tag keys and values (commas, equals signs, and spaces)
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// encodeInflux serializes a payload to InfluxDB line protocol, one line per snapshot
+// Every line is tagged with server_id, hostname, and deploy_id (each omitted if empty), and fields are
+// named after the snapshot's JSON keys so both encodings carry the same data
+// Generic scrapes aren't encoded: config validation only allows them with the json encoding
+func encodeInflux(payload Payload, serverID, hostname string) []byte {
+	var sb strings.Builder
+
+	baseTags := map[string]string{"server_id": serverID, "hostname": hostname, "deploy_id": payload.DeployID}
+	withTag := func(key, value string) map[string]string {
+		tags := map[string]string{key: value}
+		for k, v := range baseTags {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	for _, snapshot := range payload.NodeExporter {
+		writeInfluxLine(&sb, influxNodeMeasurement, baseTags, reflect.ValueOf(snapshot), snapshot.Timestamp)
+		if snapshot.Pressure != nil {
+			writeInfluxLine(&sb, influxPressureMeasurement, baseTags, reflect.ValueOf(*snapshot.Pressure), snapshot.Timestamp)
+		}
+		if snapshot.Rates != nil {
+			writeInfluxLine(&sb, influxRatesMeasurement, baseTags, reflect.ValueOf(*snapshot.Rates), snapshot.Timestamp)
+		}
+		for _, core := range snapshot.CPUPerCore {
+			writeInfluxLine(&sb, influxCPUMeasurement, withTag("cpu", core.CPU), reflect.ValueOf(core), snapshot.Timestamp)
+		}
+		for _, fs := range snapshot.Filesystems {
+			tags := withTag("mountpoint", fs.Mountpoint)
+			tags["device"] = fs.Device
+			tags["fstype"] = fs.FSType
+			writeInfluxLine(&sb, influxFilesystemMeasurement, tags, reflect.ValueOf(fs), snapshot.Timestamp)
+		}
+		for _, iface := range snapshot.NetworkInterfaces {
+			writeInfluxLine(&sb, influxInterfaceMeasurement, withTag("device", iface.Device), reflect.ValueOf(iface), snapshot.Timestamp)
+		}
+		for _, node := range snapshot.NUMANodes {
+			writeInfluxLine(&sb, influxNUMAMeasurement, withTag("node", node.Node), reflect.ValueOf(node), snapshot.Timestamp)
+		}
+		states := make([]string, 0, len(snapshot.TCPConnectionStates))
+		for state := range snapshot.TCPConnectionStates {
+			states = append(states, state)
+		}
+		sort.Strings(states)
+		for _, state := range states {
+			conns := influxTCPState{Connections: snapshot.TCPConnectionStates[state]}
+			writeInfluxLine(&sb, influxTCPMeasurement, withTag("state", state), reflect.ValueOf(conns), snapshot.Timestamp)
+		}
+		for _, kill := range snapshot.OOMKills {
+			writeInfluxLine(&sb, influxOOMKillMeasurement, withTag("process", kill.Process), reflect.ValueOf(kill), snapshot.Timestamp)
+		}
+		for _, sensor := range snapshot.Thermal {
+			tags := withTag("chip", sensor.Chip)
+			tags["sensor"] = sensor.Sensor
+			tags["label"] = sensor.Label
+			writeInfluxLine(&sb, influxThermalMeasurement, tags, reflect.ValueOf(sensor), snapshot.Timestamp)
+		}
+	}
+
+	for _, snapshot := range payload.ProcessExporter {
+		writeInfluxLine(&sb, influxProcessMeasurement, withTag("name", snapshot.Name), reflect.ValueOf(snapshot), snapshot.Timestamp)
+	}
+
+	return []byte(sb.String())
+}
+
+// writeInfluxLine appends a single line: measurement,tag=value field=value timestamp
+// Tags are sorted by key (as InfluxDB recommends) and empty tag values are dropped
+// Lines without any numeric field are skipped, since line protocol requires at least one
+func writeInfluxLine(sb *strings.Builder, measurement string, tags map[string]string, v reflect.Value, ts time.Time) {
+	fields := influxFields(v)
+	if fields == "" {
+		return
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k, val := range tags {
+		if val != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	sb.WriteString(measurement)
+	for _, k := range keys {
+		sb.WriteByte(',')
+		sb.WriteString(influxTagEscaper.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(influxTagEscaper.Replace(tags[k]))
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(fields)
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	sb.WriteByte('\n')
+}
+
+// influxFields formats the numeric fields of a snapshot struct as key=value pairs
+// Field keys are the JSON tags; integers get the "i" suffix, and strings, slices,
+// timestamps, and non-finite floats (not representable in line protocol) are skipped
+func influxFields(v reflect.Value) string {
+	var parts []string
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			parts = append(parts, key+"="+strconv.FormatInt(field.Int(), 10)+"i")
+		case reflect.Float32, reflect.Float64:
+			f := field.Float()
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				continue
+			}
+			parts = append(parts, key+"="+strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
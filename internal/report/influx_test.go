@@ -0,0 +1,237 @@
+package report
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+// influxPoint is a parsed line protocol line
+type influxPoint struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]string
+	timestamp   int64
+}
+
+// parseInfluxLine parses a line protocol line, honoring backslash escapes in the series key
+func parseInfluxLine(t *testing.T, line string) influxPoint {
+	t.Helper()
+
+	// Split into series key, fields, and timestamp on unescaped spaces
+	var sections []string
+	var current strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			current.WriteByte(line[i])
+			current.WriteByte(line[i+1])
+			i++
+		case line[i] == ' ':
+			sections = append(sections, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	sections = append(sections, current.String())
+	if len(sections) != 3 {
+		t.Fatalf("Expected series key, fields, and timestamp, got %d sections: %q", len(sections), line)
+	}
+
+	unescape := strings.NewReplacer(`\,`, ",", `\=`, "=", `\ `, " ")
+	point := influxPoint{tags: map[string]string{}, fields: map[string]string{}}
+
+	keyParts := splitUnescaped(sections[0], ',')
+	point.measurement = keyParts[0]
+	for _, tag := range keyParts[1:] {
+		kv := splitUnescaped(tag, '=')
+		if len(kv) != 2 {
+			t.Fatalf("Malformed tag %q in %q", tag, line)
+		}
+		point.tags[unescape.Replace(kv[0])] = unescape.Replace(kv[1])
+	}
+
+	for _, field := range strings.Split(sections[1], ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			t.Fatalf("Malformed field %q in %q", field, line)
+		}
+		point.fields[kv[0]] = kv[1]
+	}
+
+	ts, err := strconv.ParseInt(sections[2], 10, 64)
+	if err != nil {
+		t.Fatalf("Invalid timestamp in %q: %v", line, err)
+	}
+	point.timestamp = ts
+
+	return point
+}
+
+// splitUnescaped splits s on sep, ignoring backslash-escaped separators
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func TestEncodeInflux_RoundTrip(t *testing.T) {
+	ts := time.Date(2025, 10, 15, 12, 0, 0, 0, time.UTC)
+	payload := Payload{
+		NodeExporter: []prometheus.NodeExporterMetricSnapshot{{
+			Timestamp:        ts,
+			CPUIdleSeconds:   1234.5,
+			CPUCores:         4,
+			MemoryTotalBytes: 8 << 30,
+			Load1Min:         0.25,
+			NetworkInterfaces: []prometheus.NetworkInterfaceSnapshot{
+				{Device: "eth0", ReceiveBytesTotal: 1000, TransmitBytesTotal: 2000},
+			},
+			NUMANodes: []prometheus.NUMANodeSnapshot{
+				{Node: "0", MemoryTotalBytes: 4 << 30, MemoryFreeBytes: 1 << 30, MemoryUsedBytes: 3 << 30},
+			},
+			Pressure:            &prometheus.PressureSnapshot{CPUWaitingSecondsTotal: 12.5, IOStalledSecondsTotal: 3},
+			TCPConnectionStates: map[string]int64{"ESTABLISHED": 42},
+			Thermal: []prometheus.ThermalSnapshot{
+				{Chip: "platform_coretemp_0", Sensor: "temp2", Label: "Core 0", Celsius: 52.5},
+			},
+		}},
+		ProcessExporter: []prometheus.ProcessExporterMetricSnapshot{
+			{Timestamp: ts, Name: "my app, v2", NumProcs: 3, CPUSecondsTotal: 12.75, MemoryBytes: 4096},
+		},
+	}
+
+	data := encodeInflux(payload, "server-1", "web 01")
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected 7 lines (node, pressure, interface, numa, tcp, thermal, process), got %d:\n%s", len(lines), data)
+	}
+
+	points := make(map[string]influxPoint)
+	for _, line := range lines {
+		point := parseInfluxLine(t, line)
+		points[point.measurement] = point
+
+		if point.tags["server_id"] != "server-1" {
+			t.Errorf("%s: expected server_id tag server-1, got %q", point.measurement, point.tags["server_id"])
+		}
+		if point.tags["hostname"] != "web 01" {
+			t.Errorf("%s: expected hostname tag %q, got %q", point.measurement, "web 01", point.tags["hostname"])
+		}
+		if point.timestamp != ts.UnixNano() {
+			t.Errorf("%s: expected timestamp %d, got %d", point.measurement, ts.UnixNano(), point.timestamp)
+		}
+		if _, ok := point.fields["timestamp"]; ok {
+			t.Errorf("%s: timestamp should not be encoded as a field", point.measurement)
+		}
+	}
+
+	tests := []struct {
+		measurement string
+		tag         string
+		tagValue    string
+		fields      map[string]string
+	}{
+		{"node_exporter", "", "", map[string]string{
+			"cpu_idle_seconds":   "1234.5",
+			"cpu_cores":          "4i",
+			"memory_total_bytes": strconv.FormatInt(8<<30, 10) + "i",
+			"load_1min":          "0.25",
+			"swap_total_bytes":   "0i",
+		}},
+		{"node_pressure", "", "", map[string]string{
+			"cpu_waiting_seconds_total": "12.5",
+			"io_stalled_seconds_total":  "3",
+		}},
+		{"node_network_interface", "device", "eth0", map[string]string{
+			"receive_bytes_total":  "1000i",
+			"transmit_bytes_total": "2000i",
+		}},
+		{"node_numa", "node", "0", map[string]string{
+			"memory_used_bytes": strconv.FormatInt(3<<30, 10) + "i",
+		}},
+		{"node_tcp", "state", "ESTABLISHED", map[string]string{
+			"connections": "42i",
+		}},
+		{"node_thermal", "label", "Core 0", map[string]string{
+			"celsius": "52.5",
+		}},
+		{"process_exporter", "name", "my app, v2", map[string]string{
+			"num_procs":         "3i",
+			"cpu_seconds_total": "12.75",
+			"memory_bytes":      "4096i",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.measurement, func(t *testing.T) {
+			point, ok := points[tt.measurement]
+			if !ok {
+				t.Fatalf("Missing %s line", tt.measurement)
+			}
+			if tt.tag != "" && point.tags[tt.tag] != tt.tagValue {
+				t.Errorf("Expected tag %s=%q, got %q", tt.tag, tt.tagValue, point.tags[tt.tag])
+			}
+			for key, want := range tt.fields {
+				if got := point.fields[key]; got != want {
+					t.Errorf("Field %s: expected %s, got %s", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeInflux_EmptyHostnameOmitsTag(t *testing.T) {
+	payload := Payload{
+		ProcessExporter: []prometheus.ProcessExporterMetricSnapshot{{Timestamp: time.Now(), Name: "sshd", NumProcs: 1}},
+	}
+
+	point := parseInfluxLine(t, strings.TrimSuffix(string(encodeInflux(payload, "server-1", "")), "\n"))
+	if _, ok := point.tags["hostname"]; ok {
+		t.Errorf("Expected empty hostname tag to be omitted, got %v", point.tags)
+	}
+}
+
+func TestSendOnce_InfluxEncoding(t *testing.T) {
+	var gotContentType string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, "none")
+	sender.config.Server.Encoding = "influx"
+	sender.hostname = "web01"
+
+	payload := Payload{NodeExporter: []prometheus.NodeExporterMetricSnapshot{{Timestamp: time.Now(), Load1Min: 1}}}
+	if err := sender.SendOnce(payload, "test-server"); err != nil {
+		t.Fatalf("SendOnce failed: %v", err)
+	}
+
+	if gotContentType != "text/plain; charset=utf-8" {
+		t.Errorf("Expected line protocol content type, got %q", gotContentType)
+	}
+	if !strings.HasPrefix(string(gotBody), "node_exporter,hostname=web01,server_id=test-server ") {
+		t.Errorf("Unexpected body: %s", gotBody)
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+// Payload is a batch of parsed snapshots sent to the ingest endpoint in one request
+// JSON format: { "deploy_id": "...", "node_exporter": [...], "process_exporter": [...], "generic": { "<exporter>": [...] } }
+// (deploy_id and exporters without data are omitted)
+type Payload struct {
+	DeployID        string                                     `json:"deploy_id,omitempty"` // agent.deploy_id when the scrapes were buffered
+	NodeExporter    []prometheus.NodeExporterMetricSnapshot    `json:"node_exporter,omitempty"`
+	ProcessExporter []prometheus.ProcessExporterMetricSnapshot `json:"process_exporter,omitempty"`
+	Generic         map[string][]GenericSnapshot               `json:"generic,omitempty"` // Raw scrapes of type generic exporters, keyed by exporter name
+}
+
+// GenericSnapshot is one unparsed scrape of a type generic exporter
+// The ingest server is expected to parse the Prometheus text itself
+type GenericSnapshot struct {
+	Timestamp time.Time `json:"timestamp"` // When the scrape was buffered
+	Metrics   string    `json:"metrics"`   // Prometheus text format, as scraped
+}
+
+// Empty reports whether the payload has no snapshots
+func (p Payload) Empty() bool {
+	return len(p.NodeExporter) == 0 && len(p.ProcessExporter) == 0 && len(p.Generic) == 0
+}
+
+// AddGeneric appends a raw scrape under its exporter name
+func (p *Payload) AddGeneric(exporterName string, snapshot GenericSnapshot) {
+	if p.Generic == nil {
+		p.Generic = make(map[string][]GenericSnapshot)
+	}
+	p.Generic[exporterName] = append(p.Generic[exporterName], snapshot)
+}
+
+// exporterCount returns the number of exporters with data in the payload
+func (p Payload) exporterCount() int {
+	count := 0
+	if len(p.NodeExporter) > 0 {
+		count++
+	}
+	if len(p.ProcessExporter) > 0 {
+		count++
+	}
+	return count + len(p.Generic)
+}
+
+// encodePayload serializes the payload according to server.encoding
+// Returns the body and its Content-Type
+func (s *Sender) encodePayload(payload Payload, serverID string) ([]byte, string, error) {
+	if s.config.Server.Encoding == "influx" {
+		return encodeInflux(payload, serverID, s.hostname), "text/plain; charset=utf-8", nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return data, "application/json", nil
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+func TestEncodePayload_DeployID(t *testing.T) {
+	sender := newTestSender(t, "http://localhost", "none")
+	payload := Payload{NodeExporter: []prometheus.NodeExporterMetricSnapshot{{Timestamp: time.Now(), Load1Min: 1}}}
+
+	t.Run("omitted when unset", func(t *testing.T) {
+		data, _, err := sender.encodePayload(payload, "test-server")
+		if err != nil {
+			t.Fatalf("encodePayload failed: %v", err)
+		}
+		if strings.Contains(string(data), "deploy_id") {
+			t.Errorf("Expected no deploy_id, got: %s", data)
+		}
+	})
+
+	payload.DeployID = "release-2025.10.15"
+
+	t.Run("json", func(t *testing.T) {
+		data, contentType, err := sender.encodePayload(payload, "test-server")
+		if err != nil {
+			t.Fatalf("encodePayload failed: %v", err)
+		}
+		if contentType != "application/json" {
+			t.Errorf("Expected application/json, got %q", contentType)
+		}
+
+		var decoded struct {
+			DeployID string `json:"deploy_id"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Failed to unmarshal payload: %v", err)
+		}
+		if decoded.DeployID != "release-2025.10.15" {
+			t.Errorf("Expected deploy_id release-2025.10.15, got %q", decoded.DeployID)
+		}
+	})
+
+	t.Run("influx", func(t *testing.T) {
+		sender.config.Server.Encoding = "influx"
+		t.Cleanup(func() { sender.config.Server.Encoding = "json" })
+
+		data, _, err := sender.encodePayload(payload, "test-server")
+		if err != nil {
+			t.Fatalf("encodePayload failed: %v", err)
+		}
+		point := parseInfluxLine(t, strings.TrimSuffix(string(data), "\n"))
+		if point.tags["deploy_id"] != "release-2025.10.15" {
+			t.Errorf("Expected deploy_id tag, got %v", point.tags)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+// rateTracker computes per-second counter rates between consecutive node_exporter snapshots
+// (node_exporter.compute_rates). Like the deduper, the baseline only advances once a batch
+// is sent, so a batch retried after a failed send gets the same rates again
+type rateTracker struct {
+	sent   *rateSample // Last successfully sent snapshot
+	staged *rateSample // Last snapshot in the current batch (not yet sent)
+}
+
+type rateSample struct {
+	scrapedAt time.Time
+	snapshot  prometheus.NodeExporterMetricSnapshot
+}
+
+// newRateTracker creates a rate tracker without a baseline
+func newRateTracker() *rateTracker {
+	return &rateTracker{}
+}
+
+// apply sets snapshot.Rates from the previous snapshot and stages snapshot as the next baseline
+func (r *rateTracker) apply(snapshot *prometheus.NodeExporterMetricSnapshot, scrapedAt time.Time) {
+	prev := r.staged
+	if prev == nil {
+		prev = r.sent
+	}
+
+	if prev == nil {
+		snapshot.Rates = &prometheus.NodeRatesSnapshot{}
+	} else {
+		snapshot.Rates = computeNodeRates(prev.snapshot, *snapshot, scrapedAt.Sub(prev.scrapedAt))
+	}
+	r.staged = &rateSample{scrapedAt: scrapedAt, snapshot: *snapshot}
+}
+
+// commit makes the staged snapshot the baseline after a successful send
+func (r *rateTracker) commit() {
+	if r.staged != nil {
+		r.sent = r.staged
+		r.staged = nil
+	}
+}
+
+// rollback discards the staged snapshot after a failed send
+func (r *rateTracker) rollback() {
+	r.staged = nil
+}
+
+// computeNodeRates returns the per-second rates between two snapshots taken interval apart
+// A non-positive interval (out-of-order or duplicate scrape times) yields all-zero rates
+func computeNodeRates(prev, cur prometheus.NodeExporterMetricSnapshot, interval time.Duration) *prometheus.NodeRatesSnapshot {
+	rates := &prometheus.NodeRatesSnapshot{}
+	if interval <= 0 {
+		return rates
+	}
+	seconds := interval.Seconds()
+	rates.IntervalSeconds = seconds
+
+	rate := func(prev, cur float64) float64 {
+		if cur < prev {
+			return 0 // Counter reset
+		}
+		return (cur - prev) / seconds
+	}
+	intRate := func(prev, cur int64) float64 {
+		return rate(float64(prev), float64(cur))
+	}
+
+	rates.CPUIdle = rate(prev.CPUIdleSeconds, cur.CPUIdleSeconds)
+	rates.CPUIowait = rate(prev.CPUIowaitSeconds, cur.CPUIowaitSeconds)
+	rates.CPUSystem = rate(prev.CPUSystemSeconds, cur.CPUSystemSeconds)
+	rates.CPUUser = rate(prev.CPUUserSeconds, cur.CPUUserSeconds)
+	rates.CPUSteal = rate(prev.CPUStealSeconds, cur.CPUStealSeconds)
+
+	// The disk and network counters come from the primary device, which can change between
+	// scrapes (e.g. a disk detached, an interface down): diffing two devices would be meaningless
+	if prev.DiskPrimaryDevice == cur.DiskPrimaryDevice {
+		rates.DiskReadsPerSecond = intRate(prev.DiskReadsCompletedTotal, cur.DiskReadsCompletedTotal)
+		rates.DiskWritesPerSecond = intRate(prev.DiskWritesCompletedTotal, cur.DiskWritesCompletedTotal)
+		rates.DiskReadBytesPerSecond = intRate(prev.DiskReadBytesTotal, cur.DiskReadBytesTotal)
+		rates.DiskWrittenBytesPerSecond = intRate(prev.DiskWrittenBytesTotal, cur.DiskWrittenBytesTotal)
+		rates.DiskIOTimeSecondsPerSecond = rate(prev.DiskIOTimeSecondsTotal, cur.DiskIOTimeSecondsTotal)
+	}
+
+	if prev.NetworkPrimaryInterface == cur.NetworkPrimaryInterface {
+		rates.NetworkReceiveBytesPerSecond = intRate(prev.NetworkReceiveBytesTotal, cur.NetworkReceiveBytesTotal)
+		rates.NetworkTransmitBytesPerSecond = intRate(prev.NetworkTransmitBytesTotal, cur.NetworkTransmitBytesTotal)
+		rates.NetworkReceivePacketsPerSecond = intRate(prev.NetworkReceivePacketsTotal, cur.NetworkReceivePacketsTotal)
+		rates.NetworkTransmitPacketsPerSecond = intRate(prev.NetworkTransmitPacketsTotal, cur.NetworkTransmitPacketsTotal)
+		rates.NetworkReceiveErrsPerSecond = intRate(prev.NetworkReceiveErrsTotal, cur.NetworkReceiveErrsTotal)
+		rates.NetworkTransmitErrsPerSecond = intRate(prev.NetworkTransmitErrsTotal, cur.NetworkTransmitErrsTotal)
+		rates.NetworkReceiveDropPerSecond = intRate(prev.NetworkReceiveDropTotal, cur.NetworkReceiveDropTotal)
+		rates.NetworkTransmitDropPerSecond = intRate(prev.NetworkTransmitDropTotal, cur.NetworkTransmitDropTotal)
+	}
+
+	return rates
+}
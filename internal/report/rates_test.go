@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+func TestRateTracker(t *testing.T) {
+	r := newRateTracker()
+	start := time.Now()
+
+	first := prometheus.NodeExporterMetricSnapshot{CPUUserSeconds: 100, DiskReadBytesTotal: 1000, NetworkPrimaryInterface: "eth0", NetworkReceiveBytesTotal: 500}
+	r.apply(&first, start)
+	if first.Rates == nil || *first.Rates != (prometheus.NodeRatesSnapshot{}) {
+		t.Errorf("Expected zero rates for the first snapshot, got %+v", first.Rates)
+	}
+
+	second := prometheus.NodeExporterMetricSnapshot{CPUUserSeconds: 115, DiskReadBytesTotal: 4000, NetworkPrimaryInterface: "eth0", NetworkReceiveBytesTotal: 2000}
+	r.apply(&second, start.Add(15*time.Second))
+	want := prometheus.NodeRatesSnapshot{IntervalSeconds: 15, CPUUser: 1, DiskReadBytesPerSecond: 200, NetworkReceiveBytesPerSecond: 100}
+	if *second.Rates != want {
+		t.Errorf("Rates = %+v, want %+v", *second.Rates, want)
+	}
+
+	// Counters reset (reboot): zero instead of a negative rate
+	third := prometheus.NodeExporterMetricSnapshot{CPUUserSeconds: 2, DiskReadBytesTotal: 5000, NetworkPrimaryInterface: "eth0", NetworkReceiveBytesTotal: 10}
+	r.apply(&third, start.Add(30*time.Second))
+	if third.Rates.CPUUser != 0 || third.Rates.NetworkReceiveBytesPerSecond != 0 || third.Rates.DiskReadBytesPerSecond != 1000.0/15 {
+		t.Errorf("Expected reset counters to yield 0 and others a rate, got %+v", *third.Rates)
+	}
+}
+
+func TestRateTracker_RollbackOnFailedSend(t *testing.T) {
+	r := newRateTracker()
+	start := time.Now()
+
+	first := prometheus.NodeExporterMetricSnapshot{DiskReadsCompletedTotal: 100}
+	r.apply(&first, start)
+	r.commit()
+
+	second := prometheus.NodeExporterMetricSnapshot{DiskReadsCompletedTotal: 400}
+	r.apply(&second, start.Add(30*time.Second))
+	r.rollback()
+
+	// The retried snapshot is diffed against the last sent one again
+	retried := prometheus.NodeExporterMetricSnapshot{DiskReadsCompletedTotal: 400}
+	r.apply(&retried, start.Add(30*time.Second))
+	if retried.Rates.DiskReadsPerSecond != 10 {
+		t.Errorf("Expected 10 reads/s after rollback, got %v", retried.Rates.DiskReadsPerSecond)
+	}
+}
+
+func TestComputeNodeRates_PrimaryDeviceChanged(t *testing.T) {
+	prev := prometheus.NodeExporterMetricSnapshot{NetworkPrimaryInterface: "eth0", NetworkTransmitBytesTotal: 100, DiskPrimaryDevice: "sda", DiskReadBytesTotal: 100}
+	cur := prometheus.NodeExporterMetricSnapshot{NetworkPrimaryInterface: "eth1", NetworkTransmitBytesTotal: 9000, DiskPrimaryDevice: "sdb", DiskReadBytesTotal: 9000}
+
+	rates := computeNodeRates(prev, cur, 10*time.Second)
+	if rates.NetworkTransmitBytesPerSecond != 0 {
+		t.Errorf("Expected no network rate across interfaces, got %v", rates.NetworkTransmitBytesPerSecond)
+	}
+	if rates.DiskReadBytesPerSecond != 0 {
+		t.Errorf("Expected no disk rate across disks, got %v", rates.DiskReadBytesPerSecond)
+	}
+
+	cur.DiskPrimaryDevice = "sda"
+	if rates := computeNodeRates(prev, cur, 10*time.Second); rates.DiskReadBytesPerSecond != 890 {
+		t.Errorf("Expected a disk rate on the same disk, got %v", rates.DiskReadBytesPerSecond)
+	}
+	if rates := computeNodeRates(prev, prev, 0); *rates != (prometheus.NodeRatesSnapshot{}) {
+		t.Errorf("Expected zero rates for a zero interval, got %+v", *rates)
+	}
+}
+
+func TestProcessBatch_ComputeRates(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.NodeExporter.ComputeRates = true
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	base := time.Now().Add(-time.Minute).Truncate(time.Second)
+	for i, reads := range []int{100, 250} {
+		data := []byte(fmt.Sprintf("node_disk_reads_completed_total{device=\"sda\"} %d\n", reads))
+		if err := sender.buffer.SavePrometheusAt(data, "test-server", "node_exporter", base.Add(time.Duration(i)*15*time.Second)); err != nil {
+			t.Fatalf("SavePrometheusAt failed: %v", err)
+		}
+	}
+
+	files, _ := sender.buffer.GetBufferFiles()
+	if err := sender.processBatch(files); err != nil {
+		t.Fatalf("processBatch failed: %v", err)
+	}
+
+	if len(got.NodeExporter) != 2 {
+		t.Fatalf("Expected 2 node_exporter snapshots, got %d", len(got.NodeExporter))
+	}
+	if rates := got.NodeExporter[0].Rates; rates == nil || rates.DiskReadsPerSecond != 0 {
+		t.Errorf("Expected zero rates for the first snapshot, got %+v", rates)
+	}
+	if rates := got.NodeExporter[1].Rates; rates == nil || rates.DiskReadsPerSecond != 10 {
+		t.Errorf("Expected 10 reads/s for the second snapshot, got %+v", rates)
+	}
+}
@@ -2,15 +2,22 @@ package report
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/node-pulse/agent/internal/config"
@@ -18,24 +25,117 @@ import (
 	"github.com/node-pulse/agent/internal/prometheus"
 )
 
+// compressionThreshold is the minimum body size (bytes) before gzip is applied
+// Smaller payloads are sent uncompressed since gzip overhead outweighs the savings
+const compressionThreshold = 1024
+
+// filesPerExporter is how many of the oldest files per exporter go into each batch
+const filesPerExporter = 5
+
+// minUploadBytesPerSecond is the slowest upload rate a request is given time for
+// Requests get server.timeout plus the time to upload their body at this rate
+const minUploadBytesPerSecond = 64 << 10
+
+// newHTTPClient creates the sender's HTTP client with its own transport
+// Each client gets a fresh connection pool so rebuilding it drops stale keep-alive connections
+// A nil proxy keeps the default HTTP_PROXY/HTTPS_PROXY/NO_PROXY handling, and a nil
+// TLS config keeps the default certificate verification
+// timeout bounds each phase (dial, TLS handshake, waiting for response headers) rather than
+// the whole request, so large uploads aren't cut off; see requestTimeout for the overall deadline
+// Overridable in tests to observe client rebuilds
+var newHTTPClient = func(timeout time.Duration, proxy *url.URL, tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{
+		Transport: transport,
+	}
+}
+
+// requestTimeout returns the overall deadline for sending a body of the given size: time to
+// connect, upload the body at minUploadBytesPerSecond, and wait for the response
+// Small requests still fail fast, while large catch-up batches over slow links get more time
+func requestTimeout(timeout time.Duration, bodyBytes int) time.Duration {
+	upload := time.Duration(bodyBytes) * time.Second / minUploadBytesPerSecond
+	return 2*timeout + upload
+}
+
 // Sender handles sending metrics reports to the server
 // New architecture: Write-Ahead Log (WAL) pattern
 // - All metrics are written to buffer first
 // - Separate goroutine drains buffer continuously with random jitter
 type Sender struct {
-	config     *config.Config
-	client     *http.Client
-	buffer     *Buffer
-	drainCtx   context.Context
-	drainStop  context.CancelFunc
-	rng        *rand.Rand
+	config    *config.Config
+	clientMu  sync.Mutex // Guards client and clientAt (batches may be sent concurrently)
+	client    *http.Client
+	clientAt  time.Time // When client was created (for server.client_max_lifetime)
+	buffer    *Buffer
+	drainCtx  context.Context
+	drainStop context.CancelFunc
+	flushCh   chan struct{} // Wakes the drain goroutine for an immediate flush
+	flushDone chan struct{} // Signaled when a flush finishes, for FlushBeforeClose
+	rng       *rand.Rand
+	dedupe    *deduper                    // nil when server.dedupe_unchanged is disabled
+	pinner    *prometheus.InterfacePinner // nil when node_exporter.pin_primary_interface is disabled
+	rates     *rateTracker                // nil when node_exporter.compute_rates is disabled
+	nodeOpts  prometheus.ParseOptions     // node_exporter.primary_disk and primary_network
+	generic   map[string]bool             // Buffer directory names of type generic exporters (forwarded unparsed)
+	authName  string                      // Auth header name (empty when server.auth.type is none)
+	authValue string                      // Auth header value
+	hostname  string                      // Tagged on each line when server.encoding is influx
+	proxy     *url.URL                    // server.proxy_url (nil = from environment)
+	tlsConfig *tls.Config                 // server.tls (nil = Go defaults)
+
+	// Request body bytes sent (after compression) since the agent started
+	bytesSent atomic.Int64
+
+	// Flush requests made, and the requests covered by the last finished flush (those made
+	// before it started), so FlushBeforeClose doesn't mistake an earlier flush for its own
+	flushRequested atomic.Uint64
+	flushCompleted atomic.Uint64
+
+	// Files in batches currently being sent, so concurrent batches never overlap
+	inFlightMu sync.Mutex
+	inFlight   map[string]struct{}
+
+	// Last successful delivery (persisted in the buffer directory for 'status')
+	deliveryMu sync.Mutex
+	delivery   DeliveryStats
+	skewWarned bool // Whether the last clock skew measurement exceeded ClockSkewThreshold
+
+	// Consecutive send failures (only accessed by the drain goroutine)
+	consecutiveFailures int
+
+	// How far the oldest buffered scrape is past buffer.stale_threshold when last logged, 0 = not stale
+	// (see checkStaleBuffer; only accessed by the drain goroutine)
+	staleLevel int
 }
 
 // NewSender creates a new report sender
 func NewSender(cfg *config.Config) (*Sender, error) {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: cfg.Server.Timeout,
+	// Resolve auth header (token may come from an env var or file)
+	authName, authValue, err := resolveAuthHeader(cfg.Server.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure auth: %w", err)
+	}
+
+	// An explicit proxy takes precedence over the environment
+	proxy, err := config.ParseProxyURL(cfg.Server.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.Server.TLS, cfg.Server.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
 	}
 
 	// Create buffer (always enabled in new architecture)
@@ -50,16 +150,102 @@ func NewSender(cfg *config.Config) (*Sender, error) {
 	// Create random number generator with time-based seed for jitter
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
+	// Hostname is informational (influx tag), so a lookup failure isn't fatal
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Debug("Failed to get hostname", logger.Err(err))
+	}
+
+	// Create deduper if enabled (suppresses identical consecutive snapshots)
+	var dedupe *deduper
+	if cfg.Server.DedupeUnchanged {
+		dedupe = newDeduper(cfg.Server.DedupeMaxSuppress)
+	}
+
+	// Pin the primary network interface if enabled (keeps network counters continuous)
+	var pinner *prometheus.InterfacePinner
+	if cfg.NodeExporter.PinPrimaryInterface {
+		pinner = prometheus.NewInterfacePinner()
+	}
+
+	// Compute counter rates between consecutive node_exporter snapshots if enabled
+	var rates *rateTracker
+	if cfg.NodeExporter.ComputeRates {
+		rates = newRateTracker()
+	}
+
+	// Load persisted delivery stats so the batch count survives restarts
+	delivery, err := loadDeliveryStats(filepath.Join(cfg.Buffer.Path, deliveryStateFile))
+	if err != nil {
+		logger.Warn("Ignoring unreadable delivery state", logger.Err(err))
+	}
+
 	return &Sender{
 		config:    cfg,
-		client:    client,
+		client:    newHTTPClient(cfg.Server.Timeout, proxy, tlsConfig),
+		clientAt:  time.Now(),
 		buffer:    buffer,
 		drainCtx:  ctx,
 		drainStop: cancel,
+		flushCh:   make(chan struct{}, 1),
+		flushDone: make(chan struct{}, 1),
 		rng:       rng,
+		dedupe:    dedupe,
+		pinner:    pinner,
+		rates:     rates,
+		nodeOpts:  NodeParseOptions(cfg),
+		generic:   genericExporters(cfg),
+		authName:  authName,
+		authValue: authValue,
+		hostname:  hostname,
+		proxy:     proxy,
+		tlsConfig: tlsConfig,
+		inFlight:  make(map[string]struct{}),
+		delivery:  delivery,
 	}, nil
 }
 
+// resolveAuthHeader returns the header name and value to attach to every request
+func resolveAuthHeader(auth config.AuthConfig) (string, string, error) {
+	if auth.Type == "" || auth.Type == "none" {
+		return "", "", nil
+	}
+
+	token, err := auth.ResolveToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	switch auth.Type {
+	case "bearer":
+		return "Authorization", "Bearer " + token, nil
+	case "header":
+		return auth.HeaderName, token, nil
+	default:
+		return "", "", fmt.Errorf("unsupported auth type: %s", auth.Type)
+	}
+}
+
+// setHeaders sets the headers shared by ingest and heartbeat requests: the User-Agent,
+// server.headers, and auth
+func (s *Sender) setHeaders(req *http.Request, serverID string) {
+	req.Header.Set("User-Agent", userAgent(s.config.Server.UserAgent, serverID))
+	for name, value := range s.config.Server.Headers {
+		req.Header.Set(name, value)
+	}
+	if s.authName != "" {
+		req.Header.Set(s.authName, s.authValue)
+	}
+}
+
+// userAgent expands a server.user_agent template
+func userAgent(template, serverID string) string {
+	if template == "" {
+		template = config.DefaultUserAgent
+	}
+	return strings.NewReplacer("{version}", agentVersion, "{server_id}", serverID).Replace(template)
+}
+
 // BufferPrometheus saves Prometheus text format data to buffer
 // The data will be sent asynchronously by the drain goroutine (after parsing to JSON)
 func (s *Sender) BufferPrometheus(data []byte, serverID string, exporterName string) error {
@@ -75,8 +261,23 @@ func (s *Sender) BufferPrometheus(data []byte, serverID string, exporterName str
 	return nil
 }
 
-// sendJSONHTTP sends JSON metrics to server
-func (s *Sender) sendJSONHTTP(data []byte, serverID string) error {
+// SendOnce sends a payload directly to the server, bypassing the buffer
+// Used by one-shot connectivity checks; the background drain uses processBatch instead
+func (s *Sender) SendOnce(payload Payload, serverID string) error {
+	return s.sendPayload(payload, serverID)
+}
+
+// sendPayload encodes a payload according to server.encoding and sends it
+func (s *Sender) sendPayload(payload Payload, serverID string) error {
+	data, contentType, err := s.encodePayload(payload, serverID)
+	if err != nil {
+		return err
+	}
+	return s.sendHTTP(data, contentType, serverID)
+}
+
+// sendHTTP sends an encoded metrics body to server
+func (s *Sender) sendHTTP(data []byte, contentType string, serverID string) error {
 	// Build URL with server_id query parameter
 	endpoint := s.config.Server.Endpoint
 	u, err := url.Parse(endpoint)
@@ -89,20 +290,50 @@ func (s *Sender) sendJSONHTTP(data []byte, serverID string) error {
 	q.Set("server_id", serverID)
 	u.RawQuery = q.Encode()
 
+	// Compress body if enabled and large enough
+	body, contentEncoding, err := s.encodeBody(data)
+	if err != nil {
+		return err
+	}
+
 	// Create request
-	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(data))
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout(s.config.Server.Timeout, len(body)))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "nodepulse-agent/2.0")
-
-	resp, err := s.client.Do(req)
+	req.Header.Set("Content-Type", contentType)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	s.setHeaders(req, serverID)
+
+	// The server dates its response after reading the body, so the skew is measured from when
+	// the body was written rather than when the request started (uploads can take seconds)
+	var wroteAt atomic.Pointer[time.Time]
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			now := time.Now()
+			wroteAt.Store(&now)
+		},
+	}))
+
+	resp, err := s.httpClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	receivedAt := time.Now()
+
+	// Any response carries the server clock, even an error status
+	// (skipped if the server answered before the body was written, e.g. a 413)
+	if written := wroteAt.Load(); written != nil {
+		if skew, ok := measureClockSkew(resp.Header.Get("Date"), *written, receivedAt); ok {
+			s.recordClockSkew(skew)
+		}
+	}
 
 	// Read response body (and discard it)
 	io.Copy(io.Discard, resp.Body)
@@ -112,12 +343,65 @@ func (s *Sender) sendJSONHTTP(data []byte, serverID string) error {
 		return fmt.Errorf("server returned status %d", resp.StatusCode)
 	}
 
+	s.bytesSent.Add(int64(len(body)))
 	return nil
 }
 
+// BytesSent returns the request body bytes (after compression) delivered since the agent started
+func (s *Sender) BytesSent() int64 {
+	return s.bytesSent.Load()
+}
+
+// httpClient returns the HTTP client, rebuilding it once server.client_max_lifetime has elapsed
+// Long-lived clients can hold broken keep-alive connections after network events (e.g. VPN
+// reconnect), which otherwise surface as a failed first request after every idle period
+func (s *Sender) httpClient() *http.Client {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+
+	maxLifetime := s.config.Server.ClientMaxLifetime
+	if maxLifetime > 0 && time.Since(s.clientAt) >= maxLifetime {
+		s.resetClient()
+		logger.Debug("Rebuilt HTTP client after max lifetime", logger.Duration("max_lifetime", maxLifetime))
+	}
+	return s.client
+}
+
+// resetClient closes the current client's idle connections and replaces it
+// Callers must hold clientMu
+func (s *Sender) resetClient() {
+	s.client.CloseIdleConnections()
+	s.client = newHTTPClient(s.config.Server.Timeout, s.proxy, s.tlsConfig)
+	s.clientAt = time.Now()
+}
+
+// encodeBody compresses the request body according to server.compression
+// Returns the body, the Content-Encoding header value (empty if uncompressed), and any error
+func (s *Sender) encodeBody(data []byte) ([]byte, string, error) {
+	if s.config.Server.Compression != "gzip" || len(data) < compressionThreshold {
+		return data, "", nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to gzip request body: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to gzip request body: %w", err)
+	}
+
+	return buf.Bytes(), "gzip", nil
+}
+
 // StartDraining starts the background goroutine that continuously drains the buffer
 // It should be called once after creating the sender
 func (s *Sender) StartDraining() {
+	// Pick up files left flat in the buffer root by older agents
+	if err := s.buffer.MigrateLegacyFiles(); err != nil {
+		logger.Warn("Failed to migrate legacy buffer files", logger.Err(err))
+	}
+
 	go s.drainLoop()
 	logger.Info("Started buffer drain goroutine with random jitter")
 }
@@ -142,6 +426,9 @@ func (s *Sender) drainLoop() {
 			continue
 		}
 
+		// Warn if the backlog is getting old (e.g. the ingest endpoint is down for hours)
+		s.checkStaleBuffer(files, time.Now())
+
 		// If no files to process, wait and check again
 		if len(files) == 0 {
 			s.randomDelay()
@@ -151,15 +438,31 @@ func (s *Sender) drainLoop() {
 		// NEW APPROACH: Pick N oldest files from each exporter
 		// This ensures all exporters are represented and drains backlog quickly
 		// With 2 exporters and 5 files each = 10 files per HTTP request
-		filesPerExporter := 5
-		batch := s.selectOldestFromEachExporter(files, filesPerExporter)
-
-		if len(batch) > 0 {
-			if err := s.processBatch(batch); err != nil {
-				// Failed to send - keep files and retry after delay
-				logger.Debug("Failed to process batch, will retry",
-					logger.Int("batch_size", len(batch)),
+		// Up to server.send_concurrency such batches are sent in parallel
+		batches := s.selectBatches(files, s.sendConcurrency())
+
+		if len(batches) > 0 {
+			if err := s.sendBatches(batches); err != nil {
+				// Failed to send - keep files and back off before retrying
+				s.consecutiveFailures++
+				// Drop pooled connections so the next attempt dials fresh
+				if s.consecutiveFailures > 1 {
+					s.httpClient().CloseIdleConnections()
+				}
+				delay := s.backoffDelay()
+				logger.Debug("Failed to process batch, backing off",
+					logger.Int("batches", len(batches)),
+					logger.Int("consecutive_failures", s.consecutiveFailures),
+					logger.Duration("delay", delay),
 					logger.Err(err))
+				s.sleep(delay)
+				continue
+			}
+
+			if s.consecutiveFailures > 0 {
+				logger.Info("Delivery recovered after failures",
+					logger.Int("consecutive_failures", s.consecutiveFailures))
+				s.consecutiveFailures = 0
 			}
 		}
 
@@ -168,10 +471,73 @@ func (s *Sender) drainLoop() {
 	}
 }
 
-// processBatch loads and sends buffered files grouped by exporter
-// Returns error if send fails (files are kept for retry)
-// Payload format: { "node_exporter": [...], "process_exporter": [...] }
+// NodeParseOptions returns the node_exporter parser options from the config
+func NodeParseOptions(cfg *config.Config) prometheus.ParseOptions {
+	opts := prometheus.ParseOptions{
+		PrimaryDisk:    cfg.NodeExporter.PrimaryDisk,
+		PrimaryNetwork: cfg.NodeExporter.PrimaryNetwork,
+	}
+	// Patterns were checked when the config was loaded
+	opts.DiskInclude, _ = config.CompileDeviceFilter(cfg.NodeExporter.DiskInclude)
+	opts.DiskExclude, _ = config.CompileDeviceFilter(cfg.NodeExporter.DiskExclude)
+	opts.NetworkInclude, _ = config.CompileDeviceFilter(cfg.NodeExporter.NetworkInclude)
+	opts.NetworkExclude, _ = config.CompileDeviceFilter(cfg.NodeExporter.NetworkExclude)
+	return opts
+}
+
+// genericExporters returns the buffer directory names of the configured type generic exporters
+func genericExporters(cfg *config.Config) map[string]bool {
+	generic := make(map[string]bool)
+	for _, e := range cfg.Exporters {
+		if e.Type == config.ExporterTypeGeneric {
+			generic[config.BufferDirName(e.Name)] = true
+		}
+	}
+	return generic
+}
+
+// processBatch sends buffered files, split into requests of at most buffer.max_batch_bytes
+// Returns error if a send fails (files of that request and later ones are kept for retry)
 func (s *Sender) processBatch(filePaths []string) error {
+	for _, chunk := range splitBatchBySize(filePaths, s.config.Buffer.MaxBatchBytes) {
+		if err := s.sendBatch(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitBatchBySize splits files into consecutive chunks whose total size on disk stays within
+// maxBytes. Parsed snapshots are smaller than the scrapes they come from (generic passthrough is
+// about the same size), so this also bounds the request body. A file larger than maxBytes is
+// sent on its own. maxBytes <= 0 means no limit
+func splitBatchBySize(filePaths []string, maxBytes int64) [][]string {
+	if maxBytes <= 0 || len(filePaths) == 0 {
+		return [][]string{filePaths}
+	}
+
+	var chunks [][]string
+	var chunk []string
+	var chunkBytes int64
+	for _, filePath := range filePaths {
+		var size int64
+		if info, err := os.Stat(filePath); err == nil {
+			size = info.Size()
+		}
+		if len(chunk) > 0 && chunkBytes+size > maxBytes {
+			chunks = append(chunks, chunk)
+			chunk, chunkBytes = nil, 0
+		}
+		chunk = append(chunk, filePath)
+		chunkBytes += size
+	}
+	return append(chunks, chunk)
+}
+
+// sendBatch loads and sends buffered files grouped by exporter in one request
+// Returns error if send fails (files are kept for retry)
+// Payload format: { "node_exporter": [...], "process_exporter": [...], "generic": { "<exporter>": [...] } }
+func (s *Sender) sendBatch(filePaths []string) error {
 	if len(filePaths) == 0 {
 		return nil
 	}
@@ -179,8 +545,12 @@ func (s *Sender) processBatch(filePaths []string) error {
 	// Group entries by exporter name - use separate maps for type safety
 	nodeExporterMetrics := []prometheus.NodeExporterMetricSnapshot{}
 	processExporterMetrics := []prometheus.ProcessExporterMetricSnapshot{}
+	var payload Payload
 	processedFiles := []string{}
+	suppressedFiles := []string{}
 	var serverID string
+	var deployIDSet bool
+	now := time.Now()
 
 	for _, filePath := range filePaths {
 		// Only process .prom files
@@ -209,10 +579,23 @@ func (s *Sender) processBatch(filePaths []string) error {
 			serverID = entry.ServerID
 		}
 
+		// A payload carries one deploy_id, so a scrape from another deploy starts the next batch
+		if deployIDSet && entry.DeployID != payload.DeployID {
+			break
+		}
+		payload.DeployID = entry.DeployID
+		deployIDSet = true
+
+		// Dedupe compares scrape times, so a backlog drained at once isn't all within one window
+		scrapedAt := entry.ScrapedAt
+		if scrapedAt.IsZero() {
+			scrapedAt = now // File name without a parseable time
+		}
+
 		// Parse Prometheus text to structured metrics based on exporter type
 		switch entry.ExporterName {
 		case "node_exporter":
-			snapshot, err := prometheus.ParseNodeExporterMetrics(entry.Data)
+			snapshot, err := prometheus.ParseNodeExporterMetricsWithOptions(entry.Data, s.nodeOpts)
 			if err != nil {
 				logger.Warn("Failed to parse node_exporter metrics, using zero values",
 					logger.String("exporter", entry.ExporterName),
@@ -223,6 +606,20 @@ func (s *Sender) processBatch(filePaths []string) error {
 					Timestamp: time.Now().UTC(),
 				}
 			}
+			// The parsers stamp the parse time, which for backlog is the drain, not the scrape
+			if !entry.ScrapedAt.IsZero() {
+				snapshot.Timestamp = entry.ScrapedAt.UTC()
+			}
+			if s.pinner != nil {
+				s.pinner.Apply(snapshot)
+			}
+			if s.dedupe != nil && s.dedupe.check(entry.ExporterName, nodeSnapshotFingerprint(*snapshot), scrapedAt) {
+				suppressedFiles = append(suppressedFiles, filePath)
+				continue
+			}
+			if s.rates != nil {
+				s.rates.apply(snapshot, entry.ScrapedAt)
+			}
 			nodeExporterMetrics = append(nodeExporterMetrics, *snapshot)
 
 		case "process_exporter":
@@ -234,52 +631,78 @@ func (s *Sender) processBatch(filePaths []string) error {
 					logger.Err(err))
 				continue
 			}
+			if !entry.ScrapedAt.IsZero() {
+				for i := range snapshots {
+					snapshots[i].Timestamp = entry.ScrapedAt.UTC()
+				}
+			}
+			// Keep only the heaviest process groups if a limit is configured
+			snapshots = prometheus.LimitProcessSnapshots(snapshots, s.config.ProcessExporter.MaxGroups)
+			if s.dedupe != nil && s.dedupe.check(entry.ExporterName, processSnapshotsFingerprint(snapshots), scrapedAt) {
+				suppressedFiles = append(suppressedFiles, filePath)
+				continue
+			}
 			// Append all process snapshots (one per process group)
 			processExporterMetrics = append(processExporterMetrics, snapshots...)
 
 		default:
-			logger.Warn("Unknown exporter type, skipping",
-				logger.String("exporter", entry.ExporterName),
-				logger.String("file", filePath))
-			continue
+			if !s.generic[entry.ExporterName] {
+				logger.Warn("Unknown exporter type, skipping",
+					logger.String("exporter", entry.ExporterName),
+					logger.String("file", filePath))
+				continue
+			}
+			// Passthrough: the ingest server parses generic exporters' text itself
+			payload.AddGeneric(entry.ExporterName, GenericSnapshot{
+				Timestamp: entry.ScrapedAt.UTC(),
+				Metrics:   string(entry.Data),
+			})
 		}
 
 		processedFiles = append(processedFiles, filePath)
 	}
 
-	// Nothing to send
-	if len(nodeExporterMetrics) == 0 && len(processExporterMetrics) == 0 {
-		return nil
+	if len(suppressedFiles) > 0 {
+		logger.Debug("Suppressed unchanged snapshots",
+			logger.Int("files", len(suppressedFiles)))
 	}
 
-	// Build payload: { "node_exporter": [...], "process_exporter": [...] }
-	// Only include exporters that have data
-	payload := make(map[string]interface{})
-	exporterCount := 0
-	if len(nodeExporterMetrics) > 0 {
-		payload["node_exporter"] = nodeExporterMetrics
-		exporterCount++
-	}
-	if len(processExporterMetrics) > 0 {
-		payload["process_exporter"] = processExporterMetrics
-		exporterCount++
+	// Nothing to send
+	if len(nodeExporterMetrics) == 0 && len(processExporterMetrics) == 0 && len(payload.Generic) == 0 {
+		s.deleteFiles(suppressedFiles)
+		return nil
 	}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal batch: %w", err)
-	}
+	// Only exporters that have data are included in the payload
+	payload.NodeExporter = nodeExporterMetrics
+	payload.ProcessExporter = processExporterMetrics
 
 	// Send batch via HTTP
-	if err := s.sendJSONHTTP(jsonData, serverID); err != nil {
+	if err := s.sendPayload(payload, serverID); err != nil {
 		// Send failed - keep all files for retry
+		if s.dedupe != nil {
+			s.dedupe.rollback()
+		}
+		if s.rates != nil {
+			s.rates.rollback()
+		}
 		logger.Debug("Failed to send batch, will retry",
 			logger.Int("batch_size", len(processedFiles)),
 			logger.Err(err))
 		return err
 	}
 
+	s.recordDelivery(time.Now())
+
+	// Success - the sent snapshots become the new dedupe baseline
+	if s.dedupe != nil {
+		s.dedupe.commit()
+	}
+	if s.rates != nil {
+		s.rates.commit()
+	}
+	s.deleteFiles(suppressedFiles)
+
 	// Success - delete all files in batch
 	successCount := 0
 	for _, filePath := range processedFiles {
@@ -295,7 +718,7 @@ func (s *Sender) processBatch(filePaths []string) error {
 	if successCount > 0 {
 		logger.Info("Successfully sent buffered data",
 			logger.Int("files", successCount),
-			logger.Int("exporters", exporterCount))
+			logger.Int("exporters", payload.exporterCount()))
 
 		// Periodically clean up old buffer files
 		if err := s.buffer.Cleanup(); err != nil {
@@ -306,6 +729,17 @@ func (s *Sender) processBatch(filePaths []string) error {
 	return nil
 }
 
+// deleteFiles removes buffer files that were intentionally not sent (e.g. suppressed duplicates)
+func (s *Sender) deleteFiles(filePaths []string) {
+	for _, filePath := range filePaths {
+		if err := s.buffer.DeleteFile(filePath); err != nil {
+			logger.Error("Failed to delete buffer file",
+				logger.String("file", filePath),
+				logger.Err(err))
+		}
+	}
+}
+
 // selectOldestFromEachExporter picks N oldest files from each exporter directory
 // This ensures all exporters are represented in each batch, preventing one exporter
 // from blocking others if it has a backlog
@@ -336,6 +770,84 @@ func (s *Sender) selectOldestFromEachExporter(filePaths []string, filesPerExport
 	return batch
 }
 
+// sendConcurrency returns the max number of batches to send in parallel (server.send_concurrency)
+func (s *Sender) sendConcurrency() int {
+	if s.config.Server.SendConcurrency < 1 {
+		return 1
+	}
+	return s.config.Server.SendConcurrency
+}
+
+// selectBatches picks up to n non-overlapping batches from files, oldest first, and marks
+// their files in flight. Files already in flight are skipped, so a file is never part of two
+// concurrent batches. Each batch must be released with releaseBatch once sent
+func (s *Sender) selectBatches(files []string, n int) [][]string {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	available := make([]string, 0, len(files))
+	for _, file := range files {
+		if _, ok := s.inFlight[file]; !ok {
+			available = append(available, file)
+		}
+	}
+
+	var batches [][]string
+	for len(batches) < n && len(available) > 0 {
+		batch := s.selectOldestFromEachExporter(available, filesPerExporter)
+
+		selected := make(map[string]struct{}, len(batch))
+		for _, file := range batch {
+			selected[file] = struct{}{}
+			s.inFlight[file] = struct{}{}
+		}
+		remaining := available[:0]
+		for _, file := range available {
+			if _, ok := selected[file]; !ok {
+				remaining = append(remaining, file)
+			}
+		}
+		available = remaining
+
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// releaseBatch clears the in-flight mark of a batch's files
+func (s *Sender) releaseBatch(batch []string) {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	for _, file := range batch {
+		delete(s.inFlight, file)
+	}
+}
+
+// sendBatches sends batches in parallel and waits for all of them
+// Each batch only deletes its own files on success, so a failed batch keeps its files for
+// retry without affecting the others. Returns the failed batches' errors joined
+func (s *Sender) sendBatches(batches [][]string) error {
+	if len(batches) == 1 {
+		defer s.releaseBatch(batches[0])
+		return s.processBatch(batches[0])
+	}
+
+	errs := make([]error, len(batches))
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch []string) {
+			defer wg.Done()
+			defer s.releaseBatch(batch)
+			errs[i] = s.processBatch(batch)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
 
 // randomDelay waits for a random duration between 0 and the configured interval
 // This distributes load across the interval window
@@ -346,15 +858,140 @@ func (s *Sender) randomDelay() {
 
 	logger.Debug("Waiting random delay before next drain attempt", logger.Duration("delay", delay))
 
+	s.sleep(delay)
+}
+
+// backoffDelay returns the capped exponential backoff delay for the current failure count
+// Delay doubles from buffer.backoff.base up to buffer.backoff.max, with jitter in the
+// upper half of the window so a fleet of agents doesn't retry in lockstep
+func (s *Sender) backoffDelay() time.Duration {
+	base := s.config.Buffer.Backoff.Base
+	maxDelay := s.config.Buffer.Backoff.Max
+
+	delay := base
+	for i := 1; i < s.consecutiveFailures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(s.rng.Int63n(int64(half)))
+}
+
+// sleep waits for the given duration or until the drain goroutine is stopped
+// A flush request interrupts the wait and drains the backlog immediately
+func (s *Sender) sleep(delay time.Duration) {
 	// Use select to make delay cancellable
 	select {
 	case <-s.drainCtx.Done():
 		return
+	case <-s.flushCh:
+		s.flushBacklog()
+		return
 	case <-time.After(delay):
 		return
 	}
 }
 
+// Flush asks the drain goroutine to send the whole backlog now instead of waiting for its
+// next attempt. Sends still happen on the drain goroutine, so a flush never overlaps its own sends.
+// Non-blocking: repeated requests while a flush is pending are coalesced.
+func (s *Sender) Flush() {
+	s.requestFlush()
+}
+
+// requestFlush wakes the drain goroutine for a flush and returns the request's sequence number
+// A flush covers the request once flushCompleted reaches it
+func (s *Sender) requestFlush() uint64 {
+	seq := s.flushRequested.Add(1)
+	select {
+	case s.flushCh <- struct{}{}:
+	default:
+	}
+	return seq
+}
+
+// FlushBeforeClose asks the drain goroutine to send the backlog and waits up to timeout for the
+// flush to finish. Call it after scraping has stopped, before Close.
+// Returns the number of files still buffered (sent after the next start)
+func (s *Sender) FlushBeforeClose(timeout time.Duration) int {
+	// A flush already running (e.g. from SIGUSR2) may have missed files, so wait for one that
+	// started after this request
+	s.waitForFlush(s.requestFlush(), timeout)
+
+	files, err := s.buffer.GetBufferFiles()
+	if err != nil {
+		logger.Warn("Failed to get buffer files after shutdown flush", logger.Err(err))
+		return 0
+	}
+	return len(files)
+}
+
+// waitForFlush waits up to timeout for a flush covering request seq to finish
+func (s *Sender) waitForFlush(seq uint64, timeout time.Duration) {
+	deadline := time.After(timeout)
+	for s.flushCompleted.Load() < seq {
+		select {
+		case <-s.flushDone:
+		case <-deadline:
+			return
+		}
+	}
+}
+
+// flushBacklog sends batches until the buffer is empty or a send fails
+// Must only be called from the drain goroutine
+func (s *Sender) flushBacklog() {
+	// Requests made up to now are covered: the buffer is read after this point
+	covers := s.flushRequested.Load()
+	defer func() {
+		s.flushCompleted.Store(covers)
+		select {
+		case s.flushDone <- struct{}{}:
+		default:
+		}
+	}()
+
+	before, err := s.buffer.GetBufferFiles()
+	if err != nil {
+		logger.Warn("Failed to get buffer files for flush", logger.Err(err))
+		return
+	}
+	logger.Info("Flushing buffer on request", logger.Int("files", len(before)))
+
+	var flushErr error
+	for {
+		files, err := s.buffer.GetBufferFiles()
+		if err != nil || len(files) == 0 {
+			flushErr = err
+			break
+		}
+		if flushErr = s.sendBatches(s.selectBatches(files, s.sendConcurrency())); flushErr != nil {
+			break
+		}
+
+		// Stop if nothing was removed (e.g. only files from unknown exporters are left)
+		if after, err := s.buffer.GetBufferFiles(); err != nil || len(after) >= len(files) {
+			break
+		}
+	}
+
+	remaining, _ := s.buffer.GetBufferFiles()
+	if flushErr != nil {
+		logger.Warn("Buffer flush stopped early",
+			logger.Int("files_sent", len(before)-len(remaining)),
+			logger.Int("files_remaining", len(remaining)),
+			logger.Err(flushErr))
+		return
+	}
+	logger.Info("Buffer flush complete", logger.Int("files_sent", len(before)-len(remaining)))
+}
+
 // Close stops the drain goroutine and closes the sender
 func (s *Sender) Close() error {
 	// Stop drain goroutine
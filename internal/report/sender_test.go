@@ -0,0 +1,951 @@
+package report
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/prometheus"
+)
+
+func newTestConfig(t *testing.T, endpoint string) *config.Config {
+	t.Helper()
+
+	return &config.Config{
+		Server: config.ServerConfig{
+			Endpoint:    endpoint,
+			Timeout:     3 * time.Second,
+			Compression: "none",
+		},
+		Agent: config.AgentConfig{
+			Interval: 15 * time.Second,
+		},
+		Buffer: config.BufferConfig{
+			Path:           t.TempDir(),
+			RetentionHours: 48,
+			BatchSize:      5,
+			Backoff: config.BackoffConfig{
+				Base: 15 * time.Second,
+				Max:  5 * time.Minute,
+			},
+		},
+	}
+}
+
+func newTestSender(t *testing.T, endpoint string, compression string) *Sender {
+	t.Helper()
+
+	cfg := newTestConfig(t, endpoint)
+	cfg.Server.Compression = compression
+
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	t.Cleanup(func() { sender.Close() })
+	return sender
+}
+
+func TestSendHTTP_Compression(t *testing.T) {
+	small := []byte(`{"node_exporter":[]}`)
+	large := []byte(`{"node_exporter":[` + strings.Repeat(`{"cpu_idle_seconds":12345.67},`, 100) + `{}]}`)
+
+	tests := []struct {
+		name         string
+		compression  string
+		data         []byte
+		wantEncoding string
+	}{
+		{name: "gzip above threshold", compression: "gzip", data: large, wantEncoding: "gzip"},
+		{name: "gzip below threshold", compression: "gzip", data: small, wantEncoding: ""},
+		{name: "none above threshold", compression: "none", data: large, wantEncoding: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEncoding string
+			var gotBody []byte
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotEncoding = r.Header.Get("Content-Encoding")
+
+				var reader io.Reader = r.Body
+				if gotEncoding == "gzip" {
+					gz, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("Failed to create gzip reader: %v", err)
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					defer gz.Close()
+					reader = gz
+				}
+
+				body, err := io.ReadAll(reader)
+				if err != nil {
+					t.Errorf("Failed to read request body: %v", err)
+				}
+				gotBody = body
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			sender := newTestSender(t, server.URL, tt.compression)
+			if err := sender.sendHTTP(tt.data, "application/json", "test-server"); err != nil {
+				t.Fatalf("sendHTTP failed: %v", err)
+			}
+
+			if gotEncoding != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", gotEncoding, tt.wantEncoding)
+			}
+			if !bytes.Equal(gotBody, tt.data) {
+				t.Errorf("Decoded body does not match original JSON:\ngot:  %s\nwant: %s", gotBody, tt.data)
+			}
+		})
+	}
+}
+
+func TestSendHTTP_Auth(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	t.Setenv("NODEPULSE_TEST_TOKEN", "env-token")
+
+	tests := []struct {
+		name       string
+		auth       config.AuthConfig
+		wantHeader string
+		wantValue  string
+	}{
+		{
+			name:       "bearer inline token",
+			auth:       config.AuthConfig{Type: "bearer", Token: "inline-token"},
+			wantHeader: "Authorization",
+			wantValue:  "Bearer inline-token",
+		},
+		{
+			name:       "bearer token from env",
+			auth:       config.AuthConfig{Type: "bearer", TokenEnv: "NODEPULSE_TEST_TOKEN"},
+			wantHeader: "Authorization",
+			wantValue:  "Bearer env-token",
+		},
+		{
+			name:       "custom header token from file",
+			auth:       config.AuthConfig{Type: "header", TokenFile: tokenFile, HeaderName: "X-API-Key"},
+			wantHeader: "X-API-Key",
+			wantValue:  "file-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotValue string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotValue = r.Header.Get(tt.wantHeader)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			cfg := newTestConfig(t, server.URL)
+			cfg.Server.Auth = tt.auth
+			sender, err := NewSender(cfg)
+			if err != nil {
+				t.Fatalf("NewSender failed: %v", err)
+			}
+			defer sender.Close()
+
+			if err := sender.sendHTTP([]byte(`{}`), "application/json", "test-server"); err != nil {
+				t.Fatalf("sendHTTP failed: %v", err)
+			}
+			if gotValue != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.wantHeader, gotValue, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSendHTTP_Headers(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Server.UserAgent = "nodepulse/{version} ({server_id})"
+	cfg.Server.Headers = map[string]string{"x-route": "eu-west"}
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	if err := sender.sendHTTP([]byte(`{}`), "application/json", "test-server"); err != nil {
+		t.Fatalf("sendHTTP failed: %v", err)
+	}
+	if want := "nodepulse/" + agentVersion + " (test-server)"; got.Get("User-Agent") != want {
+		t.Errorf("Expected User-Agent %q, got %q", want, got.Get("User-Agent"))
+	}
+	if got.Get("X-Route") != "eu-west" {
+		t.Errorf("Expected X-Route header, got %q", got.Get("X-Route"))
+	}
+	if got.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", got.Get("Content-Type"))
+	}
+}
+
+func TestUserAgent_Default(t *testing.T) {
+	if got, want := userAgent("", "test-server"), "nodepulse-agent/"+agentVersion; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestSendOnce(t *testing.T) {
+	var gotServerID string
+	var gotBody []byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotServerID = r.URL.Query().Get("server_id")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, "none")
+	payload := Payload{NodeExporter: []prometheus.NodeExporterMetricSnapshot{{Load1Min: 1}}}
+
+	if err := sender.SendOnce(payload, "test-server"); err != nil {
+		t.Fatalf("SendOnce failed: %v", err)
+	}
+	if gotServerID != "test-server" {
+		t.Errorf("Expected server_id test-server, got %q", gotServerID)
+	}
+	if !strings.HasPrefix(string(gotBody), `{"node_exporter":[{`) || !strings.Contains(string(gotBody), `"load_1min":1`) {
+		t.Errorf("Unexpected body: %s", gotBody)
+	}
+	if strings.Contains(string(gotBody), "process_exporter") {
+		t.Errorf("Expected exporters without data to be omitted: %s", gotBody)
+	}
+	if files, _ := sender.buffer.GetBufferFiles(); len(files) != 0 {
+		t.Errorf("Expected SendOnce to bypass the buffer, found %d files", len(files))
+	}
+
+	status = http.StatusUnauthorized
+	if err := sender.SendOnce(payload, "test-server"); err == nil {
+		t.Error("Expected error when server rejects the payload")
+	}
+}
+
+func TestHTTPClient_MaxLifetime(t *testing.T) {
+	created := 0
+	original := newHTTPClient
+	newHTTPClient = func(timeout time.Duration, proxy *url.URL, tlsConfig *tls.Config) *http.Client {
+		created++
+		return original(timeout, proxy, tlsConfig)
+	}
+	t.Cleanup(func() { newHTTPClient = original })
+
+	cfg := newTestConfig(t, "http://localhost")
+	cfg.Server.ClientMaxLifetime = time.Minute
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	first := sender.httpClient()
+	if sender.httpClient() != first {
+		t.Error("Expected the same client within max lifetime")
+	}
+	if created != 1 {
+		t.Errorf("Expected 1 client created, got %d", created)
+	}
+
+	// Age the client past its lifetime
+	sender.clientAt = time.Now().Add(-2 * time.Minute)
+	second := sender.httpClient()
+	if second == first {
+		t.Error("Expected a new client after max lifetime")
+	}
+	if second.Transport == first.Transport {
+		t.Error("Expected a new transport after max lifetime")
+	}
+	if created != 2 {
+		t.Errorf("Expected 2 clients created, got %d", created)
+	}
+
+	// Zero disables rebuilding
+	cfg.Server.ClientMaxLifetime = 0
+	sender.clientAt = time.Now().Add(-time.Hour)
+	if sender.httpClient() != second {
+		t.Error("Expected client to be kept when max lifetime is disabled")
+	}
+}
+
+func TestNewSender_MissingAuthToken(t *testing.T) {
+	cfg := newTestConfig(t, "http://localhost")
+	cfg.Server.Auth = config.AuthConfig{Type: "bearer", TokenEnv: "NODEPULSE_TEST_UNSET_TOKEN"}
+
+	if _, err := NewSender(cfg); err == nil {
+		t.Fatal("Expected error when auth token env var is not set")
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	sender := newTestSender(t, "http://localhost", "none")
+
+	tests := []struct {
+		failures int
+		ceiling  time.Duration
+	}{
+		{failures: 1, ceiling: 15 * time.Second},
+		{failures: 2, ceiling: 30 * time.Second},
+		{failures: 3, ceiling: 60 * time.Second},
+		{failures: 5, ceiling: 240 * time.Second},
+		{failures: 6, ceiling: 5 * time.Minute}, // capped
+		{failures: 100, ceiling: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		sender.consecutiveFailures = tt.failures
+		for i := 0; i < 20; i++ {
+			delay := sender.backoffDelay()
+			if delay < tt.ceiling/2 || delay >= tt.ceiling {
+				t.Errorf("failures=%d: delay %s outside [%s, %s)", tt.failures, delay, tt.ceiling/2, tt.ceiling)
+			}
+		}
+	}
+}
+
+func TestFlush_DrainsBacklogImmediately(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Agent.Interval = time.Hour // Without a flush, the drain loop would wait up to an hour
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	// 12 files from one exporter take 3 batches at 5 files per batch
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 12; i++ {
+		data := []byte(fmt.Sprintf("node_load1 %d\n", i))
+		if err := sender.buffer.SavePrometheusAt(data, "test-server", "node_exporter", base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("SavePrometheusAt failed: %v", err)
+		}
+	}
+
+	// Flush before draining starts: the request is kept until the drain loop sleeps
+	sender.Flush()
+	sender.StartDraining()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		files, _ := sender.buffer.GetBufferFiles()
+		if len(files) == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if files, _ := sender.buffer.GetBufferFiles(); len(files) != 0 {
+		t.Fatalf("Expected flush to drain the backlog, %d files remaining", len(files))
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected 3 batch requests, got %d", got)
+	}
+}
+
+func TestLastDeliveryStats(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	if stats := sender.LastDeliveryStats(); !stats.LastDelivery.IsZero() || stats.BatchesSent != 0 {
+		t.Fatalf("Expected no deliveries initially, got %+v", stats)
+	}
+
+	sendBatch := func() error {
+		if err := sender.buffer.SavePrometheus([]byte("node_load1 1\n"), "test-server", "node_exporter"); err != nil {
+			t.Fatalf("SavePrometheus failed: %v", err)
+		}
+		files, _ := sender.buffer.GetBufferFiles()
+		return sender.processBatch(files)
+	}
+
+	// Failed sends are not deliveries
+	if err := sendBatch(); err == nil {
+		t.Fatal("Expected send to fail")
+	}
+	if stats := sender.LastDeliveryStats(); stats.BatchesSent != 0 {
+		t.Fatalf("Expected failed send not to count, got %+v", stats)
+	}
+
+	status = http.StatusOK
+	before := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := sendBatch(); err != nil {
+			t.Fatalf("processBatch failed: %v", err)
+		}
+	}
+
+	stats := sender.LastDeliveryStats()
+	if stats.BatchesSent != 2 {
+		t.Errorf("Expected 2 batches sent, got %d", stats.BatchesSent)
+	}
+	if stats.LastDelivery.Before(before) {
+		t.Errorf("Expected last delivery after %v, got %v", before, stats.LastDelivery)
+	}
+
+	// A new sender (e.g. 'nodepulse status') reads the persisted stats
+	reloaded, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer reloaded.Close()
+
+	got := reloaded.LastDeliveryStats()
+	if got.BatchesSent != 2 || !got.LastDelivery.Equal(stats.LastDelivery) {
+		t.Errorf("Expected persisted stats %+v, got %+v", stats, got)
+	}
+}
+
+func TestSendHTTP_ProxyURL(t *testing.T) {
+	var gotURL, gotProxyAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotProxyAuth = r.Header.Get("Proxy-Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("agent", "secret")
+
+	cfg := newTestConfig(t, "http://ingest.example.com/metrics")
+	cfg.Server.ProxyURL = proxyURL.String()
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	if err := sender.sendHTTP([]byte(`{}`), "application/json", "test-server"); err != nil {
+		t.Fatalf("sendHTTP failed: %v", err)
+	}
+
+	if gotURL != "http://ingest.example.com/metrics?server_id=test-server" {
+		t.Errorf("Expected request for the ingest endpoint via the proxy, got %q", gotURL)
+	}
+	if !strings.HasPrefix(gotProxyAuth, "Basic ") {
+		t.Errorf("Expected Basic proxy credentials, got %q", gotProxyAuth)
+	}
+}
+
+func TestSendBatches_Concurrent(t *testing.T) {
+	var inFlight, maxInFlight int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if n <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, n) {
+				break
+			}
+		}
+		if n == 2 {
+			close(release)
+		}
+
+		// Hold each request until both batches are in flight
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"load_1min":7`) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Server.SendConcurrency = 2
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	// 10 files from one exporter make two batches of 5: loads 0-4 and 5-9
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 10; i++ {
+		data := []byte(fmt.Sprintf("node_load1 %d\n", i))
+		if err := sender.buffer.SavePrometheusAt(data, "test-server", "node_exporter", base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("SavePrometheusAt failed: %v", err)
+		}
+	}
+	files, _ := sender.buffer.GetBufferFiles()
+
+	batches := sender.selectBatches(files, 2)
+	if len(batches) != 2 || len(batches[0]) != 5 || len(batches[1]) != 5 {
+		t.Fatalf("Expected two batches of 5 files, got %v", batches)
+	}
+	seen := make(map[string]bool)
+	for _, batch := range batches {
+		for _, file := range batch {
+			if seen[file] {
+				t.Fatalf("File %s is in two batches", file)
+			}
+			seen[file] = true
+		}
+	}
+	if again := sender.selectBatches(files, 2); len(again) != 0 {
+		t.Fatalf("Expected in-flight files not to be selected again, got %v", again)
+	}
+
+	// The second batch (loads 5-9) is rejected
+	if err := sender.sendBatches(batches); err == nil {
+		t.Fatal("Expected an error from the failed batch")
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got != 2 {
+		t.Errorf("Expected 2 batches in flight at once, got %d", got)
+	}
+
+	// Only the successful batch's files were deleted
+	remaining, _ := sender.buffer.GetBufferFiles()
+	if len(remaining) != 5 {
+		t.Fatalf("Expected 5 files kept for retry, got %d", len(remaining))
+	}
+	for i, file := range remaining {
+		if file != batches[1][i] {
+			t.Errorf("Expected failed batch files to remain, got %s", file)
+		}
+	}
+
+	// Files are released after sending and can be retried
+	if retry := sender.selectBatches(remaining, 2); len(retry) != 1 || len(retry[0]) != 5 {
+		t.Errorf("Expected the failed batch to be selectable again, got %v", retry)
+	}
+}
+
+func TestProcessBatch_GenericPassthrough(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Exporters = []config.ExporterConfig{{Name: "custom_app", Type: config.ExporterTypeGeneric}}
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	raw := "# HELP app_requests_total Requests served.\n# TYPE app_requests_total counter\napp_requests_total{path=\"/\"} 42\n"
+	scrapedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	if err := sender.buffer.SavePrometheusAt([]byte(raw), "test-server", "custom_app", scrapedAt); err != nil {
+		t.Fatalf("SavePrometheusAt failed: %v", err)
+	}
+	if err := sender.buffer.SavePrometheus([]byte("node_load1 0.5\n"), "test-server", "node_exporter"); err != nil {
+		t.Fatalf("SavePrometheus failed: %v", err)
+	}
+	// Not configured as generic, so it's still skipped
+	if err := sender.buffer.SavePrometheus([]byte("redis_up 1\n"), "test-server", "redis_exporter"); err != nil {
+		t.Fatalf("SavePrometheus failed: %v", err)
+	}
+
+	files, _ := sender.buffer.GetBufferFiles()
+	if err := sender.processBatch(files); err != nil {
+		t.Fatalf("processBatch failed: %v", err)
+	}
+
+	if len(got.NodeExporter) != 1 {
+		t.Errorf("Expected 1 node_exporter snapshot, got %d", len(got.NodeExporter))
+	}
+	snapshots := got.Generic["custom_app"]
+	if len(snapshots) != 1 {
+		t.Fatalf("Expected 1 custom_app snapshot, got %+v", got.Generic)
+	}
+	if snapshots[0].Metrics != raw {
+		t.Errorf("Expected raw metrics to pass through unchanged, got %q", snapshots[0].Metrics)
+	}
+	if !snapshots[0].Timestamp.Equal(scrapedAt) {
+		t.Errorf("Expected timestamp %v, got %v", scrapedAt, snapshots[0].Timestamp)
+	}
+	if _, ok := got.Generic["redis_exporter"]; ok {
+		t.Error("Expected an exporter not configured as generic to be skipped")
+	}
+}
+
+func TestProcessBatch_DeployIDFromScrapeTime(t *testing.T) {
+	var got []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		got = append(got, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, "none")
+	base := time.Now().Add(-time.Hour)
+
+	// Two scrapes before a redeploy, one after
+	sender.config.Agent.DeployID = "release-1"
+	for i := 0; i < 2; i++ {
+		if err := sender.buffer.SavePrometheusAt([]byte("node_load1 0.5\n"), "test-server", "node_exporter", base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("SavePrometheusAt failed: %v", err)
+		}
+	}
+	sender.config.Agent.DeployID = "release-2"
+	if err := sender.buffer.SavePrometheusAt([]byte("node_load1 0.7\n"), "test-server", "node_exporter", base.Add(time.Minute)); err != nil {
+		t.Fatalf("SavePrometheusAt failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		files, _ := sender.buffer.GetBufferFiles()
+		if err := sender.processBatch(files); err != nil {
+			t.Fatalf("processBatch failed: %v", err)
+		}
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(got))
+	}
+	if got[0].DeployID != "release-1" || len(got[0].NodeExporter) != 2 {
+		t.Errorf("Expected 2 snapshots from release-1 in the first batch, got %q with %d", got[0].DeployID, len(got[0].NodeExporter))
+	}
+	if got[1].DeployID != "release-2" || len(got[1].NodeExporter) != 1 {
+		t.Errorf("Expected 1 snapshot from release-2 in the second batch, got %q with %d", got[1].DeployID, len(got[1].NodeExporter))
+	}
+	if got[0].NodeExporter[0].Load1Min != 0.5 {
+		t.Errorf("Expected the deploy header to be stripped before parsing, got load %v", got[0].NodeExporter[0].Load1Min)
+	}
+}
+
+func TestProcessBatch_SnapshotTimestampIsScrapeTime(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, "none")
+	scrapedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// Backlog from an hour ago, with inline timestamps disabled
+	if err := sender.buffer.SavePrometheusAt([]byte("node_load1 0.5\n"), "test-server", "node_exporter", scrapedAt); err != nil {
+		t.Fatalf("SavePrometheusAt failed: %v", err)
+	}
+	if err := sender.buffer.SavePrometheusAt([]byte("namedprocess_namegroup_num_procs{groupname=\"nginx\"} 4\n"), "test-server", "process_exporter", scrapedAt); err != nil {
+		t.Fatalf("SavePrometheusAt failed: %v", err)
+	}
+	files, _ := sender.buffer.GetBufferFiles()
+	if err := sender.processBatch(files); err != nil {
+		t.Fatalf("processBatch failed: %v", err)
+	}
+
+	if len(got.NodeExporter) != 1 || !got.NodeExporter[0].Timestamp.Equal(scrapedAt) {
+		t.Errorf("Expected the node snapshot to be stamped with the scrape time %s, got %+v", scrapedAt, got.NodeExporter)
+	}
+	if len(got.ProcessExporter) != 1 || !got.ProcessExporter[0].Timestamp.Equal(scrapedAt) {
+		t.Errorf("Expected the process snapshot to be stamped with the scrape time %s, got %+v", scrapedAt, got.ProcessExporter)
+	}
+}
+
+func TestFlushBeforeClose(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		status        int
+		wantRemaining int
+	}{
+		{"delivers the backlog", http.StatusOK, 0},
+		{"returns early when the endpoint fails", http.StatusServiceUnavailable, 7},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			cfg := newTestConfig(t, server.URL)
+			cfg.Agent.Interval = time.Hour
+			sender, err := NewSender(cfg)
+			if err != nil {
+				t.Fatalf("NewSender failed: %v", err)
+			}
+			defer sender.Close()
+
+			base := time.Now().Add(-time.Hour)
+			for i := 0; i < 7; i++ {
+				data := []byte(fmt.Sprintf("node_load1 %d\n", i))
+				if err := sender.buffer.SavePrometheusAt(data, "test-server", "node_exporter", base.Add(time.Duration(i)*time.Second)); err != nil {
+					t.Fatalf("SavePrometheusAt failed: %v", err)
+				}
+			}
+			sender.StartDraining()
+
+			start := time.Now()
+			remaining := sender.FlushBeforeClose(10 * time.Second)
+			if remaining != tt.wantRemaining {
+				t.Errorf("Expected %d files remaining, got %d", tt.wantRemaining, remaining)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Expected the flush to finish before the timeout, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestFlushBeforeClose_IgnoresEarlierFlush(t *testing.T) {
+	sender := newTestSender(t, "http://127.0.0.1:1", "none")
+
+	// A SIGUSR2 flush is running when the shutdown flush is requested
+	sender.Flush()
+	earlier := sender.flushRequested.Load()
+	seq := sender.requestFlush()
+
+	done := make(chan struct{})
+	go func() {
+		sender.waitForFlush(seq, 5*time.Second)
+		close(done)
+	}()
+
+	sender.flushCompleted.Store(earlier)
+	sender.flushDone <- struct{}{}
+	select {
+	case <-done:
+		t.Fatal("Expected the wait to continue after the earlier flush finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sender.flushCompleted.Store(seq)
+	sender.flushDone <- struct{}{}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the wait to end once a flush started after the request finished")
+	}
+}
+
+func TestProcessBatch_MaxBatchBytes(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Buffer.MaxBatchBytes = 30 // Two 14-byte scrapes per request
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 6; i++ {
+		data := []byte(fmt.Sprintf("node_load1 %d\n", i+10))
+		if err := sender.buffer.SavePrometheusAt(data, "test-server", "node_exporter", base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("SavePrometheusAt failed: %v", err)
+		}
+	}
+	files, _ := sender.buffer.GetBufferFiles()
+
+	// The third request fails: the first two are delivered, the rest stay buffered
+	if err := sender.processBatch(files); err == nil {
+		t.Fatal("Expected the third request to fail")
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("Expected 3 requests, got %d", got)
+	}
+	remaining, _ := sender.buffer.GetBufferFiles()
+	if len(remaining) != 2 || remaining[0] != files[4] {
+		t.Errorf("Expected the last 2 files to remain, got %v", remaining)
+	}
+}
+
+func TestSplitBatchBySize(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i, size := range []int{10, 10, 25, 5} {
+		path := filepath.Join(dir, fmt.Sprintf("%d.prom", i))
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		files = append(files, path)
+	}
+
+	tests := []struct {
+		maxBytes int64
+		want     []int // Files per chunk
+	}{
+		{0, []int{4}},
+		{20, []int{2, 1, 1}}, // The 25-byte file exceeds the cap and is sent alone
+		{30, []int{2, 2}},
+		{100, []int{4}},
+	}
+
+	for _, tt := range tests {
+		chunks := splitBatchBySize(files, tt.maxBytes)
+		var got []int
+		for _, chunk := range chunks {
+			got = append(got, len(chunk))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("splitBatchBySize(max %d) chunk sizes = %v, want %v", tt.maxBytes, got, tt.want)
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		bodyBytes int
+		want      time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1024, 10*time.Second + 15625*time.Microsecond},
+		{8 << 20, 10*time.Second + 128*time.Second}, // A max-size batch gets two extra minutes
+	}
+
+	for _, tt := range tests {
+		if got := requestTimeout(5*time.Second, tt.bodyBytes); got != tt.want {
+			t.Errorf("requestTimeout(5s, %d) = %s, want %s", tt.bodyBytes, got, tt.want)
+		}
+	}
+}
+
+func TestSendHTTP_ResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Server.Timeout = 100 * time.Millisecond
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	start := time.Now()
+	if err := sender.sendHTTP([]byte(`{}`), "application/json", "test-server"); err == nil {
+		t.Fatal("Expected a server that never responds to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the request to fail after server.timeout, took %s", elapsed)
+	}
+}
+
+func TestNodeParseOptions_DeviceFilters(t *testing.T) {
+	cfg := &config.Config{NodeExporter: config.NodeExporterConfig{DiskInclude: "^xvd", NetworkExclude: "^eth0$"}}
+	opts := NodeParseOptions(cfg)
+
+	snapshot, err := prometheus.ParseNodeExporterMetricsWithOptions([]byte(`node_disk_reads_completed_total{device="sda"} 10
+node_disk_reads_completed_total{device="xvda"} 20
+node_network_receive_bytes_total{device="eth0"} 1000
+node_network_receive_bytes_total{device="eth1"} 2000
+`), opts)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if snapshot.DiskPrimaryDevice != "xvda" || snapshot.NetworkPrimaryInterface != "eth1" {
+		t.Errorf("Expected the configured filters to pick xvda and eth1, got %s and %s",
+			snapshot.DiskPrimaryDevice, snapshot.NetworkPrimaryInterface)
+	}
+	if opts.DiskExclude != nil || opts.NetworkInclude != nil {
+		t.Error("Expected unset filters to keep the built-in filter")
+	}
+}
+
+func TestProcessBatch_DedupeWindowUsesScrapeTime(t *testing.T) {
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, server.URL)
+	cfg.Server.DedupeUnchanged = true
+	cfg.Server.DedupeMaxSuppress = 5 * time.Minute
+	cfg.Buffer.BatchSize = 20
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	// Ten minutes of identical scrapes, drained at once after an outage
+	base := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	for i := 0; i < 10; i++ {
+		if err := sender.buffer.SavePrometheusAt([]byte("node_load1 0.5\n"), "test-server", "node_exporter", base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("SavePrometheusAt failed: %v", err)
+		}
+	}
+	files, _ := sender.buffer.GetBufferFiles()
+	if err := sender.processBatch(files); err != nil {
+		t.Fatalf("processBatch failed: %v", err)
+	}
+
+	// One snapshot per dedupe_max_suppress of scrape time, not one for the whole drain
+	if len(got.NodeExporter) != 2 || !got.NodeExporter[1].Timestamp.Equal(base.Add(5*time.Minute)) {
+		t.Errorf("Expected the scrapes at +0m and +5m to be sent, got %d snapshots", len(got.NodeExporter))
+	}
+}
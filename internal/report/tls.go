@@ -0,0 +1,119 @@
+package report
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/logger"
+)
+
+// newTLSConfig builds the TLS config for ingest requests from server.tls
+// Returns nil when no option is set, so the transport keeps Go's defaults
+func newTLSConfig(cfg config.ServerTLSConfig, endpoint string) (*tls.Config, error) {
+	if cfg == (config.ServerTLSConfig{}) {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	// Private CA: replaces the system pool
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Mutual TLS
+	if cfg.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+		return tlsConfig, nil
+	}
+
+	if cfg.AllowClockSkew {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+		}
+		serverName := u.Hostname()
+
+		// Standard verification is replaced by verifyAllowingClockSkew, which still checks
+		// the chain and hostname. RootCAs is read at handshake time (nil = system pool)
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyAllowingClockSkew(rawCerts, serverName, tlsConfig.RootCAs)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyAllowingClockSkew verifies the server certificate chain and hostname like the default
+// TLS verification, but if the only problem is the validity period (e.g. the clock is wrong
+// before NTP sync), it re-verifies at a time inside the presented certificates' validity
+func verifyAllowingClockSkew(rawCerts [][]byte, serverName string, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no server certificate presented")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse server certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(opts)
+	var invalidErr x509.CertificateInvalidError
+	if err == nil || !errors.As(err, &invalidErr) || invalidErr.Reason != x509.Expired {
+		return err
+	}
+
+	// The latest NotBefore among the presented certificates is inside all of their validity periods
+	// (if they overlap at all); everything except time is verified again at that point
+	opts.CurrentTime = certs[0].NotBefore
+	for _, cert := range certs[1:] {
+		if cert.NotBefore.After(opts.CurrentTime) {
+			opts.CurrentTime = cert.NotBefore
+		}
+	}
+	if _, skewErr := certs[0].Verify(opts); skewErr != nil {
+		return err
+	}
+
+	logger.Warn("Accepted ingest certificate outside its validity period (server.tls.allow_clock_skew) - check the system clock",
+		logger.String("server_name", serverName),
+		logger.String("not_before", certs[0].NotBefore.UTC().String()),
+		logger.String("not_after", certs[0].NotAfter.UTC().String()),
+		logger.Err(err))
+	return nil
+}
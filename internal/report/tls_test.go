@@ -0,0 +1,235 @@
+package report
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+)
+
+// testCA is a throwaway certificate authority for TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCA creates a self-signed CA valid from an hour before notBefore
+func newTestCA(t *testing.T, notBefore time.Time) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             notBefore.Add(-time.Hour),
+		NotAfter:              notBefore.Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &testCA{cert: cert, key: key}
+}
+
+// pool returns a cert pool trusting the CA
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue signs a 127.0.0.1 certificate valid from notBefore for the given usage
+func (ca *testCA) issue(t *testing.T, notBefore time.Time, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(30 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writePEM writes a certificate and its key as PEM files, returning their paths
+func writePEM(t *testing.T, dir, name string, cert tls.Certificate) (string, string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+// newTestTLSServer starts an HTTPS server with the given TLS config that accepts every request
+func newTestTLSServer(t *testing.T, tlsConfig *tls.Config) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSendHTTP_AllowClockSkew(t *testing.T) {
+	// The certificate becomes valid tomorrow, as seen by a host whose clock is a day behind
+	notBefore := time.Now().Add(24 * time.Hour)
+	ca := newTestCA(t, notBefore)
+	caPath, _ := writePEM(t, t.TempDir(), "ca", tls.Certificate{Certificate: [][]byte{ca.cert.Raw}, PrivateKey: ca.key})
+	server := newTestTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, notBefore, x509.ExtKeyUsageServerAuth)},
+	})
+
+	newSender := func(t *testing.T, allowClockSkew bool) *Sender {
+		cfg := newTestConfig(t, server.URL)
+		cfg.Server.TLS = config.ServerTLSConfig{CAFile: caPath, AllowClockSkew: allowClockSkew}
+		sender, err := NewSender(cfg)
+		if err != nil {
+			t.Fatalf("NewSender failed: %v", err)
+		}
+		t.Cleanup(func() { sender.Close() })
+		return sender
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		sender := newSender(t, false)
+		if err := sender.sendHTTP([]byte(`{}`), "application/json", "test-server"); err == nil {
+			t.Fatal("Expected not-yet-valid certificate to be rejected")
+		}
+	})
+
+	t.Run("accepted with allow_clock_skew", func(t *testing.T) {
+		sender := newSender(t, true)
+		if err := sender.sendHTTP([]byte(`{}`), "application/json", "test-server"); err != nil {
+			t.Fatalf("Expected not-yet-valid certificate to be accepted, got: %v", err)
+		}
+	})
+}
+
+func TestVerifyAllowingClockSkew_StillVerifiesChainAndHostname(t *testing.T) {
+	notBefore := time.Now().Add(24 * time.Hour)
+	ca := newTestCA(t, notBefore)
+	cert := ca.issue(t, notBefore, x509.ExtKeyUsageServerAuth)
+	otherRoots := newTestCA(t, notBefore).pool()
+
+	if err := verifyAllowingClockSkew(cert.Certificate, "127.0.0.1", ca.pool()); err != nil {
+		t.Fatalf("Expected skewed certificate to verify, got: %v", err)
+	}
+	if err := verifyAllowingClockSkew(cert.Certificate, "ingest.example.com", ca.pool()); err == nil {
+		t.Error("Expected hostname mismatch to be rejected")
+	}
+	if err := verifyAllowingClockSkew(cert.Certificate, "127.0.0.1", otherRoots); err == nil {
+		t.Error("Expected certificate from an untrusted CA to be rejected")
+	}
+}
+
+func TestSendHTTP_CustomCAAndClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, time.Now())
+	caPath, _ := writePEM(t, dir, "ca", tls.Certificate{Certificate: [][]byte{ca.cert.Raw}, PrivateKey: ca.key})
+	clientCert, clientKey := writePEM(t, dir, "client", ca.issue(t, time.Now().Add(-time.Minute), x509.ExtKeyUsageClientAuth))
+
+	server := newTestTLSServer(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, time.Now().Add(-time.Minute), x509.ExtKeyUsageServerAuth)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool(),
+	})
+
+	send := func(t *testing.T, tlsCfg config.ServerTLSConfig) error {
+		cfg := newTestConfig(t, server.URL)
+		cfg.Server.TLS = tlsCfg
+		sender, err := NewSender(cfg)
+		if err != nil {
+			t.Fatalf("NewSender failed: %v", err)
+		}
+		defer sender.Close()
+		return sender.sendHTTP([]byte(`{}`), "application/json", "test-server")
+	}
+
+	if err := send(t, config.ServerTLSConfig{CAFile: caPath, ClientCertFile: clientCert, ClientKeyFile: clientKey}); err != nil {
+		t.Fatalf("Expected mutual TLS send to succeed, got: %v", err)
+	}
+	if err := send(t, config.ServerTLSConfig{CAFile: caPath}); err == nil {
+		t.Error("Expected send without a client certificate to fail")
+	}
+	if err := send(t, config.ServerTLSConfig{ClientCertFile: clientCert, ClientKeyFile: clientKey}); err == nil {
+		t.Error("Expected send without the private CA to fail verification")
+	}
+	if err := send(t, config.ServerTLSConfig{InsecureSkipVerify: true, ClientCertFile: clientCert, ClientKeyFile: clientKey}); err != nil {
+		t.Errorf("Expected insecure_skip_verify to skip verification, got: %v", err)
+	}
+}
+
+func TestNewTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not-pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.ServerTLSConfig
+	}{
+		{"missing CA file", config.ServerTLSConfig{CAFile: filepath.Join(dir, "missing.pem")}},
+		{"CA file without certificates", config.ServerTLSConfig{CAFile: notPEM}},
+		{"invalid client key pair", config.ServerTLSConfig{ClientCertFile: notPEM, ClientKeyFile: notPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTLSConfig(tt.cfg, "https://ingest.example.com"); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if tlsConfig, err := newTLSConfig(config.ServerTLSConfig{}, "https://ingest.example.com"); err != nil || tlsConfig != nil {
+		t.Errorf("Expected nil TLS config for empty settings, got %v, %v", tlsConfig, err)
+	}
+}
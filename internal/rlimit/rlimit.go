@@ -0,0 +1,83 @@
+package rlimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// MinNoFile is the open files soft limit below which the agent warns at startup
+// Every scrape and ingest request holds a socket, on top of buffer and log files,
+// so a lower limit shows up as intermittent "too many open files" scrape failures
+const MinNoFile = 256
+
+// unlimited is RLIM_INFINITY as returned by getrlimit
+const unlimited = ^uint64(0)
+
+// Limit is a soft/hard resource limit pair
+type Limit struct {
+	Soft uint64
+	Hard uint64
+}
+
+// Limits holds the process resource limits relevant to the agent
+type Limits struct {
+	NoFile Limit // RLIMIT_NOFILE: open file descriptors (including sockets)
+	NProc  Limit // RLIMIT_NPROC: processes/threads for the agent's user
+}
+
+// Current returns the resource limits of the running process
+func Current() (Limits, error) {
+	var limits Limits
+
+	noFile, err := get(unix.RLIMIT_NOFILE)
+	if err != nil {
+		return limits, fmt.Errorf("failed to get RLIMIT_NOFILE: %w", err)
+	}
+	limits.NoFile = noFile
+
+	nproc, err := get(unix.RLIMIT_NPROC)
+	if err != nil {
+		return limits, fmt.Errorf("failed to get RLIMIT_NPROC: %w", err)
+	}
+	limits.NProc = nproc
+
+	return limits, nil
+}
+
+// NoFileLow reports whether the open files soft limit is below MinNoFile
+func (l Limits) NoFileLow() bool {
+	return l.NoFile.Soft < MinNoFile
+}
+
+// String formats the limit as soft/hard, e.g. "1024/524288" or "unlimited/unlimited"
+func (l Limit) String() string {
+	return formatValue(l.Soft) + "/" + formatValue(l.Hard)
+}
+
+// Float returns a limit value as a float (for metrics), with RLIM_INFINITY as +Inf
+func Float(v uint64) float64 {
+	if v == unlimited {
+		return math.Inf(1)
+	}
+	return float64(v)
+}
+
+// get reads a single resource limit
+func get(resource int) (Limit, error) {
+	var rlim unix.Rlimit
+	if err := unix.Getrlimit(resource, &rlim); err != nil {
+		return Limit{}, err
+	}
+	return Limit{Soft: uint64(rlim.Cur), Hard: uint64(rlim.Max)}, nil
+}
+
+// formatValue formats a limit value, spelling out RLIM_INFINITY
+func formatValue(v uint64) string {
+	if v == unlimited {
+		return "unlimited"
+	}
+	return strconv.FormatUint(v, 10)
+}
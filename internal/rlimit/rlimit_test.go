@@ -0,0 +1,65 @@
+package rlimit
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCurrent(t *testing.T) {
+	limits, err := Current()
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+
+	var want unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &want); err != nil {
+		t.Fatalf("Getrlimit failed: %v", err)
+	}
+	if limits.NoFile.Soft != uint64(want.Cur) || limits.NoFile.Hard != uint64(want.Max) {
+		t.Errorf("Expected nofile %d/%d, got %s", want.Cur, want.Max, limits.NoFile)
+	}
+
+	if limits.NoFile.Soft == 0 || limits.NProc.Soft == 0 {
+		t.Errorf("Expected non-zero soft limits, got nofile %s, nproc %s", limits.NoFile, limits.NProc)
+	}
+	if limits.NoFile.Soft > limits.NoFile.Hard {
+		t.Errorf("Soft nofile limit exceeds hard limit: %s", limits.NoFile)
+	}
+}
+
+func TestLimitString(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		want  string
+	}{
+		{Limit{Soft: 1024, Hard: 524288}, "1024/524288"},
+		{Limit{Soft: 1024, Hard: unlimited}, "1024/unlimited"},
+		{Limit{Soft: unlimited, Hard: unlimited}, "unlimited/unlimited"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.limit.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestFloat(t *testing.T) {
+	if got := Float(1024); got != 1024 {
+		t.Errorf("Float(1024) = %g, want 1024", got)
+	}
+	if got := Float(unlimited); !math.IsInf(got, 1) {
+		t.Errorf("Float(unlimited) = %g, want +Inf", got)
+	}
+}
+
+func TestNoFileLow(t *testing.T) {
+	if !(Limits{NoFile: Limit{Soft: 64, Hard: 4096}}).NoFileLow() {
+		t.Error("Expected soft limit 64 to be low")
+	}
+	if (Limits{NoFile: Limit{Soft: 1024, Hard: 4096}}).NoFileLow() {
+		t.Error("Expected soft limit 1024 not to be low")
+	}
+}
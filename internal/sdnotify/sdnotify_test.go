@@ -0,0 +1,111 @@
+package sdnotify
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// listenNotifySocket creates a unixgram socket and points NOTIFY_SOCKET at it
+func listenNotifySocket(t *testing.T) *net.UnixConn {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen on notify socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	buf := make([]byte, 256)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read notification: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	conn := listenNotifySocket(t)
+
+	sent, err := Notify(Ready)
+	if err != nil || !sent {
+		t.Fatalf("Notify() = %v, %v; want true, nil", sent, err)
+	}
+	if got := readNotification(t, conn); got != Ready {
+		t.Errorf("Received %q, want %q", got, Ready)
+	}
+}
+
+func TestNotify_NoSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := Notify(Ready)
+	if err != nil || sent {
+		t.Errorf("Notify() = %v, %v; want false, nil outside systemd", sent, err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		usec    string
+		pid     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "disabled", usec: "", want: 0},
+		{name: "enabled", usec: "30000000", want: 30 * time.Second},
+		{name: "this process", usec: "30000000", pid: strconv.Itoa(os.Getpid()), want: 30 * time.Second},
+		{name: "other process", usec: "30000000", pid: "1", want: 0},
+		{name: "invalid", usec: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+
+			got, err := WatchdogInterval()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WatchdogInterval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("WatchdogInterval() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWatchdog(t *testing.T) {
+	conn := listenNotifySocket(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunWatchdog(ctx, 20*time.Millisecond)
+		close(done)
+	}()
+
+	if got := readNotification(t, conn); got != Watchdog {
+		t.Errorf("Received %q, want %q", got, Watchdog)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunWatchdog did not stop after context cancellation")
+	}
+}
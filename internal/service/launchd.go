@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	launchdLabel    = "io.nodepulse.agent"
+	launchdPlist    = "/Library/LaunchDaemons/io.nodepulse.agent.plist"
+	launchdTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>start</string>
+		<string>--supervised</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>ThrottleInterval</key>
+	<integer>10</integer>
+</dict>
+</plist>
+`
+)
+
+// launchd manages the agent as a launchd daemon (macOS)
+type launchd struct {
+	plist string
+}
+
+func newLaunchd() *launchd {
+	return &launchd{plist: launchdPlist}
+}
+
+func (l *launchd) Name() string {
+	return "launchd"
+}
+
+func (l *launchd) ServiceFile() string {
+	return l.plist
+}
+
+func (l *launchd) Install(binary string, opts InstallOptions) error {
+	if err := rejectWatchdog(l, opts); err != nil {
+		return err
+	}
+
+	// Daemons in /Library/LaunchDaemons are loaded at boot
+	if err := os.WriteFile(l.plist, []byte(renderLaunchdPlist(binary)), 0644); err != nil {
+		return fmt.Errorf("failed to write launchd plist: %w", err)
+	}
+
+	return nil
+}
+
+func (l *launchd) Start() error {
+	return run("launchctl", "bootstrap", "system", l.plist)
+}
+
+func (l *launchd) Stop() error {
+	// KeepAlive would relaunch a killed agent, so unload the job instead
+	return run("launchctl", "bootout", "system/"+launchdLabel)
+}
+
+func (l *launchd) Restart() error {
+	return run("launchctl", "kickstart", "-k", "system/"+launchdLabel)
+}
+
+func (l *launchd) Status() (string, error) {
+	output, err := runCommand("launchctl", "print", "system/"+launchdLabel)
+	return string(output), err
+}
+
+func (l *launchd) Uninstall() error {
+	// Unload if running
+	run("launchctl", "bootout", "system/"+launchdLabel)
+
+	if err := os.Remove(l.plist); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove launchd plist: %w", err)
+	}
+
+	return nil
+}
+
+// renderLaunchdPlist returns the launchd job definition for the given binary
+func renderLaunchdPlist(binary string) string {
+	return fmt.Sprintf(launchdTemplate, launchdLabel, binary)
+}
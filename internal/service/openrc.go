@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	openRCScript   = "/etc/init.d/nodepulse"
+	openRCTemplate = `#!/sbin/openrc-run
+
+name="nodepulse"
+description="NodePulse Server Monitor Agent"
+command="%s"
+# --supervised: supervise-daemon tracks the process, so the agent writes no PID file of its own
+# (otherwise 'nodepulse stop' would kill a process supervise-daemon respawns)
+command_args="start --supervised"
+# supervise-daemon restarts the agent if it exits (like systemd's Restart=always)
+supervisor=supervise-daemon
+respawn_delay=10
+pidfile="/run/${RC_SVCNAME}.supervisor.pid"
+
+depend() {
+	need net
+}
+`
+)
+
+// openRC manages the agent as an OpenRC service (e.g. Alpine Linux)
+type openRC struct {
+	script string
+}
+
+func newOpenRC() *openRC {
+	return &openRC{script: openRCScript}
+}
+
+func (o *openRC) Name() string {
+	return "openrc"
+}
+
+func (o *openRC) ServiceFile() string {
+	return o.script
+}
+
+func (o *openRC) Install(binary string, opts InstallOptions) error {
+	if err := rejectWatchdog(o, opts); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(o.script, []byte(renderOpenRCScript(binary)), 0755); err != nil {
+		return fmt.Errorf("failed to write init script: %w", err)
+	}
+
+	// Start at boot
+	if err := run("rc-update", "add", Name, "default"); err != nil {
+		return fmt.Errorf("failed to enable service: %w", err)
+	}
+
+	return nil
+}
+
+func (o *openRC) Start() error {
+	return run("rc-service", Name, "start")
+}
+
+func (o *openRC) Stop() error {
+	return run("rc-service", Name, "stop")
+}
+
+func (o *openRC) Restart() error {
+	return run("rc-service", Name, "restart")
+}
+
+func (o *openRC) Status() (string, error) {
+	output, err := runCommand("rc-service", Name, "status")
+	return string(output), err
+}
+
+func (o *openRC) Uninstall() error {
+	// Stop service if running
+	run("rc-service", Name, "stop")
+
+	if err := run("rc-update", "del", Name, "default"); err != nil {
+		fmt.Printf("Warning: failed to disable service: %v\n", err)
+	}
+
+	if err := os.Remove(o.script); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove init script: %w", err)
+	}
+
+	return nil
+}
+
+// renderOpenRCScript returns the OpenRC init script for the given binary
+func renderOpenRCScript(binary string) string {
+	return fmt.Sprintf(openRCTemplate, binary)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Name is the service name used by all init systems
+const Name = "nodepulse"
+
+// UnitName returns the systemd unit name of an agent instance ("" = the default instance)
+func UnitName(instance string) string {
+	if instance == "" {
+		return Name
+	}
+	return Name + "@" + instance
+}
+
+// Manager installs and controls the agent as a system service
+type Manager interface {
+	// Name returns the init system name (e.g. "systemd")
+	Name() string
+
+	// ServiceFile returns the path of the service definition (unit file, init script, or plist)
+	ServiceFile() string
+
+	// Install registers the service to run the given binary at boot (does not start it)
+	Install(binary string, opts InstallOptions) error
+
+	Start() error
+	Stop() error
+	Restart() error
+
+	// Status returns the init system's human-readable status output
+	Status() (string, error)
+
+	// Uninstall stops the service and removes its definition
+	Uninstall() error
+}
+
+// InstallOptions configures service installation
+type InstallOptions struct {
+	Watchdog time.Duration // Systemd watchdog timeout, 0 disables it (systemd only)
+}
+
+// runCommand runs an init system command and returns its combined output (overridable in tests)
+var runCommand = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// run runs a command and includes its output in the error on failure
+func run(name string, args ...string) error {
+	output, err := runCommand(name, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, string(output))
+	}
+	return nil
+}
+
+// Detect returns the manager for the init system running on this host
+// A non-empty instance manages that named instance (systemd only)
+func Detect(instance string) (Manager, error) {
+	m, err := detect(runtime.GOOS, pathExists)
+	if err != nil || instance == "" {
+		return m, err
+	}
+	return forInstance(m, instance)
+}
+
+// forInstance returns the manager for a named instance of the agent
+func forInstance(m Manager, instance string) (Manager, error) {
+	if _, ok := m.(*systemd); !ok {
+		return nil, fmt.Errorf("--instance is only supported with systemd (detected %s)", m.Name())
+	}
+	return newSystemdInstance(instance), nil
+}
+
+// detect picks a manager from the OS and well-known init system paths
+func detect(goos string, exists func(string) bool) (Manager, error) {
+	if goos == "darwin" {
+		return newLaunchd(), nil
+	}
+
+	// Same checks as sd_booted(3) and OpenRC's own detection
+	if exists("/run/systemd/system") {
+		return newSystemd(), nil
+	}
+	if exists("/run/openrc") || exists("/sbin/openrc-run") {
+		return newOpenRC(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported init system: expected systemd, OpenRC, or launchd")
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// rejectWatchdog returns an error if a watchdog was requested on an init system without one
+func rejectWatchdog(m Manager, opts InstallOptions) error {
+	if opts.Watchdog > 0 {
+		return fmt.Errorf("--watchdog is only supported with systemd (detected %s)", m.Name())
+	}
+	return nil
+}
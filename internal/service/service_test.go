@@ -0,0 +1,227 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubCommands records init system commands instead of running them
+func stubCommands(t *testing.T) *[]string {
+	t.Helper()
+
+	var calls []string
+	original := runCommand
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		return nil, nil
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &calls
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		goos  string
+		paths []string
+		want  string
+	}{
+		{name: "macOS", goos: "darwin", want: "launchd"},
+		{name: "systemd", goos: "linux", paths: []string{"/run/systemd/system"}, want: "systemd"},
+		{name: "openrc", goos: "linux", paths: []string{"/run/openrc", "/sbin/openrc-run"}, want: "openrc"},
+		{name: "systemd preferred", goos: "linux", paths: []string{"/run/systemd/system", "/sbin/openrc-run"}, want: "systemd"},
+		{name: "unknown", goos: "linux", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists := func(path string) bool {
+				for _, p := range tt.paths {
+					if p == path {
+						return true
+					}
+				}
+				return false
+			}
+
+			manager, err := detect(tt.goos, exists)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Expected error, got %s", manager.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detect failed: %v", err)
+			}
+			if manager.Name() != tt.want {
+				t.Errorf("detect() = %s, want %s", manager.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	t.Run("without watchdog", func(t *testing.T) {
+		unit := renderSystemdUnit("/opt/nodepulse/nodepulse", 0)
+
+		if !strings.Contains(unit, "Type=simple\nExecStart=/opt/nodepulse/nodepulse start\n") {
+			t.Errorf("Expected simple service, got:\n%s", unit)
+		}
+		if strings.Contains(unit, "WatchdogSec") {
+			t.Errorf("Expected no WatchdogSec, got:\n%s", unit)
+		}
+	})
+
+	t.Run("with watchdog", func(t *testing.T) {
+		unit := renderSystemdUnit("/opt/nodepulse/nodepulse", time.Minute)
+
+		for _, want := range []string{"Type=notify\n", "NotifyAccess=main\n", "WatchdogSec=60\n", "ExecStart=/opt/nodepulse/nodepulse start\n"} {
+			if !strings.Contains(unit, want) {
+				t.Errorf("Expected %q in unit, got:\n%s", want, unit)
+			}
+		}
+		if strings.Contains(unit, "Type=simple") {
+			t.Errorf("Expected Type=simple to be replaced, got:\n%s", unit)
+		}
+	})
+}
+
+func TestManagers_Lifecycle(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		manager  Manager
+		contains []string // Expected in the service file
+		calls    []string
+	}{
+		{
+			manager:  &systemd{unitFile: filepath.Join(dir, "nodepulse.service")},
+			contains: []string{"ExecStart=/opt/nodepulse/nodepulse start"},
+			calls: []string{
+				"systemctl daemon-reload", "systemctl enable nodepulse",
+				"systemctl start nodepulse", "systemctl stop nodepulse", "systemctl restart nodepulse",
+				"systemctl stop nodepulse", "systemctl disable nodepulse", "systemctl daemon-reload",
+			},
+		},
+		{
+			manager:  &openRC{script: filepath.Join(dir, "nodepulse")},
+			contains: []string{`command="/opt/nodepulse/nodepulse"`, `command_args="start --supervised"`},
+			calls: []string{
+				"rc-update add nodepulse default",
+				"rc-service nodepulse start", "rc-service nodepulse stop", "rc-service nodepulse restart",
+				"rc-service nodepulse stop", "rc-update del nodepulse default",
+			},
+		},
+		{
+			manager:  &launchd{plist: filepath.Join(dir, "io.nodepulse.agent.plist")},
+			contains: []string{"<string>/opt/nodepulse/nodepulse</string>\n\t\t<string>start</string>\n\t\t<string>--supervised</string>\n"},
+			calls: []string{
+				"launchctl bootstrap system " + filepath.Join(dir, "io.nodepulse.agent.plist"),
+				"launchctl bootout system/io.nodepulse.agent",
+				"launchctl kickstart -k system/io.nodepulse.agent",
+				"launchctl bootout system/io.nodepulse.agent",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.manager.Name(), func(t *testing.T) {
+			calls := stubCommands(t)
+			m := tt.manager
+
+			if err := m.Install("/opt/nodepulse/nodepulse", InstallOptions{}); err != nil {
+				t.Fatalf("Install failed: %v", err)
+			}
+			content, err := os.ReadFile(m.ServiceFile())
+			if err != nil {
+				t.Fatalf("Service file not written: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Expected %q in service file, got:\n%s", want, content)
+				}
+			}
+
+			for _, step := range []func() error{m.Start, m.Stop, m.Restart, m.Uninstall} {
+				if err := step(); err != nil {
+					t.Fatalf("Lifecycle step failed: %v", err)
+				}
+			}
+			if _, err := os.Stat(m.ServiceFile()); !os.IsNotExist(err) {
+				t.Errorf("Expected service file to be removed on uninstall")
+			}
+
+			if !reflect.DeepEqual(*calls, tt.calls) {
+				t.Errorf("Unexpected commands:\ngot:  %q\nwant: %q", *calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestInstall_WatchdogOnlyOnSystemd(t *testing.T) {
+	stubCommands(t)
+	dir := t.TempDir()
+	opts := InstallOptions{Watchdog: time.Minute}
+
+	for _, m := range []Manager{
+		&openRC{script: filepath.Join(dir, "nodepulse")},
+		&launchd{plist: filepath.Join(dir, "io.nodepulse.agent.plist")},
+	} {
+		if err := m.Install("/opt/nodepulse/nodepulse", opts); err == nil {
+			t.Errorf("%s: expected error for --watchdog", m.Name())
+		}
+	}
+}
+
+func TestSystemdInstance(t *testing.T) {
+	calls := stubCommands(t)
+	m := newSystemdInstance("db")
+	m.unitFile = filepath.Join(t.TempDir(), "nodepulse@.service")
+
+	if err := m.Install("/opt/nodepulse/nodepulse", InstallOptions{}); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	content, err := os.ReadFile(m.ServiceFile())
+	if err != nil {
+		t.Fatalf("Template unit not written: %v", err)
+	}
+	for _, want := range []string{"Description=NodePulse Server Monitor Agent (%i)\n", "ExecStart=/opt/nodepulse/nodepulse start --instance %i\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in template unit, got:\n%s", want, content)
+		}
+	}
+
+	if err := m.Uninstall(); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(m.ServiceFile()); err != nil {
+		t.Errorf("Expected the shared template unit to be kept on uninstall: %v", err)
+	}
+
+	want := []string{
+		"systemctl daemon-reload", "systemctl enable nodepulse@db",
+		"systemctl stop nodepulse@db", "systemctl disable nodepulse@db",
+	}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("Unexpected commands:\ngot:  %q\nwant: %q", *calls, want)
+	}
+}
+
+func TestForInstance_SystemdOnly(t *testing.T) {
+	if _, err := forInstance(newOpenRC(), "db"); err == nil {
+		t.Error("Expected --instance to be rejected on OpenRC")
+	}
+
+	m, err := forInstance(newSystemd(), "db")
+	if err != nil {
+		t.Fatalf("forInstance failed: %v", err)
+	}
+	if m.ServiceFile() != systemdInstanceUnitFile {
+		t.Errorf("Expected the template unit, got %s", m.ServiceFile())
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	systemdUnitFile = "/etc/systemd/system/nodepulse.service"
+	// Template unit for named instances, started as nodepulse@<instance>
+	systemdInstanceUnitFile = "/etc/systemd/system/nodepulse@.service"
+	systemdTemplate         = `[Unit]
+Description=NodePulse Server Monitor Agent%s
+After=network.target
+
+[Service]
+%sExecStart=%s %s
+Restart=always
+RestartSec=10s
+
+[Install]
+WantedBy=multi-user.target
+`
+)
+
+// systemd manages the agent as a systemd unit
+type systemd struct {
+	unitFile string
+	instance string // Named instance, whose unitFile is the nodepulse@.service template ("" = default)
+}
+
+func newSystemd() *systemd {
+	return &systemd{unitFile: systemdUnitFile}
+}
+
+// newSystemdInstance manages a named instance through the nodepulse@.service template
+func newSystemdInstance(instance string) *systemd {
+	return &systemd{unitFile: systemdInstanceUnitFile, instance: instance}
+}
+
+// unit returns the unit name passed to systemctl
+func (s *systemd) unit() string {
+	return UnitName(s.instance)
+}
+
+func (s *systemd) Name() string {
+	return "systemd"
+}
+
+func (s *systemd) ServiceFile() string {
+	return s.unitFile
+}
+
+func (s *systemd) Install(binary string, opts InstallOptions) error {
+	// Create service file
+	unit := renderSystemdUnit(binary, opts.Watchdog)
+	if s.instance != "" {
+		unit = renderSystemdInstanceUnit(binary, opts.Watchdog)
+	}
+	if err := os.WriteFile(s.unitFile, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write service file: %w", err)
+	}
+
+	// Reload systemd
+	if err := run("systemctl", "daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+
+	// Enable service
+	if err := run("systemctl", "enable", s.unit()); err != nil {
+		return fmt.Errorf("failed to enable service: %w", err)
+	}
+
+	return nil
+}
+
+func (s *systemd) Start() error {
+	return run("systemctl", "start", s.unit())
+}
+
+func (s *systemd) Stop() error {
+	return run("systemctl", "stop", s.unit())
+}
+
+func (s *systemd) Restart() error {
+	return run("systemctl", "restart", s.unit())
+}
+
+func (s *systemd) Status() (string, error) {
+	output, err := runCommand("systemctl", "status", s.unit())
+	return string(output), err
+}
+
+func (s *systemd) Uninstall() error {
+	// Stop service if running
+	run("systemctl", "stop", s.unit())
+
+	// Disable service
+	if err := run("systemctl", "disable", s.unit()); err != nil {
+		fmt.Printf("Warning: failed to disable service: %v\n", err)
+	}
+
+	// The template unit is shared by all instances, so only the default unit file is removed
+	if s.instance != "" {
+		return nil
+	}
+
+	// Remove service file
+	if err := os.Remove(s.unitFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove service file: %w", err)
+	}
+
+	// Reload systemd
+	if err := run("systemctl", "daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w", err)
+	}
+
+	return nil
+}
+
+// renderSystemdUnit returns the systemd unit for the given binary
+// With a watchdog, the unit uses Type=notify so systemd waits for READY=1 and restarts
+// the agent if it stops sending WATCHDOG=1 pings
+func renderSystemdUnit(binary string, watchdog time.Duration) string {
+	return fmt.Sprintf(systemdTemplate, "", systemdServiceType(watchdog), binary, "start")
+}
+
+// renderSystemdInstanceUnit returns the template unit for named instances (%i is the instance name)
+func renderSystemdInstanceUnit(binary string, watchdog time.Duration) string {
+	return fmt.Sprintf(systemdTemplate, " (%i)", systemdServiceType(watchdog), binary, "start --instance %i")
+}
+
+// systemdServiceType returns the unit's Type= and watchdog settings
+func systemdServiceType(watchdog time.Duration) string {
+	if watchdog > 0 {
+		return fmt.Sprintf("Type=notify\nNotifyAccess=main\nWatchdogSec=%d\n", int(watchdog.Seconds()))
+	}
+	return "Type=simple\n"
+}
@@ -0,0 +1,208 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/node-pulse/agent/internal/exporters"
+	"github.com/node-pulse/agent/internal/logger"
+	"github.com/node-pulse/agent/internal/report"
+	"github.com/node-pulse/agent/internal/rlimit"
+)
+
+// Server serves the agent's own metrics at /metrics in Prometheus text format
+type Server struct {
+	sender   *report.Sender
+	health   *exporters.HealthTracker
+	token    string // Bearer token required on every request (empty = no authentication)
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer creates a telemetry server reporting on the sender and scrape health
+// When token is set, requests must carry "Authorization: Bearer <token>" or get 401
+func NewServer(sender *report.Sender, health *exporters.HealthTracker, token string) *Server {
+	s := &Server{
+		sender: sender,
+		health: health,
+		token:  token,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.requireToken(s.handleMetrics))
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start listens on addr (host:port) and serves in the background
+// The listener is opened before returning, so a port conflict is reported here
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Telemetry server stopped", logger.Err(err))
+		}
+	}()
+
+	logger.Info("Telemetry server started", logger.String("address", listener.Addr().String()))
+	return nil
+}
+
+// Addr returns the address the server listens on (useful with port 0)
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Shutdown stops the server, waiting for in-flight requests until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// requireToken wraps a handler with the bearer token check (a no-op without a token)
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	if s.token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.token)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="nodepulse"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.writeMetrics(w, time.Now())
+}
+
+// writeMetrics writes all metrics in Prometheus text format
+func (s *Server) writeMetrics(w io.Writer, now time.Time) {
+	// Scrape health per exporter (sorted, so the output is stable)
+	health := s.health.All()
+	names := make([]string, 0, len(health))
+	for name := range health {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writeHeader(w, "nodepulse_scrape_success_total", "counter", "Successful exporter scrapes since the agent started.")
+	for _, name := range names {
+		fmt.Fprintf(w, "nodepulse_scrape_success_total{exporter=%s} %d\n", quote(name), health[name].SuccessCount)
+	}
+
+	writeHeader(w, "nodepulse_scrape_failures_total", "counter", "Failed exporter scrapes since the agent started, by error class.")
+	for _, name := range names {
+		classes := make([]string, 0, len(health[name].ErrorCounts))
+		for class := range health[name].ErrorCounts {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			fmt.Fprintf(w, "nodepulse_scrape_failures_total{exporter=%s,class=%s} %d\n",
+				quote(name), quote(class), health[name].ErrorCounts[class])
+		}
+	}
+
+	writeHeader(w, "nodepulse_scrape_consecutive_failures", "gauge", "Consecutive failed scrapes per exporter (0 after a success).")
+	for _, name := range names {
+		fmt.Fprintf(w, "nodepulse_scrape_consecutive_failures{exporter=%s} %d\n", quote(name), health[name].ConsecutiveFailures)
+	}
+
+	writeHeader(w, "nodepulse_scrape_lines_dropped_total", "counter", "Lines dropped from scrapes for exceeding metrics.max_line_bytes.")
+	for _, name := range names {
+		fmt.Fprintf(w, "nodepulse_scrape_lines_dropped_total{exporter=%s} %d\n", quote(name), health[name].LinesDropped)
+	}
+
+	// Buffer
+	buffer := s.sender.GetBufferStatus()
+
+	writeHeader(w, "nodepulse_buffer_files", "gauge", "Buffered scrapes waiting to be sent.")
+	fmt.Fprintf(w, "nodepulse_buffer_files %d\n", buffer.FileCount)
+
+	writeHeader(w, "nodepulse_buffer_size_bytes", "gauge", "Total size of buffered scrapes.")
+	fmt.Fprintf(w, "nodepulse_buffer_size_bytes %d\n", buffer.TotalSizeBytes)
+
+	writeHeader(w, "nodepulse_buffer_oldest_file_age_seconds", "gauge", "Age of the oldest buffered scrape (0 when the buffer is empty).")
+	oldestAge := 0.0
+	if buffer.HasBuffered && !buffer.OldestFile.IsZero() {
+		oldestAge = now.Sub(buffer.OldestFile).Seconds()
+	}
+	fmt.Fprintf(w, "nodepulse_buffer_oldest_file_age_seconds %g\n", oldestAge)
+
+	writeHeader(w, "nodepulse_buffer_read_only", "gauge", "1 if the buffer directory is on a read-only filesystem.")
+	fmt.Fprintf(w, "nodepulse_buffer_read_only %d\n", boolToInt(buffer.FilesystemReadOnly))
+
+	// Delivery
+	delivery := s.sender.LastDeliveryStats()
+
+	writeHeader(w, "nodepulse_sent_bytes_total", "counter", "Request body bytes (after compression) delivered since the agent started.")
+	fmt.Fprintf(w, "nodepulse_sent_bytes_total %d\n", s.sender.BytesSent())
+
+	writeHeader(w, "nodepulse_batches_sent_total", "counter", "Batches delivered to the ingest endpoint (persisted across restarts).")
+	fmt.Fprintf(w, "nodepulse_batches_sent_total %d\n", delivery.BatchesSent)
+
+	// Omitted until the first delivery, since there is no meaningful age to report
+	if !delivery.LastDelivery.IsZero() {
+		writeHeader(w, "nodepulse_last_delivery_age_seconds", "gauge", "Seconds since a batch was last delivered.")
+		fmt.Fprintf(w, "nodepulse_last_delivery_age_seconds %g\n", now.Sub(delivery.LastDelivery).Seconds())
+	}
+
+	// Resource limits (+Inf when unlimited), omitted if they can't be read
+	if limits, err := rlimit.Current(); err == nil {
+		for _, limit := range []struct {
+			name, help string
+			value      uint64
+		}{
+			{"nodepulse_rlimit_nofile_soft", "Soft limit on open file descriptors (RLIMIT_NOFILE).", limits.NoFile.Soft},
+			{"nodepulse_rlimit_nofile_hard", "Hard limit on open file descriptors (RLIMIT_NOFILE).", limits.NoFile.Hard},
+			{"nodepulse_rlimit_nproc_soft", "Soft limit on processes for the agent's user (RLIMIT_NPROC).", limits.NProc.Soft},
+			{"nodepulse_rlimit_nproc_hard", "Hard limit on processes for the agent's user (RLIMIT_NPROC).", limits.NProc.Hard},
+		} {
+			writeHeader(w, limit.name, "gauge", limit.help)
+			fmt.Fprintf(w, "%s %g\n", limit.name, rlimit.Float(limit.value))
+		}
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric family
+func writeHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// labelValueEscaper escapes label values as required by the Prometheus text format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote returns a quoted, escaped label value
+func quote(value string) string {
+	return `"` + labelValueEscaper.Replace(value) + `"`
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/node-pulse/agent/internal/config"
+	"github.com/node-pulse/agent/internal/exporters"
+	"github.com/node-pulse/agent/internal/report"
+	"github.com/node-pulse/agent/internal/rlimit"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Endpoint:    "http://127.0.0.1:1",
+			Timeout:     time.Second,
+			Compression: "none",
+		},
+		Buffer: config.BufferConfig{
+			Path:           t.TempDir(),
+			RetentionHours: 48,
+			BatchSize:      5,
+		},
+	}
+	sender, err := report.NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	if err := sender.BufferPrometheus([]byte("node_load1 0.5\n"), "server-1", "node_exporter"); err != nil {
+		t.Fatalf("BufferPrometheus failed: %v", err)
+	}
+
+	health := exporters.NewHealthTracker()
+	health.RecordSuccess("node_exporter")
+	health.RecordSuccess("node_exporter")
+	class := health.RecordFailure("process_exporter", errors.New("connection refused"))
+	health.RecordDroppedLines("node_exporter", 3)
+
+	server := NewServer(sender, health, "")
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + server.Addr() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	output := string(body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected Prometheus text content type, got %q", ct)
+	}
+
+	for _, want := range []string{
+		`nodepulse_scrape_success_total{exporter="node_exporter"} 2`,
+		`nodepulse_scrape_success_total{exporter="process_exporter"} 0`,
+		`nodepulse_scrape_failures_total{exporter="process_exporter",class="` + class + `"} 1`,
+		`nodepulse_scrape_consecutive_failures{exporter="process_exporter"} 1`,
+		`nodepulse_scrape_lines_dropped_total{exporter="node_exporter"} 3`,
+		`nodepulse_buffer_files 1`,
+		`nodepulse_buffer_size_bytes 15`,
+		`nodepulse_sent_bytes_total 0`,
+		`nodepulse_batches_sent_total 0`,
+		`# TYPE nodepulse_scrape_failures_total counter`,
+		`# TYPE nodepulse_buffer_files gauge`,
+	} {
+		if !strings.Contains(output, want+"\n") {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	limits, err := rlimit.Current()
+	if err != nil {
+		t.Fatalf("rlimit.Current failed: %v", err)
+	}
+	for name, value := range map[string]uint64{
+		"nodepulse_rlimit_nofile_soft": limits.NoFile.Soft,
+		"nodepulse_rlimit_nofile_hard": limits.NoFile.Hard,
+		"nodepulse_rlimit_nproc_soft":  limits.NProc.Soft,
+		"nodepulse_rlimit_nproc_hard":  limits.NProc.Hard,
+	} {
+		if want := fmt.Sprintf("%s %g\n", name, rlimit.Float(value)); !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	// Nothing delivered yet, so there's no delivery age
+	if strings.Contains(output, "nodepulse_last_delivery_age_seconds") {
+		t.Errorf("Expected no last delivery age before the first delivery:\n%s", output)
+	}
+}
+
+func TestServer_StartFailsOnUsedPort(t *testing.T) {
+	first := NewServer(nil, exporters.NewHealthTracker(), "")
+	if err := first.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer first.Shutdown(context.Background())
+
+	second := NewServer(nil, exporters.NewHealthTracker(), "")
+	if err := second.Start(first.Addr()); err == nil {
+		second.Shutdown(context.Background())
+		t.Fatal("Expected an error when the address is in use")
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Endpoint:    "http://127.0.0.1:1",
+			Timeout:     time.Second,
+			Compression: "none",
+		},
+		Buffer: config.BufferConfig{
+			Path:           t.TempDir(),
+			RetentionHours: 48,
+			BatchSize:      5,
+		},
+	}
+	sender, err := report.NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	defer sender.Close()
+
+	server := NewServer(sender, exporters.NewHealthTracker(), "s3cret")
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+	}{
+		{name: "no token", auth: "", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", auth: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "token without scheme", auth: "s3cret", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", auth: "Bearer s3cret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://"+server.Addr()+"/metrics", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("GET /metrics failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && strings.Contains(string(body), "nodepulse_") {
+				t.Errorf("Expected no metrics without a valid token, got:\n%s", body)
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(string(body), "nodepulse_buffer_files 0") {
+				t.Errorf("Expected metrics with a valid token, got:\n%s", body)
+			}
+		})
+	}
+}